@@ -10,6 +10,7 @@ import (
 	"littleeinsteinchildcare/backend/internal/api/middleware"
 	"littleeinsteinchildcare/backend/internal/api/routes"
 	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
 
 	"littleeinsteinchildcare/backend/firebase"
 )
@@ -37,13 +38,13 @@ func main() {
 	fmt.Println("Note: Variables must be configured properly prior to execution")
 	fmt.Println("Starting API server...")
 
-	app := firebase.Init()
-	// Always sync admin claims from Firestore
-	if err := firebase.SyncAdminClaims(app); err != nil {
-		log.Fatalf("Error syncing admin claims: %v", err)
-	}
+	// Admin claims are synced from the Users table once the private router is set up
+	firebase.Init()
 	// Load configuration
 	cfg, _ := config.LoadServerConfig()
+	// Dates and clock times are read in the center's zone rather than the container's UTC clock
+	models.SetCenterLocation(config.GetCenterLocation())
+	log.Printf("Center time zone: %s", models.CenterLocation())
 
 	// Set up router with all routes
 	privateRouter := routes.SetupPrivateRouter()
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/repositories"
+	"littleeinsteinchildcare/backend/internal/services"
+)
+
+// backfill-image-variants generates the thumbnail and medium variants for images uploaded before
+// UploadImage started storing them alongside the original.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Print which images would get variants without writing anything")
+	force := flag.Bool("force", false, "Regenerate variants even for images that already have them")
+	flag.Parse()
+
+	// Load .env file, ignoring any errors
+	_ = godotenv.Load()
+
+	blobCfg, err := config.LoadBlobConfig()
+	if err != nil {
+		log.Fatalf("Failed to load blob config: %v", err)
+	}
+	blobRepo, err := repositories.NewBlobStorageService(blobCfg.AzureAccountName, blobCfg.AzureAccountKey, blobCfg.AzureContainerName)
+	if err != nil {
+		log.Fatalf("Failed to create blob repository: %v", err)
+	}
+	azTableCfg, err := config.LoadAzTableConfig()
+	if err != nil {
+		log.Fatalf("Failed to load Azure Table config: %v", err)
+	}
+	userRepo, err := repositories.NewUserRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Failed to create user repository: %v", err)
+	}
+	blobService := services.NewBlobService(blobRepo, userRepo, config.GetImageURLTTL())
+
+	ctx := context.Background()
+	images, err := blobService.GetAllImages(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list images: %v", err)
+	}
+
+	generated, skipped, failed := 0, 0, 0
+	for _, name := range images {
+		// Image blobs are named {userID}/{fileName}
+		userID, fileName, ok := strings.Cut(name, "/")
+		if !ok {
+			log.Printf("Skipping %s: not stored under a user", name)
+			skipped++
+			continue
+		}
+		if !*force && blobService.HasVariants(ctx, userID, fileName) {
+			skipped++
+			continue
+		}
+
+		log.Printf("Image %s/%s: generating variants", userID, fileName)
+		if !*dryRun {
+			sizes, err := blobService.GenerateVariants(ctx, userID, fileName)
+			if err != nil {
+				log.Printf("Failed to generate variants for %s/%s: %v", userID, fileName, err)
+				failed++
+				continue
+			}
+			log.Printf("Image %s/%s: stored %v", userID, fileName, sizes)
+		}
+		generated++
+	}
+
+	log.Printf("Backfill complete: %d images processed, %d skipped, %d failed (dry run: %v)", generated, skipped, failed, *dryRun)
+}
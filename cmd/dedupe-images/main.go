@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/joho/godotenv"
+
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/repositories"
+	"littleeinsteinchildcare/backend/internal/services"
+)
+
+// dedupe-images scans the image container for photos a user uploaded more than once and reports them. Images
+// stored before uploads were hashed get their SHA-256 recorded in blob metadata along the way. With -merge the
+// oldest copy of each photo is kept and the rest are deleted, except copies a message attachment or daily report
+// photo entry still points at.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Report duplicates without storing hashes or deleting anything")
+	merge := flag.Bool("merge", false, "Delete every copy of a duplicated photo except the oldest")
+	flag.Parse()
+
+	// Load .env file, ignoring any errors
+	_ = godotenv.Load()
+
+	blobCfg, err := config.LoadBlobConfig()
+	if err != nil {
+		log.Fatalf("Failed to load blob config: %v", err)
+	}
+	blobRepo, err := repositories.NewBlobStorageService(blobCfg.AzureAccountName, blobCfg.AzureAccountKey, blobCfg.AzureContainerName)
+	if err != nil {
+		log.Fatalf("Failed to create blob repository: %v", err)
+	}
+	azTableCfg, err := config.LoadAzTableConfig()
+	if err != nil {
+		log.Fatalf("Failed to load Azure Table config: %v", err)
+	}
+	userRepo, err := repositories.NewUserRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Failed to create user repository: %v", err)
+	}
+	blobService := services.NewBlobService(blobRepo, userRepo, config.GetImageURLTTL())
+
+	referenced := map[string]bool{}
+	if *merge && !*dryRun {
+		messageRepo, err := repositories.NewMessageRepo(*azTableCfg)
+		if err != nil {
+			log.Fatalf("Failed to create message repository: %v", err)
+		}
+		dailyEntryRepo, err := repositories.NewDailyEntryRepo(*azTableCfg)
+		if err != nil {
+			log.Fatalf("Failed to create daily entry repository: %v", err)
+		}
+		if referenced, err = referencedImages(messageRepo, dailyEntryRepo); err != nil {
+			log.Fatalf("Failed to collect referenced images: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	duplicates, err := blobService.FindDuplicates(ctx, !*dryRun)
+	if err != nil {
+		log.Fatalf("Failed to scan images: %v", err)
+	}
+
+	extra, removed, skipped := 0, 0, 0
+	for _, group := range duplicates {
+		kept := group[0]
+		log.Printf("User %s: %d copies of %s, keeping %s uploaded %s", kept.ID, len(group), kept.Hash, kept.Name, kept.UploadedAt)
+		for _, duplicate := range group[1:] {
+			extra++
+			log.Printf("  duplicate %s uploaded %s", duplicate.Name, duplicate.UploadedAt)
+			if !*merge || *dryRun {
+				continue
+			}
+			// Attachments and report entries name the blob itself, deleting it would leave them pointing at nothing
+			if referenced[duplicate.ID+"/"+duplicate.Name] {
+				log.Printf("  keeping %s, it is referenced by a message or daily report", duplicate.Name)
+				skipped++
+				continue
+			}
+			// Removing through the owner also drops the file from their image list and deletes its variants
+			if err := blobService.DeleteImage(ctx, duplicate.ID, duplicate.ID, duplicate.Name); err != nil {
+				log.Printf("Failed to delete duplicate %s/%s: %v", duplicate.ID, duplicate.Name, err)
+				continue
+			}
+			removed++
+		}
+	}
+
+	log.Printf("Scan complete: %d duplicated photos, %d extra copies, %d removed, %d kept for references (merge: %v, dry run: %v)", len(duplicates), extra, removed, skipped, *merge, *dryRun)
+}
+
+// referencedImages returns the "{owner}/{name}" keys of every image attached to a message or used by a daily report photo entry
+func referencedImages(messageRepo services.MessageRepo, dailyEntryRepo services.DailyEntryRepo) (map[string]bool, error) {
+	referenced := map[string]bool{}
+	threads, err := messageRepo.GetThreads(services.MESSAGETHREADSTABLE, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, thread := range threads {
+		messages, err := messageRepo.GetMessages(services.MESSAGESTABLE, thread.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			for _, attachment := range message.Attachments {
+				referenced[attachment.ImageOwner+"/"+attachment.ImageName] = true
+			}
+		}
+	}
+
+	entries, err := dailyEntryRepo.GetEntries(services.DAILYENTRIESTABLE, "", "ImageName ne ''")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		referenced[entry.ImageOwner+"/"+entry.ImageName] = true
+	}
+	return referenced, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"google.golang.org/api/iterator"
+
+	"littleeinsteinchildcare/backend/firebase"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/repositories"
+	"littleeinsteinchildcare/backend/internal/services"
+)
+
+// migrate-invites copies the legacy Firestore invitedUsers collection into the Azure invites table
+// and promotes signed up admins in the Users table so their stored Role matches the old admin flag.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Print what would be migrated without writing anything")
+	flag.Parse()
+
+	// Load .env file, ignoring any errors
+	_ = godotenv.Load()
+
+	azTableCfg, err := config.LoadAzTableConfig()
+	if err != nil {
+		log.Fatalf("Failed to load Azure Table config: %v", err)
+	}
+	inviteRepo, err := repositories.NewInviteRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Failed to create invite repository: %v", err)
+	}
+	userRepo, err := repositories.NewUserRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Failed to create user repository: %v", err)
+	}
+	inviteService := services.NewInviteService(inviteRepo)
+	userService := services.NewUserService(userRepo, nil, nil)
+
+	// Index existing users by email so signed up admins can be promoted
+	users, err := userService.GetAllUsers()
+	if err != nil {
+		log.Fatalf("Failed to list users: %v", err)
+	}
+	usersByEmail := make(map[string]models.User, len(users))
+	for _, user := range users {
+		usersByEmail[strings.ToLower(user.Email)] = user
+	}
+
+	ctx := context.Background()
+	fsClient, err := firebase.Firestore(ctx)
+	if err != nil {
+		log.Fatalf("Failed to connect to Firestore: %v", err)
+	}
+	defer fsClient.Close()
+
+	migrated, promoted := 0, 0
+	iter := fsClient.Collection("invitedUsers").Documents(ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("Failed to read invitedUsers: %v", err)
+		}
+
+		data := doc.Data()
+		isAdmin, _ := data["admin"].(bool)
+		signedUp, _ := data["signedUp"].(bool)
+
+		invite := models.Invite{
+			Email:     strings.ToLower(doc.Ref.ID),
+			Role:      models.RoleParent,
+			SignedUp:  signedUp,
+			InvitedAt: doc.CreateTime,
+		}
+		// Staff invites carry a role, the old admin flag still wins over it
+		if role, ok := data["role"].(string); ok && role != "" {
+			invite.Role = models.NormalizeRole(role)
+		}
+		if isAdmin {
+			invite.Role = models.RoleAdmin
+		}
+		if invite.InvitedAt.IsZero() {
+			invite.InvitedAt = time.Now().UTC()
+		}
+
+		log.Printf("Invite %s: role=%s signedUp=%v", invite.Email, invite.Role, invite.SignedUp)
+		if !*dryRun {
+			if err := inviteService.SaveInvite(invite); err != nil {
+				log.Printf("Failed to save invite %s: %v", invite.Email, err)
+				continue
+			}
+		}
+		migrated++
+
+		// Old admin flag wins over whatever role was stored when the account was created
+		user, ok := usersByEmail[invite.Email]
+		if !ok || !isAdmin || user.Role == models.RoleAdmin {
+			continue
+		}
+		log.Printf("Promoting user %s (%s) from %s to admin", user.ID, user.Email, user.Role)
+		if !*dryRun {
+			if err := userService.SetUserRole(user.ID, models.RoleAdmin, user.Classrooms); err != nil {
+				log.Printf("Failed to promote user %s: %v", user.ID, err)
+				continue
+			}
+		}
+		promoted++
+	}
+
+	log.Printf("Migration complete: %d invites copied, %d users promoted (dry run: %v)", migrated, promoted, *dryRun)
+}
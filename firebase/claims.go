@@ -2,106 +2,74 @@ package firebase
 
 import (
 	"context"
-	"log"
 	"fmt"
+	"log"
 
 	firebase "firebase.google.com/go/v4"
-	"google.golang.org/api/iterator"
-)
-
-// SyncAdminClaims checks Firestore for admin users and updates their custom claims
-func SyncAdminClaims(app *firebase.App) error {
-	ctx := context.Background()
-
-	firestoreClient, err := app.Firestore(ctx)
-	if err != nil {
-		return err
-	}
-	defer firestoreClient.Close()
+	"firebase.google.com/go/v4/auth"
 
-	authClient, err := app.Auth(ctx)
-	if err != nil {
-		return err
-	}
-
-	iter := firestoreClient.Collection("invitedUsers").Documents(ctx)
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		data := doc.Data()
-		isAdmin, _ := data["admin"].(bool)
-		signedUp, _ := data["signedUp"].(bool)
-		if !signedUp || !isAdmin {
-			continue
-		}
-
-		email := doc.Ref.ID // You could also use a "uid" field if preferred
+	"littleeinsteinchildcare/backend/internal/models"
+)
 
-		user, err := authClient.GetUserByEmail(ctx, email)
-		if err != nil {
-			log.Printf("Could not find user %s: %v", email, err)
-			continue
-		}
+// UserSource lists the users stored in Azure Tables, whose Role field is the single source of truth for admin access
+type UserSource interface {
+	GetAllUsers() ([]models.User, error)
+}
 
-		err = authClient.SetCustomUserClaims(ctx, user.UID, map[string]interface{}{"admin": true})
-		if err != nil {
-			log.Printf("Error setting admin claim for %s: %v", email, err)
-		} else {
-			log.Printf("Admin claim set for user: %s", email)
-		}
-	}
+// ClaimsManager applies role changes to Firebase custom claims
+type ClaimsManager struct {
+	app *firebase.App
+}
 
-	return nil
+// NewClaimsManager creates a new ClaimsManager for the given Firebase app
+func NewClaimsManager(app *firebase.App) *ClaimsManager {
+	return &ClaimsManager{app: app}
 }
 
-func SetAdminClaimForEmail(ctx context.Context, app *firebase.App, email string) error {
-	// Initialize Firestore
-	fsClient, err := app.Firestore(ctx)
+// ApplyRole sets or removes the admin claim to match role, keeping any other custom claims,
+// then revokes the user's refresh tokens so the change takes effect on their next token refresh
+func (m *ClaimsManager) ApplyRole(ctx context.Context, uid string, role models.Role) error {
+	authClient, err := m.app.Auth(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to init Firestore: %w", err)
+		return fmt.Errorf("ClaimsManager.ApplyRole: failed to init Firebase Auth: %w", err)
 	}
-	defer fsClient.Close()
 
-	// Initialize Auth
-	authClient, err := app.Auth(ctx)
+	record, err := authClient.GetUser(ctx, uid)
 	if err != nil {
-		return fmt.Errorf("failed to init Firebase Auth: %w", err)
+		return fmt.Errorf("ClaimsManager.ApplyRole: firebase user %s not found: %w", uid, err)
 	}
 
-	// Lookup invitedUsers/{email}
-	doc, err := fsClient.Collection("invitedUsers").Doc(email).Get(ctx)
-	if err != nil {
-		log.Printf("No invitedUsers record found for %s", email)
-		return nil
+	if err := applyAdminClaim(ctx, authClient, uid, record.CustomClaims, role == models.RoleAdmin); err != nil {
+		return fmt.Errorf("ClaimsManager.ApplyRole: %w", err)
+	}
+	if err := authClient.RevokeRefreshTokens(ctx, uid); err != nil {
+		return fmt.Errorf("ClaimsManager.ApplyRole: failed to revoke refresh tokens for %s: %w", uid, err)
 	}
 
-	data := doc.Data()
-	isAdmin, _ := data["admin"].(bool)
-	signedUp, _ := data["signedUp"].(bool)
+	log.Printf("Role claims updated for %s: role=%s", uid, role)
+	return nil
+}
 
-	if !isAdmin || !signedUp {
-		log.Printf("Skipping claim for %s: admin=%v, signedUp=%v", email, isAdmin, signedUp)
-		return nil
+// applyAdminClaim writes the current custom claims back with the admin flag set or removed
+func applyAdminClaim(ctx context.Context, authClient *auth.Client, uid string, current map[string]interface{}, admin bool) error {
+	claims := make(map[string]interface{}, len(current)+1)
+	for key, value := range current {
+		claims[key] = value
 	}
-
-	// Get Firebase user
-	user, err := authClient.GetUserByEmail(ctx, email)
-	if err != nil {
-		return fmt.Errorf("firebase user not found: %w", err)
+	if admin {
+		claims["admin"] = true
+	} else {
+		delete(claims, "admin")
 	}
 
-	// Set claim
-	err = authClient.SetCustomUserClaims(ctx, user.UID, map[string]interface{}{"admin": true})
-	if err != nil {
-		return fmt.Errorf("failed to set admin claim: %w", err)
+	if err := authClient.SetCustomUserClaims(ctx, uid, claims); err != nil {
+		return fmt.Errorf("failed to set claims for %s: %w", uid, err)
 	}
-
-	log.Printf("✅ Admin claim set for: %s", email)
 	return nil
-}
\ No newline at end of file
+}
+
+// hasAdminClaim reports whether a set of custom claims grants admin access
+func hasAdminClaim(claims map[string]interface{}) bool {
+	isAdmin, _ := claims["admin"].(bool)
+	return isAdmin
+}
@@ -0,0 +1,173 @@
+package firebase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/auth"
+	"google.golang.org/api/iterator"
+
+	"littleeinsteinchildcare/backend/internal/models"
+)
+
+// ClaimsReconciler periodically compares the roles stored in Azure Tables with Firebase custom claims
+// and only writes the differences, granting admin to promoted users and removing it from demoted ones
+type ClaimsReconciler struct {
+	app      *firebase.App
+	users    UserSource
+	interval time.Duration
+
+	mutex   sync.RWMutex
+	running sync.Mutex
+	last    *models.ClaimsReconcileResult
+}
+
+// NewClaimsReconciler creates a reconciler that runs every interval once started
+func NewClaimsReconciler(app *firebase.App, users UserSource, interval time.Duration) *ClaimsReconciler {
+	return &ClaimsReconciler{
+		app:      app,
+		users:    users,
+		interval: interval,
+	}
+}
+
+// Start runs a first pass in the background and then one pass per interval until ctx is cancelled
+func (r *ClaimsReconciler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		r.RunOnce(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("Claims reconciler stopped")
+				return
+			case <-ticker.C:
+				r.RunOnce(ctx)
+			}
+		}
+	}()
+	log.Printf("Claims reconciler started, interval: %v", r.interval)
+}
+
+// LastResult returns the result of the most recent completed pass, or nil if none has finished yet
+func (r *ClaimsReconciler) LastResult() *models.ClaimsReconcileResult {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.last
+}
+
+// RunOnce performs a single reconciliation pass. If another pass is in progress the call is skipped and
+// ran is false, with the last completed result (nil if none) returned instead
+func (r *ClaimsReconciler) RunOnce(ctx context.Context) (result *models.ClaimsReconcileResult, ran bool) {
+	if !r.running.TryLock() {
+		return r.LastResult(), false
+	}
+	defer r.running.Unlock()
+
+	result = &models.ClaimsReconcileResult{
+		StartedAt: time.Now().UTC(),
+		Granted:   []string{},
+		Revoked:   []string{},
+		Missing:   []string{},
+		Errors:    []string{},
+	}
+	if err := r.reconcile(ctx, result); err != nil {
+		result.Error = err.Error()
+		log.Printf("Claims reconciler: pass failed: %v", err)
+	}
+	result.FinishedAt = time.Now().UTC()
+
+	log.Printf("Claims reconciler: checked %d users, granted %d, revoked %d, missing %d, errors %d",
+		result.UsersChecked, len(result.Granted), len(result.Revoked), len(result.Missing), len(result.Errors))
+
+	r.mutex.Lock()
+	r.last = result
+	r.mutex.Unlock()
+	return result, true
+}
+
+func (r *ClaimsReconciler) reconcile(ctx context.Context, result *models.ClaimsReconcileResult) error {
+	authClient, err := r.app.Auth(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to init Firebase Auth: %w", err)
+	}
+
+	storedUsers, err := r.users.GetAllUsers()
+	if err != nil {
+		return fmt.Errorf("failed to list stored users: %w", err)
+	}
+	// A missing or unreachable users table lists as empty, which would otherwise revoke every admin
+	if len(storedUsers) == 0 {
+		return errors.New("no stored users found, skipping the pass rather than revoking every admin")
+	}
+
+	// Desired state: every stored user whose role is admin
+	desiredAdmins := make(map[string]bool)
+	for _, user := range storedUsers {
+		if user.Role == models.RoleAdmin {
+			desiredAdmins[user.ID] = true
+		}
+	}
+
+	// Current state: page through Firebase accounts instead of one lookup per user
+	seen := make(map[string]bool, len(desiredAdmins))
+	revoke := []*auth.ExportedUserRecord{}
+	iter := authClient.Users(ctx, "")
+	for {
+		record, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list Firebase users: %w", err)
+		}
+		result.UsersChecked++
+		seen[record.UID] = true
+
+		wantAdmin := desiredAdmins[record.UID]
+		hasAdmin := hasAdminClaim(record.CustomClaims)
+		if wantAdmin == hasAdmin {
+			continue
+		}
+
+		if !wantAdmin {
+			revoke = append(revoke, record)
+			continue
+		}
+		if err := applyAdminClaim(ctx, authClient, record.UID, record.CustomClaims, true); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.Granted = append(result.Granted, record.UID)
+	}
+
+	// Stored users without a single admin while Firebase has some points at bad data, not a demotion of everyone
+	if len(desiredAdmins) == 0 && len(revoke) > 0 {
+		return fmt.Errorf("no stored admins but %d Firebase admins, skipping the pass rather than revoking them", len(revoke))
+	}
+	for _, record := range revoke {
+		if err := applyAdminClaim(ctx, authClient, record.UID, record.CustomClaims, false); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		// Revoked admins must sign in again so their current session loses the claim
+		if err := authClient.RevokeRefreshTokens(ctx, record.UID); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to revoke refresh tokens for %s: %v", record.UID, err))
+		}
+		result.Revoked = append(result.Revoked, record.UID)
+	}
+
+	for uid := range desiredAdmins {
+		if !seen[uid] {
+			result.Missing = append(result.Missing, uid)
+		}
+	}
+	return nil
+}
@@ -46,12 +46,33 @@ func FirebaseAuthMiddleware(next http.Handler) http.Handler {
 			ctx = context.WithValue(ctx, common.ContextEmail, email)
 			log.Printf("DEBUG: Email from token: %s", email)
 		}
+		isAdmin, _ := token.Claims["admin"].(bool)
+		if isAdmin {
+			// Admin tokens are re-checked against revocation so a demoted admin loses access immediately
+			if _, err := authClient.VerifyIDTokenAndCheckRevoked(r.Context(), idToken); err != nil {
+				log.Printf("DEBUG: Admin token revoked: %v", err)
+				utils.RespondUnauthorized(w, "Your session is invalid or has expired. Please sign in again.")
+				return
+			}
+		}
+		ctx = context.WithValue(ctx, common.ContextAdmin, isAdmin)
 		
 		log.Printf("DEBUG: Calling next handler with UID in context")
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// RequireAdmin rejects requests whose token does not carry the admin claim
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !utils.IsAdmin(r) {
+			utils.WriteJSONError(w, http.StatusForbidden, "Admin access required", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
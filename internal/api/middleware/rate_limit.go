@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"littleeinsteinchildcare/backend/internal/utils"
+)
+
+// RateLimit allows each client IP at most limit requests per window, rejecting the rest with 429.
+// Counts are kept in memory, which is enough to slow down form spam on a single instance. trustForwardedFor
+// reads the client IP from X-Forwarded-For and must only be set when every request comes through the proxy
+func RateLimit(limit int, window time.Duration, trustForwardedFor bool, next http.Handler) http.Handler {
+	var mu sync.Mutex
+	hits := make(map[string][]time.Time)
+
+	// Drop clients that have gone quiet once per window so the map doesn't grow without bound
+	go func() {
+		ticker := time.NewTicker(window)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			mu.Lock()
+			for key, times := range hits {
+				if len(times) == 0 || now.Sub(times[len(times)-1]) >= window {
+					delete(hits, key)
+				}
+			}
+			mu.Unlock()
+		}
+	}()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r, trustForwardedFor)
+		now := time.Now()
+
+		mu.Lock()
+		recent := []time.Time{}
+		for _, at := range hits[ip] {
+			if now.Sub(at) < window {
+				recent = append(recent, at)
+			}
+		}
+		allowed := len(recent) < limit
+		if allowed {
+			recent = append(recent, now)
+		}
+		hits[ip] = recent
+		mu.Unlock()
+
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(window.Seconds())))
+			utils.WriteJSONError(w, http.StatusTooManyRequests, "Too many requests, please try again later", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP uses the connection's remote address, or behind a trusted proxy the right-most X-Forwarded-For address,
+// the hop appended by the App Service front end. Entries to its left come from the client and can be forged, so they are ignored
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); trustForwardedFor && forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		if last := strings.TrimSpace(hops[len(hops)-1]); last != "" {
+			// The front end may include the client port
+			if host, _, err := net.SplitHostPort(last); err == nil {
+				return host
+			}
+			return last
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/api/middleware"
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterAdminRoutes sets up admin-only maintenance routes
+func RegisterAdminRoutes(router *http.ServeMux, adminHandler *handlers.AdminHandler) {
+	router.Handle("GET /api/admin/claims/status", middleware.RequireAdmin(http.HandlerFunc(adminHandler.GetClaimsStatus)))
+	router.Handle("POST /api/admin/claims/reconcile", middleware.RequireAdmin(http.HandlerFunc(adminHandler.ReconcileClaims)))
+}
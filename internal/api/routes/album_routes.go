@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/api/middleware"
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterAlbumRoutes sets up photo album, upload and tagging routes and the admin consent review report
+func RegisterAlbumRoutes(router *http.ServeMux, albumHandler *handlers.AlbumHandler) {
+	router.HandleFunc("GET /api/albums", albumHandler.GetAlbums)
+	router.HandleFunc("POST /api/albums", albumHandler.CreateAlbum)
+	router.HandleFunc("GET /api/albums/{id}/photos", albumHandler.GetPhotos)
+	router.HandleFunc("POST /api/albums/{id}/photos", albumHandler.AddPhoto)
+	router.HandleFunc("PUT /api/albums/{id}/photos/{photoId}", albumHandler.TagPhoto)
+	router.HandleFunc("DELETE /api/albums/{id}/photos/{photoId}", albumHandler.DeletePhoto)
+	router.HandleFunc("GET /api/albums/{id}/photos/{photoId}/image", albumHandler.GetPhotoImage)
+
+	router.Handle("GET /api/admin/photos/review", middleware.RequireAdmin(http.HandlerFunc(albumHandler.GetPhotoReviews)))
+}
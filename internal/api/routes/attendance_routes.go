@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterAttendanceRoutes sets up drop-off, pickup and daily roster routes
+func RegisterAttendanceRoutes(router *http.ServeMux, attendanceHandler *handlers.AttendanceHandler) {
+	router.HandleFunc("POST /api/attendance/checkin", attendanceHandler.CheckIn)
+	router.HandleFunc("POST /api/attendance/checkout", attendanceHandler.CheckOut)
+	router.HandleFunc("GET /api/attendance/classrooms/{id}", attendanceHandler.GetDayRoster)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterBillingRoutes sets up tuition plan and invoice routes
+func RegisterBillingRoutes(router *http.ServeMux, billingHandler *handlers.BillingHandler) {
+	router.HandleFunc("GET /api/children/{id}/tuition-plan", billingHandler.GetPlan)
+	router.HandleFunc("PUT /api/children/{id}/tuition-plan", billingHandler.SetPlan)
+
+	router.HandleFunc("POST /api/invoices/generate", billingHandler.GenerateInvoices)
+	router.HandleFunc("GET /api/invoices", billingHandler.GetInvoices)
+	router.HandleFunc("GET /api/invoices/{id}", billingHandler.GetInvoice)
+	router.HandleFunc("GET /api/invoices/{id}/html", billingHandler.GetInvoiceHTML)
+	router.HandleFunc("POST /api/invoices/{id}/pay", billingHandler.PayInvoice)
+	router.HandleFunc("POST /api/invoices/{id}/void", billingHandler.VoidInvoice)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterChildRoutes sets up all child and guardian related routes
+func RegisterChildRoutes(router *http.ServeMux, childHandler *handlers.ChildHandler) {
+	router.HandleFunc("GET /api/children", childHandler.GetChildren)
+	router.HandleFunc("POST /api/children", childHandler.CreateChild)
+	router.HandleFunc("GET /api/children/{id}", childHandler.GetChild)
+	router.HandleFunc("PUT /api/children/{id}", childHandler.UpdateChild)
+	router.HandleFunc("DELETE /api/children/{id}", childHandler.DeleteChild)
+
+	router.HandleFunc("POST /api/children/{id}/guardians", childHandler.AddGuardian)
+	router.HandleFunc("DELETE /api/children/{id}/guardians/{userId}", childHandler.RemoveGuardian)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterClassroomRoutes sets up all classroom, roster and teacher assignment routes
+func RegisterClassroomRoutes(router *http.ServeMux, classroomHandler *handlers.ClassroomHandler) {
+	router.HandleFunc("GET /api/classrooms", classroomHandler.GetAllClassrooms)
+	router.HandleFunc("POST /api/classrooms", classroomHandler.CreateClassroom)
+	router.HandleFunc("GET /api/classrooms/{id}", classroomHandler.GetClassroom)
+	router.HandleFunc("PUT /api/classrooms/{id}", classroomHandler.UpdateClassroom)
+	router.HandleFunc("DELETE /api/classrooms/{id}", classroomHandler.DeleteClassroom)
+
+	router.HandleFunc("GET /api/classrooms/{id}/roster", classroomHandler.GetRoster)
+	router.HandleFunc("POST /api/classrooms/{id}/teachers", classroomHandler.AssignTeacher)
+	router.HandleFunc("DELETE /api/classrooms/{id}/teachers/{userId}", classroomHandler.UnassignTeacher)
+}
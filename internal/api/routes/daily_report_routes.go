@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterDailyReportRoutes sets up daily entry logging and daily report routes
+func RegisterDailyReportRoutes(router *http.ServeMux, dailyReportHandler *handlers.DailyReportHandler) {
+	router.HandleFunc("GET /api/children/{id}/daily-report", dailyReportHandler.GetDailyReport)
+	router.HandleFunc("POST /api/children/{id}/daily-entries", dailyReportHandler.AddEntry)
+	router.HandleFunc("DELETE /api/children/{id}/daily-entries/{date}/{entryId}", dailyReportHandler.DeleteEntry)
+	router.HandleFunc("GET /api/children/{id}/daily-entries/{date}/{entryId}/photo", dailyReportHandler.GetEntryPhoto)
+}
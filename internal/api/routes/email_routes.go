@@ -1,11 +1,13 @@
 package routes
 import (
 	"net/http"
+	"littleeinsteinchildcare/backend/internal/api/middleware"
 	"littleeinsteinchildcare/backend/internal/handlers"
 )
 
 func RegisterProtectedEmailRoutes(routes *http.ServeMux, emailHandler *handlers.EmailHandler) {
-	routes.HandleFunc("POST /api/send-invite", emailHandler.SendInvite)
+	// Only admins may invite new accounts
+	routes.Handle("POST /api/send-invite", middleware.RequireAdmin(http.HandlerFunc(emailHandler.SendInvite)))
 }
 
 func RegisterUnprotectedEmailRoutes(routes *http.ServeMux, emailHandler *handlers.EmailHandler) {
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterHealthRecordRoutes sets up health record and compliance dashboard routes
+func RegisterHealthRecordRoutes(router *http.ServeMux, healthRecordHandler *handlers.HealthRecordHandler) {
+	router.HandleFunc("GET /api/children/{id}/health-records", healthRecordHandler.GetRecords)
+	router.HandleFunc("POST /api/children/{id}/health-records", healthRecordHandler.AddRecord)
+	router.HandleFunc("DELETE /api/children/{id}/health-records/{recordId}", healthRecordHandler.DeleteRecord)
+	router.HandleFunc("GET /api/children/{id}/health-records/{recordId}/document", healthRecordHandler.GetDocument)
+
+	router.HandleFunc("GET /api/health-records/compliance", healthRecordHandler.GetCompliance)
+}
@@ -10,6 +10,7 @@ import (
 func RegisterBlobRoutes(r *http.ServeMux, imageHandler *handlers.ImageHandler) {
 	// Image routes
 	r.HandleFunc("GET /api/image/{id}/{fileName}", imageHandler.GetImage)
+	r.HandleFunc("GET /api/image/{id}/{fileName}/url", imageHandler.GetImageURL)
 	r.HandleFunc("GET /api/images", imageHandler.GetAllImages)
 
 	r.HandleFunc("POST /api/image", imageHandler.UploadImage)
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/api/middleware"
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterIncidentRoutes sets up incident report, guardian acknowledgment and export routes
+func RegisterIncidentRoutes(router *http.ServeMux, incidentHandler *handlers.IncidentHandler) {
+	router.HandleFunc("POST /api/incidents", incidentHandler.CreateIncident)
+	router.HandleFunc("GET /api/incidents/{id}", incidentHandler.GetIncident)
+	router.HandleFunc("PUT /api/incidents/{id}", incidentHandler.UpdateIncident)
+	router.HandleFunc("POST /api/incidents/{id}/submit", incidentHandler.SubmitIncident)
+	router.HandleFunc("POST /api/incidents/{id}/acknowledge", incidentHandler.AcknowledgeIncident)
+	router.HandleFunc("GET /api/children/{id}/incidents", incidentHandler.GetChildIncidents)
+
+	router.Handle("GET /api/admin/incidents/export", middleware.RequireAdmin(http.HandlerFunc(incidentHandler.ExportIncidents)))
+}
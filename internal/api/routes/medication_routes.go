@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterMedicationRoutes sets up medication authorization and dose log routes
+func RegisterMedicationRoutes(router *http.ServeMux, medicationHandler *handlers.MedicationHandler) {
+	router.HandleFunc("GET /api/children/{id}/medications", medicationHandler.GetAuthorizations)
+	router.HandleFunc("POST /api/children/{id}/medications", medicationHandler.AddAuthorization)
+	router.HandleFunc("DELETE /api/children/{id}/medications/{authorizationId}", medicationHandler.RevokeAuthorization)
+	router.HandleFunc("POST /api/children/{id}/medications/{authorizationId}/doses", medicationHandler.LogDose)
+	router.HandleFunc("GET /api/children/{id}/medication-doses", medicationHandler.GetDoses)
+}
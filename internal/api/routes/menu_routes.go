@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/api/middleware"
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterMenuRoutes sets up weekly menu routes, publishing is limited to admins
+func RegisterMenuRoutes(router *http.ServeMux, menuHandler *handlers.MenuHandler) {
+	router.HandleFunc("GET /api/menus", menuHandler.GetMenu)
+
+	router.Handle("PUT /api/admin/menus/{week}", middleware.RequireAdmin(http.HandlerFunc(menuHandler.PublishMenu)))
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterMessageRoutes sets up guardian and staff messaging routes
+func RegisterMessageRoutes(router *http.ServeMux, messageHandler *handlers.MessageHandler) {
+	router.HandleFunc("GET /api/threads", messageHandler.GetThreads)
+	router.HandleFunc("POST /api/threads", messageHandler.StartThread)
+	router.HandleFunc("GET /api/threads/{id}/messages", messageHandler.GetMessages)
+	router.HandleFunc("POST /api/threads/{id}/messages", messageHandler.SendMessage)
+	router.HandleFunc("POST /api/threads/{id}/read", messageHandler.MarkRead)
+	router.HandleFunc("GET /api/threads/{id}/messages/{messageId}/attachments/{index}", messageHandler.GetAttachment)
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterPickupRoutes sets up authorized pickup list and pickup audit routes
+func RegisterPickupRoutes(router *http.ServeMux, pickupHandler *handlers.PickupHandler) {
+	router.HandleFunc("GET /api/children/{id}/pickups", pickupHandler.GetPickups)
+	router.HandleFunc("POST /api/children/{id}/pickups", pickupHandler.AddPickup)
+	router.HandleFunc("DELETE /api/children/{id}/pickups/{pickupId}", pickupHandler.RemovePickup)
+	router.HandleFunc("GET /api/children/{id}/pickups/{pickupId}/photo", pickupHandler.GetPickupPhoto)
+	router.HandleFunc("GET /api/children/{id}/pickup-audit", pickupHandler.GetAudits)
+}
@@ -6,6 +6,7 @@ import (
 	"littleeinsteinchildcare/backend/firebase"
 	"littleeinsteinchildcare/backend/internal/config"
 	"littleeinsteinchildcare/backend/internal/handlers"
+	"littleeinsteinchildcare/backend/internal/models"
 	"littleeinsteinchildcare/backend/internal/repositories"
 	"littleeinsteinchildcare/backend/internal/services"
 	"log"
@@ -57,21 +58,99 @@ func SetupPrivateRouter() *http.ServeMux {
 		log.Fatalf("Router.SetupRouter: Failed to create blob repository: %v", err)
 	}
 	
-	blobService := services.NewBlobService(blobRepo)
+	// ---------- INVITE MODULE SETUP ----------
+	// Invites and the role granted at sign up live in Azure Tables alongside users
+	inviteRepo, err := repositories.NewInviteRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create invite repository: %v", err)
+	}
+	inviteService := services.NewInviteService(inviteRepo)
+
+	blobService := services.NewBlobService(blobRepo, userRepo, config.GetImageURLTTL())
 	// Create user service with repository dependency
 	// This service will handle business logic for user operations
 	userService := services.NewUserService(userRepo, eventRepo, blobRepo)
 
+	// Keep Firebase admin claims in step with the roles stored in the Users table
+	claimsReconciler := firebase.NewClaimsReconciler(firebase.Init(), userService, config.GetClaimsReconcileInterval())
+	claimsReconciler.Start(context.Background())
+
+	// Role changes update the Users table, Firebase custom claims and the role change audit table
+	roleChangeRepo, err := repositories.NewRoleChangeRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create role change repository: %v", err)
+	}
+	roleService := services.NewRoleService(userRepo, roleChangeRepo, firebase.NewClaimsManager(firebase.Init()))
+
+	// ---------- CHILD MODULE SETUP ----------
+	// Children and guardian links are stored in their own tables and linked to users by ID
+	childRepo, err := repositories.NewChildRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create child repository: %v", err)
+	}
+	childService := services.NewChildService(childRepo, userRepo)
+	childHandler := handlers.NewChildHandler(childService)
+	RegisterChildRoutes(router, childHandler)
+
+	// ---------- CLASSROOM MODULE SETUP ----------
+	// Teachers are assigned through their user record, children through their classroom field
+	classroomRepo, err := repositories.NewClassroomRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create classroom repository: %v", err)
+	}
+	classroomService := services.NewClassroomService(classroomRepo, userRepo, childRepo)
+	classroomHandler := handlers.NewClassroomHandler(classroomService)
+	RegisterClassroomRoutes(router, classroomHandler)
+
+	// ---------- ATTENDANCE MODULE SETUP ----------
+	// Drop-off and pickup records are partitioned by date so a day's roster is a single partition query
+	attendanceRepo, err := repositories.NewAttendanceRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create attendance repository: %v", err)
+	}
+	// Checkouts are verified against guardians and each child's authorized pickup list
+	pickupRepo, err := repositories.NewPickupRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create pickup repository: %v", err)
+	}
+	pickupService := services.NewPickupService(pickupRepo, blobRepo, childRepo, userRepo)
+	pickupHandler := handlers.NewPickupHandler(pickupService)
+	RegisterPickupRoutes(router, pickupHandler)
+
+	attendanceService := services.NewAttendanceService(attendanceRepo, childRepo, userRepo, pickupService)
+	attendanceHandler := handlers.NewAttendanceHandler(attendanceService)
+	RegisterAttendanceRoutes(router, attendanceHandler)
+
+	// Created ahead of the modules below since messaging and the user handler's unread counts need it
+	apiKey := os.Getenv("SENDGRID_API_KEY")
+	emailService := services.NewSendGridService(
+		"Little Einstein",
+		"hello@littleeinsteinchildcare.org",
+		apiKey,
+	)
+
+	// ---------- MESSAGE MODULE SETUP ----------
+	// Guardians and staff message one to one or through classroom broadcasts, emails are held during quiet hours
+	messageRepo, err := repositories.NewMessageRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create message repository: %v", err)
+	}
+	quietStart, quietEnd := config.GetMessageQuietHours()
+	messageService := services.NewMessageService(messageRepo, childRepo, userRepo, blobRepo, emailService, models.QuietHours{Start: quietStart, End: quietEnd})
+	messageService.StartQuietHoursSummaries(context.Background())
+	messageHandler := handlers.NewMessageHandler(messageService)
+	RegisterMessageRoutes(router, messageHandler)
+
 	// Initialize user handler with service dependency
 	// This handler will process HTTP requests and use the service layer
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, inviteService, roleService, childService, messageService)
 
 	// Register all user-related routes (create, get, update, delete)
 	RegisterUserRoutes(router, userHandler)
 
 	// Create event service with repository dependency
 	// This service will handle business logic for event operations
-	eventService := services.NewEventService(eventRepo, *userService)
+	eventService := services.NewEventService(eventRepo, *userService, classroomService)
 
 	// Initialize event handler with event service and user service dependencies
 	// The handler needs user service to validate user relationships with events
@@ -87,23 +166,98 @@ func SetupPrivateRouter() *http.ServeMux {
 
 	// Register Azure B2C auth endpoint
 
+	// ---------- ALBUM MODULE SETUP ----------
+	// Staff share event and classroom photos tagged with the children in them, families only see their own children's
+	albumRepo, err := repositories.NewAlbumRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create album repository: %v", err)
+	}
+	albumService := services.NewAlbumService(albumRepo, blobRepo, childRepo, userRepo, eventRepo)
+	albumHandler := handlers.NewAlbumHandler(albumService)
+	RegisterAlbumRoutes(router, albumHandler)
+
 	// ---------- EMAIL MODULE SETUP ----------
 
-	apiKey := os.Getenv("SENDGRID_API_KEY")
-	emailService := services.NewSendGridService(
-		"Little Einstein",
-		"hello@littleeinsteinchildcare.org",
-		apiKey,
-	)
+	emailHandler := handlers.NewEmailHandler(emailService, inviteService)
 
-	ctx := context.Background()
-	fsClient, err := firebase.Firestore(ctx)
+	RegisterProtectedEmailRoutes(router, emailHandler)
+
+	// ---------- INCIDENT MODULE SETUP ----------
+	// Staff draft and submit incident reports, guardians are emailed and acknowledge them in the app
+	incidentRepo, err := repositories.NewIncidentRepo(*azTableCfg)
 	if err != nil {
-		panic("Failed to connect to Firestore for private routes: " + err.Error())
+		log.Fatalf("Router.SetupRouter: Failed to create incident repository: %v", err)
 	}
-	emailHandler := handlers.NewEmailHandler(emailService, fsClient)
+	incidentService := services.NewIncidentService(incidentRepo, childRepo, userRepo, emailService)
+	incidentHandler := handlers.NewIncidentHandler(incidentService)
+	RegisterIncidentRoutes(router, incidentHandler)
 
-	RegisterProtectedEmailRoutes(router, emailHandler)
+	// ---------- HEALTH RECORD MODULE SETUP ----------
+	// Immunizations and physical exams are checked against the required list and guardians reminded of gaps
+	healthRecordRepo, err := repositories.NewHealthRecordRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create health record repository: %v", err)
+	}
+	healthRecordService := services.NewHealthRecordService(healthRecordRepo, blobRepo, childRepo, userRepo, emailService, config.GetRequiredHealthRecords(), config.GetHealthExpiryWarning())
+	healthRecordService.StartHealthReminders(context.Background(), config.GetHealthReminderInterval(), config.GetHealthReminderTime())
+	healthRecordHandler := handlers.NewHealthRecordHandler(healthRecordService)
+	RegisterHealthRecordRoutes(router, healthRecordHandler)
+
+	// ---------- MEDICATION MODULE SETUP ----------
+	// Guardians sign medication authorizations and staff log each dose against them
+	medicationRepo, err := repositories.NewMedicationRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create medication repository: %v", err)
+	}
+	medicationService := services.NewMedicationService(medicationRepo, childRepo, userRepo)
+	medicationHandler := handlers.NewMedicationHandler(medicationService)
+	RegisterMedicationRoutes(router, medicationHandler)
+
+	// ---------- DAILY REPORT MODULE SETUP ----------
+	// Teachers log each child's day, guardians read it in the app and get an end of day digest email
+	dailyEntryRepo, err := repositories.NewDailyEntryRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create daily entry repository: %v", err)
+	}
+	dailyReportService := services.NewDailyReportService(dailyEntryRepo, childRepo, userRepo, blobRepo, emailService)
+	dailyReportService.StartDailyDigest(context.Background(), config.GetDailyDigestTime())
+	dailyReportHandler := handlers.NewDailyReportHandler(dailyReportService)
+	RegisterDailyReportRoutes(router, dailyReportHandler)
+
+	// ---------- BILLING MODULE SETUP ----------
+	// Monthly invoices are built from tuition plans and late pickups; payments go through the local provider until a real one is configured
+	billingRepo, err := repositories.NewBillingRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create billing repository: %v", err)
+	}
+	latePickupPolicy := models.LatePickupPolicy{
+		ClosingTime:       config.GetLatePickupClosingTime(),
+		Grace:             config.GetLatePickupGrace(),
+		FeeCentsPerMinute: config.GetLatePickupFeePerMinute(),
+	}
+	billingService := services.NewBillingService(billingRepo, childRepo, userRepo, attendanceRepo, emailService, services.NewLocalPaymentProvider(), latePickupPolicy, config.GetInvoiceDueDays())
+	billingHandler := handlers.NewBillingHandler(billingService)
+	RegisterBillingRoutes(router, billingHandler)
+
+	// ---------- MENU MODULE SETUP ----------
+	// Admins publish the weekly menu and dishes are checked against enrolled children's allergies
+	menuRepo, err := repositories.NewMenuRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create menu repository: %v", err)
+	}
+	menuService := services.NewMenuService(menuRepo, childRepo, userRepo)
+	menuHandler := handlers.NewMenuHandler(menuService)
+	RegisterMenuRoutes(router, menuHandler)
+
+	// ---------- WAITLIST MODULE SETUP ----------
+	// Admins work through public enrollment applications and turn accepted ones into child records and guardian invites
+	waitlistRepo, err := repositories.NewWaitlistRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create waitlist repository: %v", err)
+	}
+	waitlistService := services.NewWaitlistService(waitlistRepo, childService, inviteService, userRepo, emailService)
+	waitlistHandler := handlers.NewWaitlistHandler(waitlistService)
+	RegisterWaitlistRoutes(router, waitlistHandler)
 
 	// ---------- BANNER MODULE SETUP ----------
 	// Create banner service (no repository needed)
@@ -115,6 +269,23 @@ func SetupPrivateRouter() *http.ServeMux {
 	// Register all banner-related routes
 	RegisterBannerRoutes(router, bannerHandler)
 
+	// ---------- STAFF RATIO MODULE SETUP ----------
+	// Staff shifts are compared with attendance to keep classrooms within licensing ratios
+	shiftRepo, err := repositories.NewShiftRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupRouter: Failed to create shift repository: %v", err)
+	}
+	shiftService := services.NewShiftService(shiftRepo, userRepo)
+	ratioService := services.NewRatioService(classroomRepo, childRepo, attendanceRepo, shiftRepo, userRepo)
+	ratioMonitor := services.NewRatioMonitor(ratioService, emailService, bannerService, userRepo, config.GetRatioCheckInterval())
+	ratioMonitor.Start(context.Background())
+	shiftHandler := handlers.NewShiftHandler(shiftService, ratioService)
+	RegisterShiftRoutes(router, shiftHandler)
+
+	// ---------- ADMIN MODULE SETUP ----------
+	adminHandler := handlers.NewAdminHandler(claimsReconciler)
+	RegisterAdminRoutes(router, adminHandler)
+
 	// ----------Register Azure B2C Auth Endpoint ----------
 
 	registerAzureB2CEndpoint(router)
@@ -204,13 +375,25 @@ func SetupPublicRouter() *http.ServeMux {
 	// Create a new HTTP router instance for public routes
 	router := http.NewServeMux()
 
-	ctx := context.Background()
-	fsClient, err := firebase.Firestore(ctx)
+	azTableCfg, err := config.LoadAzTableConfig()
+	if err != nil {
+		log.Fatalf("Router.SetupPublicRouter: Failed to load Azure Table config: %v", err)
+	}
+	inviteRepo, err := repositories.NewInviteRepo(*azTableCfg)
 	if err != nil {
-		panic("Failed to connect to Firestore for public routes: " + err.Error())
+		log.Fatalf("Router.SetupPublicRouter: Failed to create invite repository: %v", err)
 	}
-	emailHandler := handlers.NewEmailHandler(nil, fsClient);
+	emailHandler := handlers.NewEmailHandler(nil, services.NewInviteService(inviteRepo));
 	RegisterUnprotectedEmailRoutes(router, emailHandler);
 
+	// Prospective families apply without an account, only submitting needs the repository and confirmation email
+	waitlistRepo, err := repositories.NewWaitlistRepo(*azTableCfg)
+	if err != nil {
+		log.Fatalf("Router.SetupPublicRouter: Failed to create waitlist repository: %v", err)
+	}
+	emailService := services.NewSendGridService("Little Einstein", "hello@littleeinsteinchildcare.org", os.Getenv("SENDGRID_API_KEY"))
+	waitlistHandler := handlers.NewWaitlistHandler(services.NewWaitlistService(waitlistRepo, nil, nil, nil, emailService))
+	RegisterPublicWaitlistRoutes(router, waitlistHandler, config.GetTrustForwardedFor())
+
 	return router
 }
\ No newline at end of file
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"net/http"
+
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterShiftRoutes sets up staff clock-in/out and classroom ratio routes
+func RegisterShiftRoutes(router *http.ServeMux, shiftHandler *handlers.ShiftHandler) {
+	router.HandleFunc("POST /api/shifts/clockin", shiftHandler.ClockIn)
+	router.HandleFunc("POST /api/shifts/clockout", shiftHandler.ClockOut)
+	router.HandleFunc("GET /api/ratios", shiftHandler.GetRatios)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"net/http"
 
+	"littleeinsteinchildcare/backend/internal/api/middleware"
 	"littleeinsteinchildcare/backend/internal/handlers"
 )
 
@@ -16,4 +17,8 @@ func RegisterUserRoutes(router *http.ServeMux, userHandler *handlers.UserHandler
 	router.HandleFunc("POST /api/user", userHandler.CreateUser)
 	router.HandleFunc("POST /api/user/sync", userHandler.SyncFirebaseUser)
 
+	// Admin-only role management
+	router.Handle("PUT /api/user/{id}/role", middleware.RequireAdmin(http.HandlerFunc(userHandler.UpdateUserRole)))
+	router.Handle("GET /api/user/{id}/role-history", middleware.RequireAdmin(http.HandlerFunc(userHandler.GetUserRoleHistory)))
+
 }
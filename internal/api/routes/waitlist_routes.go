@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"littleeinsteinchildcare/backend/internal/api/middleware"
+	"littleeinsteinchildcare/backend/internal/handlers"
+)
+
+// RegisterPublicWaitlistRoutes sets up the unauthenticated enrollment application form, rate limited per client
+func RegisterPublicWaitlistRoutes(router *http.ServeMux, waitlistHandler *handlers.WaitlistHandler, trustForwardedFor bool) {
+	router.Handle("POST /enrollment/applications", middleware.RateLimit(5, time.Hour, trustForwardedFor, http.HandlerFunc(waitlistHandler.SubmitApplication)))
+}
+
+// RegisterWaitlistRoutes sets up the admin waitlist and enrollment routes
+func RegisterWaitlistRoutes(router *http.ServeMux, waitlistHandler *handlers.WaitlistHandler) {
+	router.Handle("GET /api/admin/waitlist", middleware.RequireAdmin(http.HandlerFunc(waitlistHandler.GetWaitlist)))
+	router.Handle("GET /api/admin/waitlist/{id}", middleware.RequireAdmin(http.HandlerFunc(waitlistHandler.GetApplication)))
+	router.Handle("PUT /api/admin/waitlist/{id}", middleware.RequireAdmin(http.HandlerFunc(waitlistHandler.UpdateApplication)))
+	router.Handle("POST /api/admin/waitlist/{id}/status", middleware.RequireAdmin(http.HandlerFunc(waitlistHandler.TransitionApplication)))
+	router.Handle("POST /api/admin/waitlist/{id}/enroll", middleware.RequireAdmin(http.HandlerFunc(waitlistHandler.EnrollApplication)))
+}
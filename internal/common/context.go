@@ -8,4 +8,6 @@ const (
 	ContextUID contextKey = "uid"
 	// ContextEmail stores the Firebase user email in request context  
 	ContextEmail contextKey = "email"
+	// ContextAdmin stores whether the Firebase token carries the admin claim
+	ContextAdmin contextKey = "admin"
 )
\ No newline at end of file
@@ -5,8 +5,46 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// Default interval between admin claim reconciliation passes
+const defaultClaimsReconcileInterval = 15 * time.Minute
+
+// Default interval between staff ratio checks
+const defaultRatioCheckInterval = time.Minute
+
+// Default time of day the daily report digest is emailed, as an offset from local midnight
+const defaultDailyDigestTime = 17*time.Hour + 30*time.Minute
+
+// Default records every enrolled child must keep on file, "physical" is the physical exam and the rest are vaccines
+const defaultRequiredHealthRecords = "physical,DTaP,Polio,MMR,Hib,Hepatitis B,Varicella"
+
+// Default window before expiry in which health records are flagged and guardians reminded
+const defaultHealthExpiryWarning = 30 * 24 * time.Hour
+
+// Default interval between health record reminder emails to guardians
+const defaultHealthReminderInterval = 7 * 24 * time.Hour
+
+// Default time of day health record reminders are emailed, as an offset from local midnight
+const defaultHealthReminderTime = 8 * time.Hour
+
+// Default late pickup policy: closing time as an offset from local midnight, grace period and fee in cents per minute
+const defaultLatePickupClosingTime = 18 * time.Hour
+const defaultLatePickupGrace = 5 * time.Minute
+const defaultLatePickupFeePerMinute = 100
+
+// Default number of days after issue an invoice is due
+const defaultInvoiceDueDays = 14
+
+// Default window in which new message emails are held back, as offsets from midnight in the center's time zone
+const defaultMessageQuietStart = 21 * time.Hour
+const defaultMessageQuietEnd = 7 * time.Hour
+
+// Default lifetime of a signed image URL
+const defaultImageURLTTL = 15 * time.Minute
+
 // ServerConfig holds application configuration
 type ServerConfig struct {
 	Port        int
@@ -77,6 +115,39 @@ func getHost(environment string) string {
 	}
 }
 
+// GetClaimsReconcileInterval reads CLAIMS_RECONCILE_INTERVAL (e.g. "10m"), falling back to the default
+func GetClaimsReconcileInterval() time.Duration {
+	if intervalEnv := os.Getenv("CLAIMS_RECONCILE_INTERVAL"); intervalEnv != "" {
+		if interval, err := time.ParseDuration(intervalEnv); err == nil && interval > 0 {
+			return interval
+		}
+		log.Printf("Warning: Invalid CLAIMS_RECONCILE_INTERVAL environment variable '%s'", intervalEnv)
+	}
+	return defaultClaimsReconcileInterval
+}
+
+// GetRatioCheckInterval reads RATIO_CHECK_INTERVAL (e.g. "30s"), falling back to the default
+func GetRatioCheckInterval() time.Duration {
+	if intervalEnv := os.Getenv("RATIO_CHECK_INTERVAL"); intervalEnv != "" {
+		if interval, err := time.ParseDuration(intervalEnv); err == nil && interval > 0 {
+			return interval
+		}
+		log.Printf("Warning: Invalid RATIO_CHECK_INTERVAL environment variable '%s'", intervalEnv)
+	}
+	return defaultRatioCheckInterval
+}
+
+// GetDailyDigestTime reads DAILY_DIGEST_TIME (24 hour "HH:MM" local time), falling back to the default
+func GetDailyDigestTime() time.Duration {
+	if timeEnv := os.Getenv("DAILY_DIGEST_TIME"); timeEnv != "" {
+		if at, err := time.Parse("15:04", timeEnv); err == nil {
+			return time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute
+		}
+		log.Printf("Warning: Invalid DAILY_DIGEST_TIME environment variable '%s'", timeEnv)
+	}
+	return defaultDailyDigestTime
+}
+
 // getEnv returns environment variable value or error if not set
 func getEnv(key string) (string, error) {
 	if value := os.Getenv(key); value != "" {
@@ -84,3 +155,152 @@ func getEnv(key string) (string, error) {
 	}
 	return "", fmt.Errorf("environment variable %s must be set to either production, development, or legacy", key)
 }
+
+// GetRequiredHealthRecords reads HEALTH_REQUIRED_RECORDS (comma separated), falling back to the default
+func GetRequiredHealthRecords() []string {
+	value := os.Getenv("HEALTH_REQUIRED_RECORDS")
+	if value == "" {
+		value = defaultRequiredHealthRecords
+	}
+	required := []string{}
+	for _, requirement := range strings.Split(value, ",") {
+		if requirement = strings.TrimSpace(requirement); requirement != "" {
+			required = append(required, requirement)
+		}
+	}
+	return required
+}
+
+// GetHealthExpiryWarning reads HEALTH_EXPIRY_WARNING (e.g. "720h"), falling back to the default
+func GetHealthExpiryWarning() time.Duration {
+	if warningEnv := os.Getenv("HEALTH_EXPIRY_WARNING"); warningEnv != "" {
+		if warning, err := time.ParseDuration(warningEnv); err == nil && warning >= 0 {
+			return warning
+		}
+		log.Printf("Warning: Invalid HEALTH_EXPIRY_WARNING environment variable '%s'", warningEnv)
+	}
+	return defaultHealthExpiryWarning
+}
+
+// GetHealthReminderInterval reads HEALTH_REMINDER_INTERVAL (e.g. "168h", rounded to whole days), falling back to the default
+func GetHealthReminderInterval() time.Duration {
+	if intervalEnv := os.Getenv("HEALTH_REMINDER_INTERVAL"); intervalEnv != "" {
+		if interval, err := time.ParseDuration(intervalEnv); err == nil && interval > 0 {
+			return interval
+		}
+		log.Printf("Warning: Invalid HEALTH_REMINDER_INTERVAL environment variable '%s'", intervalEnv)
+	}
+	return defaultHealthReminderInterval
+}
+
+// GetLatePickupClosingTime reads LATE_PICKUP_CLOSING_TIME (24 hour "HH:MM" local time), falling back to the default
+func GetLatePickupClosingTime() time.Duration {
+	if timeEnv := os.Getenv("LATE_PICKUP_CLOSING_TIME"); timeEnv != "" {
+		if at, err := time.Parse("15:04", timeEnv); err == nil {
+			return time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute
+		}
+		log.Printf("Warning: Invalid LATE_PICKUP_CLOSING_TIME environment variable '%s'", timeEnv)
+	}
+	return defaultLatePickupClosingTime
+}
+
+// GetLatePickupGrace reads LATE_PICKUP_GRACE (e.g. "10m"), falling back to the default
+func GetLatePickupGrace() time.Duration {
+	if graceEnv := os.Getenv("LATE_PICKUP_GRACE"); graceEnv != "" {
+		if grace, err := time.ParseDuration(graceEnv); err == nil && grace >= 0 {
+			return grace
+		}
+		log.Printf("Warning: Invalid LATE_PICKUP_GRACE environment variable '%s'", graceEnv)
+	}
+	return defaultLatePickupGrace
+}
+
+// GetLatePickupFeePerMinute reads LATE_PICKUP_FEE_PER_MINUTE in cents, 0 disables late fees, falling back to the default
+func GetLatePickupFeePerMinute() int64 {
+	if feeEnv := os.Getenv("LATE_PICKUP_FEE_PER_MINUTE"); feeEnv != "" {
+		if fee, err := strconv.ParseInt(feeEnv, 10, 64); err == nil && fee >= 0 {
+			return fee
+		}
+		log.Printf("Warning: Invalid LATE_PICKUP_FEE_PER_MINUTE environment variable '%s'", feeEnv)
+	}
+	return defaultLatePickupFeePerMinute
+}
+
+// GetInvoiceDueDays reads INVOICE_DUE_DAYS, falling back to the default
+func GetInvoiceDueDays() int {
+	if daysEnv := os.Getenv("INVOICE_DUE_DAYS"); daysEnv != "" {
+		if days, err := strconv.Atoi(daysEnv); err == nil && days >= 0 {
+			return days
+		}
+		log.Printf("Warning: Invalid INVOICE_DUE_DAYS environment variable '%s'", daysEnv)
+	}
+	return defaultInvoiceDueDays
+}
+
+// GetMessageQuietHours reads MESSAGE_QUIET_HOURS (24 hour "HH:MM-HH:MM" center time, equal times turn it off),
+// falling back to the default
+func GetMessageQuietHours() (time.Duration, time.Duration) {
+	if hoursEnv := os.Getenv("MESSAGE_QUIET_HOURS"); hoursEnv != "" {
+		if startValue, endValue, ok := strings.Cut(hoursEnv, "-"); ok {
+			start, startErr := time.Parse("15:04", strings.TrimSpace(startValue))
+			end, endErr := time.Parse("15:04", strings.TrimSpace(endValue))
+			if startErr == nil && endErr == nil {
+				return time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+					time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute
+			}
+		}
+		log.Printf("Warning: Invalid MESSAGE_QUIET_HOURS environment variable '%s'", hoursEnv)
+	}
+	return defaultMessageQuietStart, defaultMessageQuietEnd
+}
+
+// GetTrustForwardedFor reads TRUST_FORWARDED_FOR ("true" or "false"), whether requests only arrive through a proxy that
+// appends the client address to X-Forwarded-For. Defaults to true in production, which runs behind the App Service
+// front end, and false elsewhere so a client reaching the API directly cannot pick its own address
+func GetTrustForwardedFor() bool {
+	if trustEnv := os.Getenv("TRUST_FORWARDED_FOR"); trustEnv != "" {
+		if trust, err := strconv.ParseBool(trustEnv); err == nil {
+			return trust
+		}
+		log.Printf("Warning: Invalid TRUST_FORWARDED_FOR environment variable '%s'", trustEnv)
+	}
+	return os.Getenv("APP_ENV") == "production"
+}
+
+// GetImageURLTTL reads IMAGE_URL_TTL (e.g. "15m"), how long a signed image URL stays valid, falling back to the default
+func GetImageURLTTL() time.Duration {
+	if ttlEnv := os.Getenv("IMAGE_URL_TTL"); ttlEnv != "" {
+		if ttl, err := time.ParseDuration(ttlEnv); err == nil && ttl > 0 {
+			return ttl
+		}
+		log.Printf("Warning: Invalid IMAGE_URL_TTL environment variable '%s'", ttlEnv)
+	}
+	return defaultImageURLTTL
+}
+
+// GetCenterLocation reads CENTER_TIME_ZONE (an IANA name such as "America/Chicago"), the zone the center's
+// calendar days and opening hours are in. Falls back to the server's zone, which is UTC in the container
+func GetCenterLocation() *time.Location {
+	zone := os.Getenv("CENTER_TIME_ZONE")
+	if zone == "" {
+		log.Printf("Warning: CENTER_TIME_ZONE is not set, using server time zone %s", time.Local)
+		return time.Local
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		log.Printf("Warning: Invalid CENTER_TIME_ZONE environment variable '%s'", zone)
+		return time.Local
+	}
+	return location
+}
+
+// GetHealthReminderTime reads HEALTH_REMINDER_TIME (24 hour "HH:MM" local time), falling back to the default
+func GetHealthReminderTime() time.Duration {
+	if timeEnv := os.Getenv("HEALTH_REMINDER_TIME"); timeEnv != "" {
+		if at, err := time.Parse("15:04", timeEnv); err == nil {
+			return time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute
+		}
+		log.Printf("Warning: Invalid HEALTH_REMINDER_TIME environment variable '%s'", timeEnv)
+	}
+	return defaultHealthReminderTime
+}
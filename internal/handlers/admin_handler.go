@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"littleeinsteinchildcare/backend/internal/models"
+	"net/http"
+)
+
+// ClaimsReconciler interface implemented in firebase package
+type ClaimsReconciler interface {
+	LastResult() *models.ClaimsReconcileResult
+	RunOnce(ctx context.Context) (*models.ClaimsReconcileResult, bool)
+}
+
+// AdminHandler handles admin-only maintenance requests
+type AdminHandler struct {
+	claimsReconciler ClaimsReconciler
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(cr ClaimsReconciler) *AdminHandler {
+	return &AdminHandler{
+		claimsReconciler: cr,
+	}
+}
+
+// GetClaimsStatus handles GET requests for the last admin claims reconciliation result
+func (h *AdminHandler) GetClaimsStatus(w http.ResponseWriter, r *http.Request) {
+	result := h.claimsReconciler.LastResult()
+
+	w.Header().Set("Content-Type", "application/json")
+	if result == nil {
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Claims reconciliation has not completed yet",
+		})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// ReconcileClaims handles POST requests to run a reconciliation pass immediately
+func (h *AdminHandler) ReconcileClaims(w http.ResponseWriter, r *http.Request) {
+	// A pass writes claims user by user, so it runs detached from the request and finishes even if the client disconnects
+	result, ran := h.claimsReconciler.RunOnce(context.WithoutCancel(r.Context()))
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ran {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]any{
+			"message":    "A claims reconciliation pass is already in progress",
+			"lastResult": result,
+		})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
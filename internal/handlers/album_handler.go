@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"littleeinsteinchildcare/backend/internal/imaging"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AlbumService interface implemented in services package
+type AlbumService interface {
+	CreateAlbum(actorID string, album models.Album) (models.Album, error)
+	GetAlbums(actorID string) ([]models.Album, error)
+	GetPhotos(actorID string, albumID string) ([]models.AlbumPhoto, error)
+	GetPhotoImage(ctx context.Context, actorID string, albumID string, photoID string) (*models.ImageStream, error)
+	AddPhoto(ctx context.Context, actorID string, photo models.AlbumPhoto, data []byte) (models.AlbumPhoto, error)
+	TagPhoto(actorID string, albumID string, photoID string, childIDs []string, visibility models.PhotoVisibility) (models.AlbumPhoto, error)
+	DeletePhoto(ctx context.Context, actorID string, albumID string, photoID string) error
+	GetPhotoReviews() ([]models.PhotoReview, error)
+}
+
+// AlbumHandler handles HTTP requests for photo albums
+type AlbumHandler struct {
+	albumService AlbumService
+}
+
+// NewAlbumHandler creates a new album handler
+func NewAlbumHandler(s AlbumService) *AlbumHandler {
+	return &AlbumHandler{
+		albumService: s,
+	}
+}
+
+// CreateAlbum handles POST requests starting an album for a classroom or event
+func (h *AlbumHandler) CreateAlbum(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AlbumHandler.CreateAlbum: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		Title     string `json:"title"`
+		EventID   string `json:"eventId"`
+		Classroom string `json:"classroom"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AlbumHandler.CreateAlbum: Failed to decode JSON request", err)
+		return
+	}
+	draft, err := models.NewAlbum(utils.NewID(), req.Title, req.EventID, req.Classroom, actorID, time.Now())
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AlbumHandler.CreateAlbum: Invalid album", err)
+		return
+	}
+
+	album, err := h.albumService.CreateAlbum(actorID, *draft)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, "AlbumHandler.CreateAlbum: Failed to create album")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(album)
+}
+
+// GetAlbums handles GET requests for the albums the user has photos to see in
+func (h *AlbumHandler) GetAlbums(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AlbumHandler.GetAlbums: Failed to get user ID from auth", err)
+		return
+	}
+
+	albums, err := h.albumService.GetAlbums(actorID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "AlbumHandler.GetAlbums: Failed to retrieve albums")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(albums)
+}
+
+// GetPhotos handles GET requests for the photos in an album the user can see
+func (h *AlbumHandler) GetPhotos(w http.ResponseWriter, r *http.Request) {
+	albumID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AlbumHandler.GetPhotos: Failed to get user ID from auth", err)
+		return
+	}
+
+	photos, err := h.albumService.GetPhotos(actorID, albumID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("AlbumHandler.GetPhotos: Failed to retrieve photos for album %s", albumID))
+		return
+	}
+
+	response := []map[string]interface{}{}
+	for _, photo := range photos {
+		response = append(response, buildAlbumPhotoResponse(photo))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetPhotoImage handles GET requests downloading a photo
+func (h *AlbumHandler) GetPhotoImage(w http.ResponseWriter, r *http.Request) {
+	albumID := r.PathValue("id")
+	photoID := r.PathValue("photoId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AlbumHandler.GetPhotoImage: Failed to get user ID from auth", err)
+		return
+	}
+
+	stream, err := h.albumService.GetPhotoImage(r.Context(), actorID, albumID, photoID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("AlbumHandler.GetPhotoImage: Failed to get photo %s", photoID))
+		return
+	}
+	serveImageStream(w, r, photoID, stream)
+}
+
+// AddPhoto handles multipart POST requests uploading a photo, with form fields childIds (repeated or comma separated),
+// visibility and caption
+func (h *AlbumHandler) AddPhoto(w http.ResponseWriter, r *http.Request) {
+	albumID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AlbumHandler.AddPhoto: Failed to get user ID from auth", err)
+		return
+	}
+	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AlbumHandler.AddPhoto: Unable to parse form", err)
+		return
+	}
+
+	visibility, err := models.ParsePhotoVisibility(r.FormValue("visibility"))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AlbumHandler.AddPhoto: Invalid visibility", err)
+		return
+	}
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AlbumHandler.AddPhoto: Missing image file", err)
+		return
+	}
+	defer file.Close()
+	// The format comes from the file's own bytes, a client claiming image/svg+xml or text/html is not trusted
+	contentType, _, err := imaging.Validate(file, MaxImagePixels)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) {
+		utils.WriteJSONError(w, http.StatusUnsupportedMediaType, "AlbumHandler.AddPhoto: Only JPEG, PNG and GIF images are accepted", err)
+		return
+	}
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AlbumHandler.AddPhoto: Invalid image", err)
+		return
+	}
+	buffer := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buffer, file); err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "AlbumHandler.AddPhoto: Failed to read image", err)
+		return
+	}
+
+	draft := models.NewAlbumPhoto(utils.NewID(), albumID, contentType, r.FormValue("caption"), formList(r, "childIds"), visibility, actorID, time.Now())
+	photo, err := h.albumService.AddPhoto(r.Context(), actorID, *draft, buffer.Bytes())
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("AlbumHandler.AddPhoto: Failed to add photo to album %s", albumID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(buildAlbumPhotoResponse(photo))
+}
+
+// TagPhoto handles PUT requests replacing the children tagged in a photo and its visibility
+func (h *AlbumHandler) TagPhoto(w http.ResponseWriter, r *http.Request) {
+	albumID := r.PathValue("id")
+	photoID := r.PathValue("photoId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AlbumHandler.TagPhoto: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		ChildIDs   []string `json:"childIds"`
+		Visibility string   `json:"visibility"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AlbumHandler.TagPhoto: Failed to decode JSON request", err)
+		return
+	}
+	visibility, err := models.ParsePhotoVisibility(req.Visibility)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AlbumHandler.TagPhoto: Invalid visibility", err)
+		return
+	}
+
+	photo, err := h.albumService.TagPhoto(actorID, albumID, photoID, req.ChildIDs, visibility)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("AlbumHandler.TagPhoto: Failed to tag photo %s", photoID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildAlbumPhotoResponse(photo))
+}
+
+// DeletePhoto handles DELETE requests removing a photo from an album
+func (h *AlbumHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	albumID := r.PathValue("id")
+	photoID := r.PathValue("photoId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AlbumHandler.DeletePhoto: Failed to get user ID from auth", err)
+		return
+	}
+
+	if err := h.albumService.DeletePhoto(r.Context(), actorID, albumID, photoID); err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("AlbumHandler.DeletePhoto: Failed to delete photo %s", photoID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPhotoReviews handles GET requests for the photos whose tagged children have not consented to how they are shared
+func (h *AlbumHandler) GetPhotoReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := h.albumService.GetPhotoReviews()
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "AlbumHandler.GetPhotoReviews: Failed to build photo review report", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reviews)
+}
+
+// Helper function to package JSON response, adding the URL the photo is served from
+func buildAlbumPhotoResponse(photo models.AlbumPhoto) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          photo.ID,
+		"albumId":     photo.AlbumID,
+		"contentType": photo.ContentType,
+		"caption":     photo.Caption,
+		"childIds":    photo.ChildIDs,
+		"visibility":  photo.Visibility,
+		"uploadedBy":  photo.UploadedBy,
+		"uploadedAt":  photo.UploadedAt,
+		"url":         fmt.Sprintf("/api/albums/%s/photos/%s/image", photo.AlbumID, photo.ID),
+	}
+}
+
+// Helper - collect a form field sent either repeated or as a comma separated list
+func formList(r *http.Request, key string) []string {
+	values := []string{}
+	for _, value := range r.MultipartForm.Value[key] {
+		values = append(values, strings.Split(value, ",")...)
+	}
+	return values
+}
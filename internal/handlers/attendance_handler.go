@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+)
+
+// AttendanceService interface implemented in services package
+type AttendanceService interface {
+	CheckIn(actorID string, childID string, droppedOffBy string) (models.AttendanceRecord, error)
+	CheckOut(actorID string, childID string, pickedUpBy string, pickupID string, overrideReason string) (models.AttendanceRecord, error)
+	GetDayRoster(actorID string, classroomID string, date string) (models.AttendanceRoster, error)
+}
+
+// AttendanceHandler handles HTTP requests for drop-off, pickup and daily rosters
+type AttendanceHandler struct {
+	attendanceService AttendanceService
+}
+
+// NewAttendanceHandler creates a new attendance handler
+func NewAttendanceHandler(s AttendanceService) *AttendanceHandler {
+	return &AttendanceHandler{
+		attendanceService: s,
+	}
+}
+
+// attendanceRequest is the JSON body accepted by check-in and checkout
+type attendanceRequest struct {
+	ChildID      string `json:"childId"`
+	DroppedOffBy string `json:"droppedOffBy"`
+	PickedUpBy   string `json:"pickedUpBy"`
+	// Guardian user ID or authorized pickup ID chosen from the pickup list lookup
+	PickupID string `json:"pickupId"`
+	// Admin only, releases the child to an adult missing from the pickup list
+	OverrideReason string `json:"overrideReason"`
+}
+
+// CheckIn handles POST requests recording a child's drop-off
+func (h *AttendanceHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AttendanceHandler.CheckIn: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req attendanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChildID == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AttendanceHandler.CheckIn: Missing or invalid childId", err)
+		return
+	}
+
+	record, err := h.attendanceService.CheckIn(actorID, req.ChildID, req.DroppedOffBy)
+	if err != nil {
+		writeAttendanceError(w, err, fmt.Sprintf("AttendanceHandler.CheckIn: Failed to check in Child with ID %s", req.ChildID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(record)
+}
+
+// CheckOut handles POST requests recording a child's pickup
+func (h *AttendanceHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AttendanceHandler.CheckOut: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req attendanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChildID == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "AttendanceHandler.CheckOut: Missing or invalid childId", err)
+		return
+	}
+
+	record, err := h.attendanceService.CheckOut(actorID, req.ChildID, req.PickedUpBy, req.PickupID, req.OverrideReason)
+	if err != nil {
+		writeAttendanceError(w, err, fmt.Sprintf("AttendanceHandler.CheckOut: Failed to check out Child with ID %s", req.ChildID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(record)
+}
+
+// GetDayRoster handles GET requests for who is present in a classroom, defaulting to today
+func (h *AttendanceHandler) GetDayRoster(w http.ResponseWriter, r *http.Request) {
+	classroomID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "AttendanceHandler.GetDayRoster: Failed to get user ID from auth", err)
+		return
+	}
+
+	roster, err := h.attendanceService.GetDayRoster(actorID, classroomID, r.URL.Query().Get("date"))
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("AttendanceHandler.GetDayRoster: Failed to get attendance for Classroom with ID %s", classroomID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(roster)
+}
+
+// Helper - map attendance state errors to 409, rejected pickups to 403 and other failures through writeServiceError
+func writeAttendanceError(w http.ResponseWriter, err error, msg string) {
+	if errors.Is(err, services.ErrUnauthorizedPickup) {
+		utils.WriteJSONError(w, http.StatusForbidden, msg, err)
+		return
+	}
+	if errors.Is(err, services.ErrAlreadyCheckedIn) || errors.Is(err, services.ErrNotCheckedIn) {
+		utils.WriteJSONError(w, http.StatusConflict, msg, err)
+		return
+	}
+	writeServiceError(w, err, http.StatusBadRequest, msg)
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+)
+
+// BillingService interface implemented in services package
+type BillingService interface {
+	GetPlan(actorID string, childID string) (models.TuitionPlan, error)
+	SetPlan(actorID string, plan models.TuitionPlan) (models.TuitionPlan, error)
+	GenerateInvoices(actorID string, month string) ([]models.Invoice, error)
+	GetInvoices(actorID string, status string) ([]models.Invoice, error)
+	GetInvoice(actorID string, id string) (models.Invoice, error)
+	RenderInvoice(actorID string, id string) (string, error)
+	PayInvoice(ctx context.Context, actorID string, id string, paymentToken string) (models.Invoice, error)
+	VoidInvoice(actorID string, id string) (models.Invoice, error)
+}
+
+// BillingHandler handles HTTP requests for tuition plans and invoices
+type BillingHandler struct {
+	billingService BillingService
+}
+
+// NewBillingHandler creates a new billing handler
+func NewBillingHandler(s BillingService) *BillingHandler {
+	return &BillingHandler{
+		billingService: s,
+	}
+}
+
+// tuitionPlanRequest is the JSON body accepted when setting a child's tuition plan, amounts are in cents
+type tuitionPlanRequest struct {
+	FamilyID               string `json:"familyId"`
+	Frequency              string `json:"frequency"`
+	RateCents              int64  `json:"rateCents"`
+	SiblingDiscountPercent int    `json:"siblingDiscountPercent"`
+	StartDate              string `json:"startDate"`
+	EndDate                string `json:"endDate"`
+}
+
+// GetPlan handles GET requests for a child's tuition plan
+func (h *BillingHandler) GetPlan(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "BillingHandler.GetPlan: Failed to get user ID from auth", err)
+		return
+	}
+
+	plan, err := h.billingService.GetPlan(actorID, childID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("BillingHandler.GetPlan: Failed to find tuition plan for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// SetPlan handles PUT requests creating or replacing a child's tuition plan
+func (h *BillingHandler) SetPlan(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "BillingHandler.SetPlan: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req tuitionPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "BillingHandler.SetPlan: Failed to decode JSON request", err)
+		return
+	}
+	frequency, err := models.ParseBillingFrequency(req.Frequency)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("BillingHandler.SetPlan: %v", err), err)
+		return
+	}
+	plan, err := models.NewTuitionPlan(childID, req.FamilyID, frequency, req.RateCents, req.SiblingDiscountPercent, req.StartDate, req.EndDate)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("BillingHandler.SetPlan: %v", err), err)
+		return
+	}
+
+	saved, err := h.billingService.SetPlan(actorID, *plan)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("BillingHandler.SetPlan: Failed to set tuition plan for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// GenerateInvoices handles POST requests issuing a month's invoices to every family
+func (h *BillingHandler) GenerateInvoices(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "BillingHandler.GenerateInvoices: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		Month string `json:"month"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Month == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "BillingHandler.GenerateInvoices: Missing month (YYYY-MM)", err)
+		return
+	}
+
+	invoices, err := h.billingService.GenerateInvoices(actorID, req.Month)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("BillingHandler.GenerateInvoices: Failed to generate invoices for %s", req.Month))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invoices)
+}
+
+// GetInvoices handles GET requests for invoices, ?status= filters by open, paid or void
+func (h *BillingHandler) GetInvoices(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "BillingHandler.GetInvoices: Failed to get user ID from auth", err)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch models.InvoiceStatus(status) {
+	case "", models.InvoiceOpen, models.InvoicePaid, models.InvoiceVoid:
+	default:
+		utils.WriteJSONError(w, http.StatusBadRequest, "BillingHandler.GetInvoices: status must be open, paid or void", nil)
+		return
+	}
+
+	invoices, err := h.billingService.GetInvoices(actorID, status)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "BillingHandler.GetInvoices: Failed to retrieve invoices")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invoices)
+}
+
+// GetInvoice handles GET requests for a single invoice
+func (h *BillingHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "BillingHandler.GetInvoice: Failed to get user ID from auth", err)
+		return
+	}
+
+	invoice, err := h.billingService.GetInvoice(actorID, id)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("BillingHandler.GetInvoice: Failed to find invoice with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invoice)
+}
+
+// GetInvoiceHTML handles GET requests for an invoice rendered as a printable HTML page
+func (h *BillingHandler) GetInvoiceHTML(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "BillingHandler.GetInvoiceHTML: Failed to get user ID from auth", err)
+		return
+	}
+
+	page, err := h.billingService.RenderInvoice(actorID, id)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("BillingHandler.GetInvoiceHTML: Failed to render invoice with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(page))
+}
+
+// PayInvoice handles POST requests from a family paying an open invoice
+func (h *BillingHandler) PayInvoice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "BillingHandler.PayInvoice: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		PaymentToken string `json:"paymentToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PaymentToken == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "BillingHandler.PayInvoice: Missing paymentToken", err)
+		return
+	}
+
+	invoice, err := h.billingService.PayInvoice(r.Context(), actorID, id, req.PaymentToken)
+	if err != nil {
+		writeBillingError(w, err, http.StatusNotFound, fmt.Sprintf("BillingHandler.PayInvoice: Failed to pay invoice with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invoice)
+}
+
+// VoidInvoice handles POST requests cancelling an open invoice
+func (h *BillingHandler) VoidInvoice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "BillingHandler.VoidInvoice: Failed to get user ID from auth", err)
+		return
+	}
+
+	invoice, err := h.billingService.VoidInvoice(actorID, id)
+	if err != nil {
+		writeBillingError(w, err, http.StatusNotFound, fmt.Sprintf("BillingHandler.VoidInvoice: Failed to void invoice with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invoice)
+}
+
+// Helper - map invoice state errors to 409, declined payments to 402 and everything else through writeServiceError
+func writeBillingError(w http.ResponseWriter, err error, status int, msg string) {
+	switch {
+	case errors.Is(err, services.ErrInvoiceState):
+		utils.WriteJSONError(w, http.StatusConflict, msg, err)
+	case errors.Is(err, services.ErrPaymentDeclined):
+		utils.WriteJSONError(w, http.StatusPaymentRequired, msg, err)
+	default:
+		writeServiceError(w, err, status, msg)
+	}
+}
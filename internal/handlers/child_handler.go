@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+)
+
+// ChildService interface implemented in services package
+type ChildService interface {
+	GetChildByID(actorID string, id string) (models.Child, error)
+	GetChildren(actorID string) ([]models.Child, error)
+	GetChildrenByGuardian(userID string) ([]models.Child, error)
+	GetChildrenByGuardians() (map[string][]models.Child, error)
+	VisibleLinkedChildren(actorID string, byGuardian map[string][]models.Child) (map[string][]models.Child, error)
+	CreateChild(actorID string, child models.Child) (models.Child, error)
+	UpdateChild(actorID string, child models.Child) (models.Child, error)
+	DeleteChild(actorID string, id string) error
+	AddGuardian(actorID string, link models.GuardianLink) (models.Child, error)
+	RemoveGuardian(actorID string, childID string, userID string) error
+	LinkInvitedGuardian(userID string, childIDs []string) error
+}
+
+// ChildHandler handles HTTP requests related to children and their guardians
+type ChildHandler struct {
+	childService ChildService
+}
+
+// NewChildHandler creates a new child handler
+func NewChildHandler(s ChildService) *ChildHandler {
+	return &ChildHandler{
+		childService: s,
+	}
+}
+
+// childRequest is the JSON body accepted when creating or updating a child
+type childRequest struct {
+	Name         string           `json:"name"`
+	Birthdate    string           `json:"birthdate"`
+	Classroom    string           `json:"classroom"`
+	Allergies    []models.Allergy `json:"allergies"`
+	Notes        string           `json:"notes"`
+	MediaConsent string           `json:"mediaConsent"`
+	Guardians    []struct {
+		UserID       string `json:"userId"`
+		Relationship string `json:"relationship"`
+	} `json:"guardians"`
+}
+
+// GetChild handles GET requests for a specific child
+func (h *ChildHandler) GetChild(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ChildHandler.GetChild: Failed to get user ID from auth", err)
+		return
+	}
+
+	child, err := h.childService.GetChildByID(actorID, id)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("ChildHandler.GetChild: Failed to find Child with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildChildResponse(child))
+}
+
+// GetChildren handles GET requests for every child visible to the caller
+func (h *ChildHandler) GetChildren(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ChildHandler.GetChildren: Failed to get user ID from auth", err)
+		return
+	}
+
+	children, err := h.childService.GetChildren(actorID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "ChildHandler.GetChildren: Failed to retrieve list of children", err)
+		return
+	}
+
+	responses := []map[string]interface{}{}
+	for _, child := range children {
+		responses = append(responses, buildChildResponse(child))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responses)
+}
+
+// CreateChild handles POST requests to create a new child with its guardians
+func (h *ChildHandler) CreateChild(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ChildHandler.CreateChild: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req childRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ChildHandler.CreateChild: Failed to decode JSON request", err)
+		return
+	}
+
+	child, err := models.NewChild(utils.NewID(), req.Name, req.Birthdate, req.Classroom, req.Allergies, req.Notes)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("ChildHandler.CreateChild: %v", err), err)
+		return
+	}
+	if req.MediaConsent != "" {
+		if child.MediaConsent, err = models.ParseMediaConsent(req.MediaConsent); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("ChildHandler.CreateChild: %v", err), err)
+			return
+		}
+	}
+	for _, g := range req.Guardians {
+		relationship, err := models.ParseRelationship(g.Relationship)
+		if err != nil || g.UserID == "" {
+			utils.WriteJSONError(w, http.StatusBadRequest, "ChildHandler.CreateChild: Each guardian needs a userId and a valid relationship", err)
+			return
+		}
+		child.Guardians = append(child.Guardians, models.GuardianLink{ChildID: child.ID, UserID: g.UserID, Relationship: relationship})
+	}
+
+	created, err := h.childService.CreateChild(actorID, *child)
+	if err != nil {
+		writeServiceError(w, err, http.StatusConflict, "ChildHandler.CreateChild: Failed to create Child")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(buildChildResponse(created))
+}
+
+// UpdateChild handles PUT requests with partial updates to a child
+func (h *ChildHandler) UpdateChild(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ChildHandler.UpdateChild: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req childRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ChildHandler.UpdateChild: Failed to decode JSON request", err)
+		return
+	}
+
+	child := models.Child{
+		ID:        id,
+		Name:      req.Name,
+		Birthdate: req.Birthdate,
+		Classroom: req.Classroom,
+		Allergies: req.Allergies,
+		Notes:     req.Notes,
+	}
+	if req.MediaConsent != "" {
+		if child.MediaConsent, err = models.ParseMediaConsent(req.MediaConsent); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("ChildHandler.UpdateChild: %v", err), err)
+			return
+		}
+	}
+	updated, err := h.childService.UpdateChild(actorID, child)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("ChildHandler.UpdateChild: Failed to update Child with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildChildResponse(updated))
+}
+
+// DeleteChild handles DELETE requests to remove a child and its guardian links
+func (h *ChildHandler) DeleteChild(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ChildHandler.DeleteChild: Failed to get user ID from auth", err)
+		return
+	}
+
+	if err := h.childService.DeleteChild(actorID, id); err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("ChildHandler.DeleteChild: Failed to delete Child with ID %s", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddGuardian handles POST requests linking a user to a child as a guardian
+func (h *ChildHandler) AddGuardian(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ChildHandler.AddGuardian: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		UserID       string `json:"userId"`
+		Relationship string `json:"relationship"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ChildHandler.AddGuardian: Missing or invalid userId", err)
+		return
+	}
+	relationship, err := models.ParseRelationship(req.Relationship)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("ChildHandler.AddGuardian: %v", err), err)
+		return
+	}
+
+	child, err := h.childService.AddGuardian(actorID, models.GuardianLink{ChildID: childID, UserID: req.UserID, Relationship: relationship})
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("ChildHandler.AddGuardian: Failed to link guardian to Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildChildResponse(child))
+}
+
+// RemoveGuardian handles DELETE requests unlinking a guardian from a child
+func (h *ChildHandler) RemoveGuardian(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	userID := r.PathValue("userId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ChildHandler.RemoveGuardian: Failed to get user ID from auth", err)
+		return
+	}
+
+	if err := h.childService.RemoveGuardian(actorID, childID, userID); err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("ChildHandler.RemoveGuardian: Failed to unlink guardian %s from Child with ID %s", userID, childID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Helper - map forbidden service errors to 403 and everything else to the given status
+func writeServiceError(w http.ResponseWriter, err error, status int, msg string) {
+	if errors.Is(err, services.ErrForbidden) {
+		utils.WriteJSONError(w, http.StatusForbidden, msg, err)
+		return
+	}
+	utils.WriteJSONError(w, status, msg, err)
+}
+
+// Helper function to package JSON response
+func buildChildResponse(child models.Child) map[string]interface{} {
+	guardians := child.Guardians
+	if guardians == nil {
+		guardians = []models.GuardianLink{}
+	}
+	allergies := child.Allergies
+	if allergies == nil {
+		allergies = []models.Allergy{}
+	}
+	return map[string]interface{}{
+		"id":            child.ID,
+		"name":          child.Name,
+		"birthdate":     child.Birthdate,
+		"classroom":     child.Classroom,
+		"allergies":     allergies,
+		"severeAllergy": child.HasSevereAllergy(),
+		"notes":         child.Notes,
+		"mediaConsent":  child.MediaConsent,
+		"guardians":     guardians,
+
+		"mediaConsentBy": child.MediaConsentBy,
+		"mediaConsentAt": child.MediaConsentAt,
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+)
+
+// ClassroomService interface implemented in services package
+type ClassroomService interface {
+	GetClassroomByID(id string) (models.Classroom, error)
+	GetAllClassrooms() ([]models.Classroom, error)
+	CreateClassroom(actorID string, classroom models.Classroom) (models.Classroom, error)
+	UpdateClassroom(actorID string, classroom models.Classroom) (models.Classroom, error)
+	DeleteClassroom(actorID string, id string) error
+	GetRoster(actorID string, id string) (models.ClassroomRoster, error)
+	AssignTeacher(actorID string, classroomID string, teacherID string) error
+	UnassignTeacher(actorID string, classroomID string, teacherID string) error
+}
+
+// ClassroomHandler handles HTTP requests related to classrooms and their rosters
+type ClassroomHandler struct {
+	classroomService ClassroomService
+}
+
+// NewClassroomHandler creates a new classroom handler
+func NewClassroomHandler(s ClassroomService) *ClassroomHandler {
+	return &ClassroomHandler{
+		classroomService: s,
+	}
+}
+
+// classroomRequest is the JSON body accepted when creating or updating a classroom
+type classroomRequest struct {
+	Name       string             `json:"name"`
+	AgeGroup   string             `json:"ageGroup"`
+	Capacity   int                `json:"capacity"`
+	RatioRules []models.RatioRule `json:"ratioRules"`
+}
+
+// GetClassroom handles GET requests for a specific classroom
+func (h *ClassroomHandler) GetClassroom(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	classroom, err := h.classroomService.GetClassroomByID(id)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("ClassroomHandler.GetClassroom: Failed to find Classroom with ID %s", id), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildClassroomResponse(classroom))
+}
+
+// GetAllClassrooms handles GET requests for every classroom
+func (h *ClassroomHandler) GetAllClassrooms(w http.ResponseWriter, r *http.Request) {
+	classrooms, err := h.classroomService.GetAllClassrooms()
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "ClassroomHandler.GetAllClassrooms: Failed to retrieve list of classrooms", err)
+		return
+	}
+
+	responses := []map[string]interface{}{}
+	for _, classroom := range classrooms {
+		responses = append(responses, buildClassroomResponse(classroom))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responses)
+}
+
+// CreateClassroom handles POST requests to create a new classroom
+func (h *ClassroomHandler) CreateClassroom(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ClassroomHandler.CreateClassroom: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req classroomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ClassroomHandler.CreateClassroom: Failed to decode JSON request", err)
+		return
+	}
+	classroom, err := models.NewClassroom(utils.NewID(), req.Name, req.AgeGroup, req.Capacity)
+	if err == nil && req.RatioRules != nil {
+		err = models.ValidateRatioRules(req.RatioRules)
+		classroom.RatioRules = req.RatioRules
+	}
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("ClassroomHandler.CreateClassroom: %v", err), err)
+		return
+	}
+
+	created, err := h.classroomService.CreateClassroom(actorID, *classroom)
+	if err != nil {
+		writeServiceError(w, err, http.StatusConflict, "ClassroomHandler.CreateClassroom: Failed to create Classroom")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(buildClassroomResponse(created))
+}
+
+// UpdateClassroom handles PUT requests with partial updates to a classroom
+func (h *ClassroomHandler) UpdateClassroom(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ClassroomHandler.UpdateClassroom: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req classroomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ClassroomHandler.UpdateClassroom: Failed to decode JSON request", err)
+		return
+	}
+
+	classroom := models.Classroom{ID: id, Name: req.Name, AgeGroup: req.AgeGroup, Capacity: req.Capacity, RatioRules: req.RatioRules}
+	updated, err := h.classroomService.UpdateClassroom(actorID, classroom)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("ClassroomHandler.UpdateClassroom: Failed to update Classroom with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildClassroomResponse(updated))
+}
+
+// DeleteClassroom handles DELETE requests to remove an empty classroom
+func (h *ClassroomHandler) DeleteClassroom(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ClassroomHandler.DeleteClassroom: Failed to get user ID from auth", err)
+		return
+	}
+
+	if err := h.classroomService.DeleteClassroom(actorID, id); err != nil {
+		writeServiceError(w, err, http.StatusConflict, fmt.Sprintf("ClassroomHandler.DeleteClassroom: Failed to delete Classroom with ID %s", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRoster handles GET requests for a classroom's teachers and enrolled children
+func (h *ClassroomHandler) GetRoster(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ClassroomHandler.GetRoster: Failed to get user ID from auth", err)
+		return
+	}
+
+	roster, err := h.classroomService.GetRoster(actorID, id)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("ClassroomHandler.GetRoster: Failed to get roster for Classroom with ID %s", id))
+		return
+	}
+
+	teachers := []map[string]interface{}{}
+	for _, teacher := range roster.Teachers {
+		teachers = append(teachers, map[string]interface{}{
+			"id":    teacher.ID,
+			"name":  teacher.Name,
+			"email": teacher.Email,
+		})
+	}
+	children := []map[string]interface{}{}
+	for _, child := range roster.Children {
+		children = append(children, buildChildResponse(child))
+	}
+	response := buildClassroomResponse(roster.Classroom)
+	response["teachers"] = teachers
+	response["children"] = children
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// AssignTeacher handles POST requests assigning a teacher to a classroom
+func (h *ClassroomHandler) AssignTeacher(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ClassroomHandler.AssignTeacher: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ClassroomHandler.AssignTeacher: Missing or invalid userId", err)
+		return
+	}
+
+	if err := h.classroomService.AssignTeacher(actorID, id, req.UserID); err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("ClassroomHandler.AssignTeacher: Failed to assign %s to Classroom with ID %s", req.UserID, id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnassignTeacher handles DELETE requests removing a teacher from a classroom
+func (h *ClassroomHandler) UnassignTeacher(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	userID := r.PathValue("userId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ClassroomHandler.UnassignTeacher: Failed to get user ID from auth", err)
+		return
+	}
+
+	if err := h.classroomService.UnassignTeacher(actorID, id, userID); err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("ClassroomHandler.UnassignTeacher: Failed to remove %s from Classroom with ID %s", userID, id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Helper function to package JSON response
+func buildClassroomResponse(classroom models.Classroom) map[string]interface{} {
+	ratioRules := classroom.RatioRules
+	if len(ratioRules) == 0 {
+		ratioRules = models.DefaultRatioRules
+	}
+	return map[string]interface{}{
+		"id":         classroom.ID,
+		"name":       classroom.Name,
+		"ageGroup":   classroom.AgeGroup,
+		"capacity":   classroom.Capacity,
+		"ratioRules": ratioRules,
+	}
+}
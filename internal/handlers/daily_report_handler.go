@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+	"time"
+)
+
+// DailyReportService interface implemented in services package
+type DailyReportService interface {
+	AddEntry(ctx context.Context, actorID string, entry models.DailyEntry) (models.DailyEntry, error)
+	DeleteEntry(actorID string, childID string, date string, entryID string) error
+	GetDailyReport(actorID string, childID string, date string) (models.DailyReport, error)
+	GetEntryPhoto(ctx context.Context, actorID string, childID string, date string, entryID string) (*models.ImageStream, error)
+}
+
+// DailyReportHandler handles HTTP requests for teachers' daily logs and parents' daily reports
+type DailyReportHandler struct {
+	dailyReportService DailyReportService
+}
+
+// NewDailyReportHandler creates a new daily report handler
+func NewDailyReportHandler(s DailyReportService) *DailyReportHandler {
+	return &DailyReportHandler{
+		dailyReportService: s,
+	}
+}
+
+// dailyEntryRequest is the JSON body accepted when logging an entry, times use RFC3339
+type dailyEntryRequest struct {
+	Type       string    `json:"type"`
+	StartAt    time.Time `json:"startAt"`
+	EndAt      time.Time `json:"endAt"`
+	Meal       string    `json:"meal"`
+	Amount     string    `json:"amount"`
+	Kind       string    `json:"kind"`
+	Mood       string    `json:"mood"`
+	Notes      string    `json:"notes"`
+	ImageOwner string    `json:"imageOwner"`
+	ImageName  string    `json:"imageName"`
+}
+
+// AddEntry handles POST requests logging an entry in a child's day
+func (h *DailyReportHandler) AddEntry(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "DailyReportHandler.AddEntry: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req dailyEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "DailyReportHandler.AddEntry: Failed to decode JSON request", err)
+		return
+	}
+	entryType, err := models.ParseDailyEntryType(req.Type)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("DailyReportHandler.AddEntry: %v", err), err)
+		return
+	}
+	if req.StartAt.IsZero() {
+		req.StartAt = time.Now()
+	}
+
+	entry := models.DailyEntry{
+		ID:         utils.NewID(),
+		ChildID:    childID,
+		Type:       entryType,
+		StartAt:    req.StartAt,
+		EndAt:      req.EndAt,
+		Meal:       req.Meal,
+		Amount:     req.Amount,
+		Kind:       req.Kind,
+		Mood:       req.Mood,
+		Notes:      req.Notes,
+		ImageOwner: req.ImageOwner,
+		ImageName:  req.ImageName,
+	}
+	created, err := h.dailyReportService.AddEntry(r.Context(), actorID, entry)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("DailyReportHandler.AddEntry: Failed to log entry for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// DeleteEntry handles DELETE requests removing a mistaken entry
+func (h *DailyReportHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	date := r.PathValue("date")
+	entryID := r.PathValue("entryId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "DailyReportHandler.DeleteEntry: Failed to get user ID from auth", err)
+		return
+	}
+
+	if err := h.dailyReportService.DeleteEntry(actorID, childID, date, entryID); err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("DailyReportHandler.DeleteEntry: Failed to delete entry %s", entryID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDailyReport handles GET requests for a child's report, ?date=YYYY-MM-DD defaults to today
+func (h *DailyReportHandler) GetDailyReport(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "DailyReportHandler.GetDailyReport: Failed to get user ID from auth", err)
+		return
+	}
+
+	report, err := h.dailyReportService.GetDailyReport(actorID, childID, r.URL.Query().Get("date"))
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("DailyReportHandler.GetDailyReport: Failed to get daily report for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildDailyReportResponse(report))
+}
+
+// GetEntryPhoto handles GET requests for the image behind a photo entry
+func (h *DailyReportHandler) GetEntryPhoto(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	date := r.PathValue("date")
+	entryID := r.PathValue("entryId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "DailyReportHandler.GetEntryPhoto: Failed to get user ID from auth", err)
+		return
+	}
+
+	stream, err := h.dailyReportService.GetEntryPhoto(r.Context(), actorID, childID, date, entryID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("DailyReportHandler.GetEntryPhoto: Failed to get photo for entry %s", entryID))
+		return
+	}
+	serveImageStream(w, r, entryID, stream)
+}
+
+// Helper function to package JSON response, adding the URL photos are served from
+func buildDailyReportResponse(report models.DailyReport) map[string]interface{} {
+	photos := []map[string]interface{}{}
+	for _, photo := range report.Photos {
+		photos = append(photos, map[string]interface{}{
+			"id":       photo.ID,
+			"startAt":  photo.StartAt,
+			"notes":    photo.Notes,
+			"staffId":  photo.StaffID,
+			"photoUrl": fmt.Sprintf("/api/children/%s/daily-entries/%s/%s/photo", report.ChildID, photo.Date, photo.ID),
+		})
+	}
+	return map[string]interface{}{
+		"childId":         report.ChildID,
+		"childName":       report.ChildName,
+		"date":            report.Date,
+		"meals":           report.Meals,
+		"naps":            report.Naps,
+		"toileting":       report.Toileting,
+		"moods":           report.Moods,
+		"activities":      report.Activities,
+		"photos":          photos,
+		"totalNapMinutes": report.TotalNapMinutes,
+	}
+}
@@ -1,26 +1,29 @@
 package handlers
 
 import (
-	"strings"
 	"encoding/json"
 	"net/http"
-	"context"
 	"littleeinsteinchildcare/backend/internal/models"
 	"littleeinsteinchildcare/backend/internal/services"
-	"littleeinsteinchildcare/backend/firebase"
-	"cloud.google.com/go/firestore"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
+// InviteService interface implemented in services package
+type InviteService interface {
+	CreateInvite(email string, role models.Role) (models.Invite, error)
+	GetInvite(email string) (models.Invite, error)
+	IsInvited(email string) (bool, error)
+	ClaimInvite(email string) (models.Invite, error)
+	ReleaseInvite(claimed models.Invite) error
+}
+
 type EmailHandler struct {
-	EmailService services.EmailService 
-	FirestoreClient *firestore.Client
+	EmailService  services.EmailService
+	InviteService InviteService
 }
 
-func NewEmailHandler(emailService services.EmailService, fsClient *firestore.Client) *EmailHandler {
+func NewEmailHandler(emailService services.EmailService, inviteService InviteService) *EmailHandler {
 	return &EmailHandler{EmailService: emailService,
-		FirestoreClient: fsClient,
+		InviteService: inviteService,
 	}
 }
 
@@ -36,22 +39,9 @@ func (h *EmailHandler) SendInvite(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to send invite", http.StatusInternalServerError)
 		return
 	}
-	
-	ctx := context.Background()
-	fsClient, err := firebase.Firestore(ctx)
-	if err != nil {
-		http.Error(w, "Failed to connect to Firestore", http.StatusInternalServerError)
-		return
-	}
-	defer fsClient.Close()
-
-	_, err = h.FirestoreClient.Collection("invitedUsers").Doc(req.Email).Set(ctx, map[string]interface{}{
-		"invited": true,
-		"signedUp": false,
-		"role": "Parent",
-	})
 
-	if err != nil {
+	// No role is given so re-inviting a staff member keeps the role on their pending invite
+	if _, err := h.InviteService.CreateInvite(req.Email, ""); err != nil {
 		http.Error(w, "Failed to record invite", http.StatusInternalServerError)
 		return
 	}
@@ -61,14 +51,13 @@ func (h *EmailHandler) SendInvite(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *EmailHandler) CheckIfInvited(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
 	email := r.URL.Query().Get("email")
 	if email == "" {
 		http.Error(w, "Missing email parameter", http.StatusBadRequest)
 		return
 	}
 
-	invited, err := h.IsEmailInvited(ctx, email)
+	invited, err := h.InviteService.IsInvited(email)
 	if err != nil {
 		http.Error(w, "Failed to check invitation", http.StatusInternalServerError)
 		return
@@ -79,17 +68,3 @@ func (h *EmailHandler) CheckIfInvited(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
-
-func (h *EmailHandler) IsEmailInvited(ctx context.Context, email string) (bool, error) {
-	docRef := h.FirestoreClient.Collection("invitedUsers").Doc(strings.ToLower(email))
-	doc, err := docRef.Get(ctx)
-
-	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			return false, nil 
-		}
-		return false, err 
-	}
-
-	return doc.Exists(), nil
-}
\ No newline at end of file
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
 	"littleeinsteinchildcare/backend/internal/utils"
 	"net/http"
 	"strings"
@@ -14,12 +15,12 @@ import (
 
 // EventService interface implemented in services package
 type EventService interface {
-	CreateEvent(user models.Event) error
+	CreateEvent(actorID string, event models.Event) error
 	GetEventByID(id string) (models.Event, error)
 	GetAllEvents() ([]models.Event, error)
 	GetEventsByUser(userId string) ([]models.Event, error)
-	DeleteEventByID(id string) error
-	UpdateEvent(newData models.Event) (models.Event, error)
+	DeleteEventByID(actorID string, id string) error
+	UpdateEvent(actorID string, newData models.Event) (models.Event, error)
 }
 
 // EventHandler handles HTTP requests related to users
@@ -120,7 +121,8 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	log.Printf("DEBUG: Successfully retrieved creator: %+v", creator)
-	inviteesStr := eventData["invitees"].(string)
+	// Invitees are optional when whole classrooms are invited
+	inviteesStr, _ := eventData["invitees"].(string)
 	log.Printf("DEBUG: Processing invitees string: '%s'", inviteesStr)
 	invitee_ids := strings.Split(inviteesStr, ",")
 	log.Printf("DEBUG: Split invitee IDs: %+v", invitee_ids)
@@ -157,6 +159,11 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		color = col
 	}
 
+	classroom := ""
+	if room, ok := eventData["classroom"].(string); ok {
+		classroom = room
+	}
+
 	event := models.Event{
 		ID:          eventData["id"].(string),
 		EventName:   eventData["eventname"].(string),
@@ -166,19 +173,33 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		Location:    location,
 		Description: description,
 		Color:       color,
+		Classroom:   classroom,
 		Creator:     creator,
 		Invitees:    invitees_list,
+
+		InvitedClassrooms: parseInvitedClassrooms(eventData),
 	}
 	
 	log.Printf("DEBUG: Created event object: ID=%s, Name=%s, Location=%s, Description=%s, Color=%s", 
 		event.ID, event.EventName, event.Location, event.Description, event.Color)
 
-	err = h.eventService.CreateEvent(event)
+	err = h.eventService.CreateEvent(creatorID, event)
+	if errors.Is(err, services.ErrForbidden) {
+		utils.WriteJSONError(w, http.StatusForbidden, "EventHandler.CreateEvent: Not allowed to create events for this classroom", err)
+		return
+	}
 	if err != nil {
 		utils.WriteJSONError(w, http.StatusConflict, "EventHandler.CreateEvent: Failed to create Event", err)
 		return
 	}
 
+	// Return the invitees resolved from any invited classrooms
+	if len(event.InvitedClassrooms) > 0 {
+		if expanded, err := h.eventService.GetEventByID(event.ID); err == nil {
+			event = expanded
+		}
+	}
+
 	response := buildEventResponse(event)
 	log.Printf("DEBUG: Event response being sent to frontend: %+v", response)
 	w.Header().Set("Content-Type", "application/json")
@@ -190,6 +211,11 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 
 	pathID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "EventHandler.UpdateEvent: Failed to get user ID from auth", err)
+		return
+	}
 	eventData, err := utils.DecodeJSONRequest(r)
 	if err != nil {
 		utils.WriteJSONError(w, http.StatusBadRequest, "EventHandler.UpdateEvent: Failed to Decode JSON", nil)
@@ -212,7 +238,11 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedEvent, err := h.eventService.UpdateEvent(event)
+	updatedEvent, err := h.eventService.UpdateEvent(actorID, event)
+	if errors.Is(err, services.ErrForbidden) {
+		utils.WriteJSONError(w, http.StatusForbidden, "EventHandler.UpdateEvent: Not allowed to update this event", err)
+		return
+	}
 	if err != nil {
 		utils.WriteJSONError(w, http.StatusNotFound, "EventHandler.UpdateEvent: Event does not exist", err)
 		return
@@ -226,8 +256,17 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 // Delete an Event by Event ID
 func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
-	err := h.eventService.DeleteEventByID(id)
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "EventHandler.DeleteEvent: Failed to get user ID from auth", err)
+		return
+	}
+	err = h.eventService.DeleteEventByID(actorID, id)
 
+	if errors.Is(err, services.ErrForbidden) {
+		utils.WriteJSONError(w, http.StatusForbidden, fmt.Sprintf("Not allowed to delete event %s", id), err)
+		return
+	}
 	if err != nil {
 		utils.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("Error deleting event %s", id), err)
 		return
@@ -262,6 +301,10 @@ func (h *EventHandler) BuildPartialEvent(w http.ResponseWriter, eventData map[st
 	if v, ok := eventData["color"].(string); ok {
 		event.Color = v
 	}
+	if v, ok := eventData["classroom"].(string); ok {
+		event.Classroom = v
+	}
+	event.InvitedClassrooms = parseInvitedClassrooms(eventData)
 
 	//! Questionable - Given time for a refactor, this could be cleaner with a better overall structure
 	// Grab IDs from Event Data and populate Event object with relevant User objects
@@ -314,12 +357,32 @@ func buildEventResponse(event models.Event) map[string]interface{} {
 		"location":    event.Location,
 		"description": event.Description,
 		"color":       event.Color,
+		"classroom":   event.Classroom,
 		"creator":     event.Creator,
 		"invitees":    event.Invitees,
+
+		"invitedClassrooms": event.InvitedClassrooms,
 	}
 	return response
 }
 
+// Helper function to read the comma separated classroom IDs invited as a whole.
+// Returns nil when the field is absent and an empty slice when it is present but blank, so updates can clear it
+func parseInvitedClassrooms(eventData map[string]any) []string {
+	classroomsStr, ok := eventData["invitedClassrooms"].(string)
+	if !ok {
+		return nil
+	}
+	classroomIDs := []string{}
+	for _, id := range strings.Split(classroomsStr, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			classroomIDs = append(classroomIDs, id)
+		}
+	}
+	return classroomIDs
+}
+
 func (h *EventHandler) TestConnection(w http.ResponseWriter, r *http.Request) {
 
 	uid, ok := r.Context().Value(common.ContextUID).(string)
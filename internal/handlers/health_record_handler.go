@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"littleeinsteinchildcare/backend/internal/imaging"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+// HealthRecordService interface implemented in services package
+type HealthRecordService interface {
+	AddRecord(ctx context.Context, actorID string, record models.HealthRecord, document []byte, documentType string) (models.HealthRecord, error)
+	GetRecords(actorID string, childID string) ([]models.HealthRecord, error)
+	GetDocument(ctx context.Context, actorID string, childID string, recordID string) ([]byte, string, error)
+	DeleteRecord(ctx context.Context, actorID string, childID string, recordID string) error
+	GetComplianceReport(actorID string) ([]models.ChildCompliance, error)
+}
+
+// HealthRecordHandler handles HTTP requests for children's immunization and physical exam records
+type HealthRecordHandler struct {
+	healthRecordService HealthRecordService
+}
+
+// NewHealthRecordHandler creates a new health record handler
+func NewHealthRecordHandler(s HealthRecordService) *HealthRecordHandler {
+	return &HealthRecordHandler{
+		healthRecordService: s,
+	}
+}
+
+// AddRecord handles multipart POST requests adding a health record with an optional "document" scan or PDF
+func (h *HealthRecordHandler) AddRecord(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "HealthRecordHandler.AddRecord: Failed to get user ID from auth", err)
+		return
+	}
+	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "HealthRecordHandler.AddRecord: Unable to parse form", err)
+		return
+	}
+
+	recordType, err := models.ParseHealthRecordType(r.FormValue("type"))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("HealthRecordHandler.AddRecord: %v", err), err)
+		return
+	}
+	dose := 0
+	if doseValue := r.FormValue("dose"); doseValue != "" {
+		if dose, err = strconv.Atoi(doseValue); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "HealthRecordHandler.AddRecord: dose must be a number", err)
+			return
+		}
+	}
+	record, err := models.NewHealthRecord(utils.NewID(), childID, recordType, r.FormValue("vaccine"), dose, r.FormValue("date"), r.FormValue("expiresOn"), actorID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("HealthRecordHandler.AddRecord: %v", err), err)
+		return
+	}
+
+	var document []byte
+	var documentType string
+	file, _, err := r.FormFile("document")
+	if err == nil {
+		defer file.Close()
+		documentType, err = detectDocumentType(file)
+		if errors.Is(err, imaging.ErrUnsupportedFormat) {
+			utils.WriteJSONError(w, http.StatusUnsupportedMediaType, "HealthRecordHandler.AddRecord: Document must be a PDF or a JPEG, PNG or GIF image", err)
+			return
+		}
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "HealthRecordHandler.AddRecord: Invalid document", err)
+			return
+		}
+		buffer := bytes.NewBuffer(nil)
+		if _, err := io.Copy(buffer, file); err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "HealthRecordHandler.AddRecord: Failed to read document", err)
+			return
+		}
+		document = buffer.Bytes()
+	}
+
+	created, err := h.healthRecordService.AddRecord(r.Context(), actorID, *record, document, documentType)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("HealthRecordHandler.AddRecord: Failed to add health record for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(buildHealthRecordResponse(created))
+}
+
+// GetRecords handles GET requests for a child's health records
+func (h *HealthRecordHandler) GetRecords(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "HealthRecordHandler.GetRecords: Failed to get user ID from auth", err)
+		return
+	}
+
+	records, err := h.healthRecordService.GetRecords(actorID, childID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("HealthRecordHandler.GetRecords: Failed to get health records for Child with ID %s", childID))
+		return
+	}
+
+	responses := []map[string]interface{}{}
+	for _, record := range records {
+		responses = append(responses, buildHealthRecordResponse(record))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responses)
+}
+
+// GetDocument handles GET requests for the scanned document behind a health record
+func (h *HealthRecordHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	recordID := r.PathValue("recordId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "HealthRecordHandler.GetDocument: Failed to get user ID from auth", err)
+		return
+	}
+
+	data, contentType, err := h.healthRecordService.GetDocument(r.Context(), actorID, childID, recordID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("HealthRecordHandler.GetDocument: Failed to get document for health record %s", recordID))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// DeleteRecord handles DELETE requests removing a health record and its document
+func (h *HealthRecordHandler) DeleteRecord(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	recordID := r.PathValue("recordId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "HealthRecordHandler.DeleteRecord: Failed to get user ID from auth", err)
+		return
+	}
+
+	if err := h.healthRecordService.DeleteRecord(r.Context(), actorID, childID, recordID); err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("HealthRecordHandler.DeleteRecord: Failed to delete health record %s", recordID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCompliance handles GET requests for the dashboard of children with missing or expiring records
+func (h *HealthRecordHandler) GetCompliance(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "HealthRecordHandler.GetCompliance: Failed to get user ID from auth", err)
+		return
+	}
+
+	report, err := h.healthRecordService.GetComplianceReport(actorID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "HealthRecordHandler.GetCompliance: Failed to build compliance report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// Helper function to package JSON response
+func buildHealthRecordResponse(record models.HealthRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          record.ID,
+		"childId":     record.ChildID,
+		"type":        record.Type,
+		"vaccine":     record.Vaccine,
+		"dose":        record.Dose,
+		"date":        record.Date,
+		"expiresOn":   record.ExpiresOn,
+		"hasDocument": record.DocumentBlob != "",
+		"uploadedBy":  record.UploadedBy,
+		"uploadedAt":  record.UploadedAt,
+	}
+}
+
+// Helper - detect a health document's type from its leading bytes, PDFs are accepted as they are and anything
+// else must pass as an image
+func detectDocumentType(file multipart.File) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("detectDocumentType: Failed to read document: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("detectDocumentType: Failed to rewind document: %w", err)
+	}
+	if http.DetectContentType(head[:n]) == "application/pdf" {
+		return "application/pdf", nil
+	}
+	contentType, _, err := imaging.Validate(file, MaxImagePixels)
+	return contentType, err
+}
@@ -1,14 +1,16 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"image"
 	"io"
 	"net/http"
-	"path/filepath"
-	"strings"
 
+	"littleeinsteinchildcare/backend/internal/imaging"
 	"littleeinsteinchildcare/backend/internal/models"
 	"littleeinsteinchildcare/backend/internal/services"
 	"littleeinsteinchildcare/backend/internal/utils"
@@ -17,13 +19,18 @@ import (
 const (
 	// Maximum upload size of 10MB
 	MaxUploadSize = 10 << 20
+	// Portion of an image upload kept in memory while parsing, the rest is spooled to disk
+	UploadMemoryLimit = 1 << 20
+	// Maximum width x height of an uploaded image, about a 40 megapixel photo
+	MaxImagePixels = 40_000_000
 )
 
 type BlobService interface {
-	UploadImage(ctx context.Context, fileName string, contentType string, data []byte, userID string) (*models.Image, error)
-	GetImage(ctx context.Context, userID, fileName string) ([]byte, string, error)
-	GetAllImages(ctx context.Context) ([]string, error)
-	DeleteImage(ctx context.Context, userID, fileName string) error
+	UploadImage(ctx context.Context, fileName string, contentType string, data io.Reader, upright *image.RGBA, format string, userID string, classroomID string, hash string) (*models.Image, error)
+	OpenImage(ctx context.Context, actorID, ownerID, fileName string, size models.ImageSize) (*models.ImageStream, error)
+	SignImageURL(ctx context.Context, actorID, ownerID, fileName string, size models.ImageSize) (*models.SignedImageURL, error)
+	GetVisibleImages(ctx context.Context, actorID string) ([]string, error)
+	DeleteImage(ctx context.Context, actorID, ownerID, fileName string) error
 }
 
 type StatisticsService interface {
@@ -49,12 +56,13 @@ func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 	// Set appropriate headers
 	w.Header().Set("Content-Type", "application/json")
 
-	// Parse the multipart form data with a max memory allocation
-	err := r.ParseMultipartForm(MaxUploadSize)
+	// Parse the multipart form data, files larger than the memory allowance are spooled to a temp file
+	err := r.ParseMultipartForm(UploadMemoryLimit)
 	if err != nil {
 		http.Error(w, "Unable to parse form", http.StatusBadRequest)
 		return
 	}
+	defer r.MultipartForm.RemoveAll()
 
 	// Get the userID from the request
 	userID, err := utils.GetUserIDFromAuth(r)
@@ -91,40 +99,83 @@ func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Read the file content
-	buffer := bytes.NewBuffer(nil)
-	if _, err := io.Copy(buffer, file); err != nil {
-		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+	// The client's Content-Type header and filename are ignored, the format comes from the file's own bytes
+	contentType, extension, err := imaging.Validate(file, MaxImagePixels)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) {
+		utils.WriteJSONError(w, http.StatusUnsupportedMediaType, "ImageHandler.UploadImage: Only JPEG, PNG and GIF images are accepted", err)
+		return
+	}
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ImageHandler.UploadImage: Invalid image", err)
+		return
+	}
+
+	// The upload is decoded once, its upright pixels re-encode rotated photos and build the resized variants.
+	// Truncated and corrupted files are refused here, before anything is stored
+	upright, format, err := imaging.DecodeUpright(file)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ImageHandler.UploadImage: Image is corrupted", err)
 		return
 	}
 
-	// Get the file name and content type
-	fileName := filepath.Base(header.Filename)
-	contentType := header.Header.Get("Content-Type")
+	// Server generated names cannot collide or smuggle path characters into the blob name
+	fileName := utils.NewID() + extension
 
-	// Check if the content type is an image
-	if !strings.HasPrefix(contentType, "image/") {
-		http.Error(w, "File is not an image", http.StatusBadRequest)
+	// Parents often re-upload the same photo, the hash of the file as received identifies repeats
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
 		return
 	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+		return
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	// Optional classroom the photo is shared with, only staff assigned to it may post there
+	classroomID := r.FormValue("classroom")
+
+	// Photos taken on phones carry GPS coordinates and camera serials, they are scrubbed as the file streams
+	// to Azure Blob Storage so it is never held in memory whole
+	scrubbed, scrubSink := io.Pipe()
+	sanitizeDone := make(chan bool, 1)
+	go func() {
+		sanitized, err := imaging.Sanitize(scrubSink, file, upright)
+		scrubSink.CloseWithError(err)
+		sanitizeDone <- sanitized
+	}()
 
-	// Upload to Azure Blob Storage
 	ctx := context.Background()
-	image, err := h.blobService.UploadImage(ctx, fileName, contentType, buffer.Bytes(), userID)
+	image, err := h.blobService.UploadImage(ctx, fileName, contentType, scrubbed, upright, format, userID, classroomID, hash)
+	// Unblocks the sanitizer when the upload stopped reading early
+	scrubbed.Close()
+	sanitized := <-sanitizeDone
+	if errors.Is(err, services.ErrForbidden) {
+		utils.WriteJSONError(w, http.StatusForbidden, "Not allowed to post photos to this classroom", err)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to upload image", http.StatusInternalServerError)
 		return
 	}
 
-	// Track the image in statistics
-	h.statisticsService.TrackUploadedImage(header.Size)
-
 	// Return success response
 	response := models.ImageUploadResponse{
 		Success: true,
 		Message: "Image uploaded successfully",
 		Image:   image,
 	}
+	status := http.StatusCreated
+	if image.Duplicate {
+		response.Message = "Image already uploaded"
+		status = http.StatusOK
+	} else {
+		image.Sanitized = sanitized
+
+		// Track the image in statistics
+		h.statisticsService.TrackUploadedImage(header.Size)
+	}
 
 	// Marshal the response to JSON
 	jsonResponse, err := json.Marshal(response)
@@ -133,7 +184,7 @@ func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(status)
 	w.Write(jsonResponse)
 }
 
@@ -148,31 +199,86 @@ func (c *ImageHandler) GetImage(w http.ResponseWriter, r *http.Request) {
 
 	fileName := r.PathValue("fileName")
 
+	// Owners open their own images, staff may open classroom photos uploaded by others
+	ownerID := r.PathValue("id")
+	if ownerID == "" {
+		ownerID = userID
+	}
+
 	if userID == "" || fileName == "" {
 		http.Error(w, "Image ID and file name are required", http.StatusBadRequest)
 		return
 	}
 
-	// Get the image from Azure Blob Storage
-	ctx := context.Background()
-	data, contentType, err := c.blobService.GetImage(ctx, userID, fileName)
+	// Thumbnails and medium variants keep phones from downloading the full upload
+	size, err := models.ParseImageSize(r.URL.Query().Get("size"))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ImageHandler.GetImage: Invalid size", err)
+		return
+	}
+
+	// The blob is streamed to the client, so the read stops when the client disconnects
+	stream, err := c.blobService.OpenImage(r.Context(), userID, ownerID, fileName, size)
+	if errors.Is(err, services.ErrForbidden) {
+		utils.WriteJSONError(w, http.StatusForbidden, "Not allowed to view this image", err)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to get image", http.StatusNotFound)
 		return
 	}
+	serveImageStream(w, r, fileName, stream)
+}
 
-	// Set the content type header
-	w.Header().Set("Content-Type", contentType)
+// Helper - stream an opened image to the client and close it. ServeContent answers Range requests from the blob and
+// replies 304 when If-None-Match matches the ETag, browsers must revalidate since access can be revoked
+func serveImageStream(w http.ResponseWriter, r *http.Request, fileName string, stream *models.ImageStream) {
+	defer stream.Content.Close()
+	w.Header().Set("Content-Type", stream.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.Header().Set("Content-Disposition", "inline; filename="+fileName)
+	w.Header().Set("ETag", stream.ETag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	http.ServeContent(w, r, fileName, stream.LastModified, stream.Content)
+}
+
+// GetImageURL mints a short-lived URL for an image that an <img> tag can load without the bearer token
+func (c *ImageHandler) GetImageURL(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Failed to retrieve userID", err)
+		return
+	}
+	ownerID := r.PathValue("id")
+	fileName := r.PathValue("fileName")
+
+	size, err := models.ParseImageSize(r.URL.Query().Get("size"))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ImageHandler.GetImageURL: Invalid size", err)
+		return
+	}
+
+	signed, err := c.blobService.SignImageURL(r.Context(), userID, ownerID, fileName, size)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, "ImageHandler.GetImageURL: Failed to sign image URL")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	json.NewEncoder(w).Encode(signed)
 }
 
+// GetAllImages lists the caller's own image blob names, or every user's for admins
 func (c *ImageHandler) GetAllImages(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ImageHandler.GetAllImages: Failed to get user ID from auth", err)
+		return
+	}
 
-	// Get the image from Azure Blob Storage
-	ctx := context.Background()
-	imgNames, err := c.blobService.GetAllImages(ctx)
+	// Get the image names from Azure Blob Storage
+	imgNames, err := c.blobService.GetVisibleImages(r.Context(), actorID)
 	if err != nil {
 		utils.WriteJSONError(w, http.StatusNotFound, "ImageHandler.GetAllImages: Failed to retrieve list of images", err)
 		return
@@ -196,6 +302,12 @@ func (h *ImageHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
 
 	fileName := r.PathValue("fileName")
 
+	// Owners delete their own images, staff may delete classroom photos uploaded by others
+	ownerID := r.PathValue("id")
+	if ownerID == "" {
+		ownerID = userID
+	}
+
 	if userID == "" || fileName == "" {
 		http.Error(w, "Image ID and file name are required", http.StatusBadRequest)
 		return
@@ -203,7 +315,11 @@ func (h *ImageHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
 
 	// Delete the image from Azure Blob Storage
 	ctx := context.Background()
-	err2 := h.blobService.DeleteImage(ctx, userID, fileName)
+	err2 := h.blobService.DeleteImage(ctx, userID, ownerID, fileName)
+	if errors.Is(err2, services.ErrForbidden) {
+		utils.WriteJSONError(w, http.StatusForbidden, "Not allowed to delete this image", err2)
+		return
+	}
 	if err2 != nil {
 		http.Error(w, "Failed to delete image", http.StatusNotFound)
 		return
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IncidentService interface implemented in services package
+type IncidentService interface {
+	CreateIncident(actorID string, draft models.Incident) (models.Incident, error)
+	GetIncident(actorID string, id string) (models.Incident, error)
+	GetIncidentsByChild(actorID string, childID string) ([]models.Incident, error)
+	UpdateIncident(actorID string, newData models.Incident) (models.Incident, error)
+	SubmitIncident(actorID string, id string) (models.Incident, error)
+	AcknowledgeIncident(actorID string, id string) (models.Incident, error)
+	ExportIncidents(from time.Time, to time.Time) ([]models.Incident, error)
+}
+
+// IncidentHandler handles HTTP requests for incident reports
+type IncidentHandler struct {
+	incidentService IncidentService
+}
+
+// NewIncidentHandler creates a new incident handler
+func NewIncidentHandler(s IncidentService) *IncidentHandler {
+	return &IncidentHandler{
+		incidentService: s,
+	}
+}
+
+// incidentRequest is the JSON body accepted when creating or editing a report, times use RFC3339
+type incidentRequest struct {
+	ChildID     string    `json:"childId"`
+	OccurredAt  time.Time `json:"occurredAt"`
+	Location    string    `json:"location"`
+	Description string    `json:"description"`
+	FirstAid    string    `json:"firstAid"`
+	Witnesses   []string  `json:"witnesses"`
+}
+
+// CreateIncident handles POST requests starting a draft incident report
+func (h *IncidentHandler) CreateIncident(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "IncidentHandler.CreateIncident: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req incidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChildID == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "IncidentHandler.CreateIncident: Missing childId or invalid JSON request", err)
+		return
+	}
+
+	draft := models.Incident{
+		ID:          utils.NewID(),
+		ChildID:     req.ChildID,
+		OccurredAt:  req.OccurredAt,
+		Location:    req.Location,
+		Description: req.Description,
+		FirstAid:    req.FirstAid,
+		Witnesses:   req.Witnesses,
+	}
+	incident, err := h.incidentService.CreateIncident(actorID, draft)
+	if err != nil {
+		writeIncidentError(w, err, http.StatusBadRequest, "IncidentHandler.CreateIncident: Failed to create incident report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(incident)
+}
+
+// GetIncident handles GET requests for a single incident report
+func (h *IncidentHandler) GetIncident(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "IncidentHandler.GetIncident: Failed to get user ID from auth", err)
+		return
+	}
+
+	incident, err := h.incidentService.GetIncident(actorID, id)
+	if err != nil {
+		writeIncidentError(w, err, http.StatusNotFound, fmt.Sprintf("IncidentHandler.GetIncident: Failed to find incident report with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(incident)
+}
+
+// GetChildIncidents handles GET requests for the incident reports about a child
+func (h *IncidentHandler) GetChildIncidents(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "IncidentHandler.GetChildIncidents: Failed to get user ID from auth", err)
+		return
+	}
+
+	incidents, err := h.incidentService.GetIncidentsByChild(actorID, childID)
+	if err != nil {
+		writeIncidentError(w, err, http.StatusInternalServerError, fmt.Sprintf("IncidentHandler.GetChildIncidents: Failed to retrieve incident reports for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(incidents)
+}
+
+// UpdateIncident handles PUT requests with partial updates to a draft report
+func (h *IncidentHandler) UpdateIncident(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "IncidentHandler.UpdateIncident: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req incidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "IncidentHandler.UpdateIncident: Failed to decode JSON request", err)
+		return
+	}
+
+	newData := models.Incident{
+		ID:          id,
+		OccurredAt:  req.OccurredAt,
+		Location:    req.Location,
+		Description: req.Description,
+		FirstAid:    req.FirstAid,
+		Witnesses:   req.Witnesses,
+	}
+	incident, err := h.incidentService.UpdateIncident(actorID, newData)
+	if err != nil {
+		writeIncidentError(w, err, http.StatusNotFound, fmt.Sprintf("IncidentHandler.UpdateIncident: Failed to update incident report with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(incident)
+}
+
+// SubmitIncident handles POST requests finalising a draft and notifying guardians
+func (h *IncidentHandler) SubmitIncident(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "IncidentHandler.SubmitIncident: Failed to get user ID from auth", err)
+		return
+	}
+
+	incident, err := h.incidentService.SubmitIncident(actorID, id)
+	if err != nil {
+		writeIncidentError(w, err, http.StatusNotFound, fmt.Sprintf("IncidentHandler.SubmitIncident: Failed to submit incident report with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(incident)
+}
+
+// AcknowledgeIncident handles POST requests from guardians signing off on a report
+func (h *IncidentHandler) AcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "IncidentHandler.AcknowledgeIncident: Failed to get user ID from auth", err)
+		return
+	}
+
+	incident, err := h.incidentService.AcknowledgeIncident(actorID, id)
+	if err != nil {
+		writeIncidentError(w, err, http.StatusNotFound, fmt.Sprintf("IncidentHandler.AcknowledgeIncident: Failed to acknowledge incident report with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(incident)
+}
+
+// ExportIncidents handles GET requests for a CSV of every report in a date range, ?from= and ?to= are inclusive dates
+func (h *IncidentHandler) ExportIncidents(w http.ResponseWriter, r *http.Request) {
+	from, err := time.ParseInLocation(models.AttendanceDateFormat, r.URL.Query().Get("from"), models.CenterLocation())
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "IncidentHandler.ExportIncidents: from must be a YYYY-MM-DD date", err)
+		return
+	}
+	to, err := time.ParseInLocation(models.AttendanceDateFormat, r.URL.Query().Get("to"), models.CenterLocation())
+	if err != nil || to.Before(from) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "IncidentHandler.ExportIncidents: to must be a YYYY-MM-DD date on or after from", err)
+		return
+	}
+
+	incidents, err := h.incidentService.ExportIncidents(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "IncidentHandler.ExportIncidents: Failed to retrieve incident reports", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=incidents-%s-%s.csv", from.Format(models.AttendanceDateFormat), to.Format(models.AttendanceDateFormat)))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "childId", "classroom", "occurredAt", "location", "description", "firstAid", "witnesses", "authorId", "status", "submittedAt", "acknowledgedAt", "acknowledgedBy"})
+	for _, incident := range incidents {
+		writer.Write([]string{
+			incident.ID,
+			incident.ChildID,
+			incident.Classroom,
+			incident.OccurredAt.Format(time.RFC3339),
+			incident.Location,
+			incident.Description,
+			incident.FirstAid,
+			strings.Join(incident.Witnesses, "; "),
+			incident.AuthorID,
+			string(incident.Status),
+			formatExportTime(incident.SubmittedAt),
+			formatExportTime(incident.AcknowledgedAt),
+			incident.AcknowledgedBy,
+		})
+	}
+	writer.Flush()
+}
+
+// Helper - map workflow errors to 409, forbidden errors to 403 and everything else to the given status
+func writeIncidentError(w http.ResponseWriter, err error, status int, msg string) {
+	if errors.Is(err, services.ErrIncidentState) {
+		utils.WriteJSONError(w, http.StatusConflict, msg, err)
+		return
+	}
+	writeServiceError(w, err, status, msg)
+}
+
+// Helper - blank for workflow steps that have not happened yet
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
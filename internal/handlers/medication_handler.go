@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+	"time"
+)
+
+// MedicationService interface implemented in services package
+type MedicationService interface {
+	GetAuthorizations(actorID string, childID string) ([]models.MedicationAuthorization, error)
+	AddAuthorization(actorID string, authorization models.MedicationAuthorization) (models.MedicationAuthorization, error)
+	RevokeAuthorization(actorID string, childID string, authorizationID string) (models.MedicationAuthorization, error)
+	LogDose(actorID string, dose models.MedicationDose) (models.MedicationDose, error)
+	GetDoses(actorID string, childID string) ([]models.MedicationDose, error)
+}
+
+// MedicationHandler handles HTTP requests for medication authorizations and the dose log
+type MedicationHandler struct {
+	medicationService MedicationService
+}
+
+// NewMedicationHandler creates a new medication handler
+func NewMedicationHandler(s MedicationService) *MedicationHandler {
+	return &MedicationHandler{
+		medicationService: s,
+	}
+}
+
+// medicationAuthorizationRequest is the JSON body a guardian signs to authorize a medication
+type medicationAuthorizationRequest struct {
+	Drug            string   `json:"drug"`
+	Dose            string   `json:"dose"`
+	Instructions    string   `json:"instructions"`
+	Times           []string `json:"times"`
+	MinIntervalMins int      `json:"minIntervalMins"`
+	StartDate       string   `json:"startDate"`
+	EndDate         string   `json:"endDate"`
+	Signature       string   `json:"signature"`
+}
+
+// GetAuthorizations handles GET requests for a child's medication authorizations
+func (h *MedicationHandler) GetAuthorizations(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MedicationHandler.GetAuthorizations: Failed to get user ID from auth", err)
+		return
+	}
+
+	authorizations, err := h.medicationService.GetAuthorizations(actorID, childID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("MedicationHandler.GetAuthorizations: Failed to get medication authorizations for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(authorizations)
+}
+
+// AddAuthorization handles POST requests from guardians signing a medication authorization
+func (h *MedicationHandler) AddAuthorization(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MedicationHandler.AddAuthorization: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req medicationAuthorizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "MedicationHandler.AddAuthorization: Failed to decode JSON request", err)
+		return
+	}
+	authorization, err := models.NewMedicationAuthorization(utils.NewID(), childID, req.Drug, req.Dose, req.Instructions, req.Times, req.MinIntervalMins, req.StartDate, req.EndDate, actorID, req.Signature)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("MedicationHandler.AddAuthorization: %v", err), err)
+		return
+	}
+
+	created, err := h.medicationService.AddAuthorization(actorID, *authorization)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("MedicationHandler.AddAuthorization: Failed to add medication authorization for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// RevokeAuthorization handles DELETE requests from guardians withdrawing a medication authorization
+func (h *MedicationHandler) RevokeAuthorization(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	authorizationID := r.PathValue("authorizationId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MedicationHandler.RevokeAuthorization: Failed to get user ID from auth", err)
+		return
+	}
+
+	authorization, err := h.medicationService.RevokeAuthorization(actorID, childID, authorizationID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("MedicationHandler.RevokeAuthorization: Failed to revoke medication authorization %s", authorizationID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(authorization)
+}
+
+// LogDose handles POST requests from staff recording an administered dose, administeredAt defaults to now
+func (h *MedicationHandler) LogDose(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	authorizationID := r.PathValue("authorizationId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MedicationHandler.LogDose: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		AdministeredAt time.Time `json:"administeredAt"`
+		Notes          string    `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "MedicationHandler.LogDose: Failed to decode JSON request", err)
+		return
+	}
+	if req.AdministeredAt.IsZero() {
+		req.AdministeredAt = time.Now()
+	}
+	if req.AdministeredAt.After(time.Now().Add(5 * time.Minute)) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "MedicationHandler.LogDose: administeredAt cannot be in the future", nil)
+		return
+	}
+
+	dose := models.MedicationDose{
+		ID:              utils.NewID(),
+		ChildID:         childID,
+		AuthorizationID: authorizationID,
+		AdministeredAt:  req.AdministeredAt,
+		Notes:           req.Notes,
+	}
+	logged, err := h.medicationService.LogDose(actorID, dose)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("MedicationHandler.LogDose: Failed to log dose for medication authorization %s", authorizationID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(logged)
+}
+
+// GetDoses handles GET requests for a child's medication dose log
+func (h *MedicationHandler) GetDoses(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MedicationHandler.GetDoses: Failed to get user ID from auth", err)
+		return
+	}
+
+	doses, err := h.medicationService.GetDoses(actorID, childID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("MedicationHandler.GetDoses: Failed to get dose log for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(doses)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+	"time"
+)
+
+// MenuService interface implemented in services package
+type MenuService interface {
+	PublishMenu(menu models.Menu) (models.Menu, []models.AllergenConflict, error)
+	GetMenu(actorID string, week string) (models.Menu, []models.AllergenConflict, error)
+}
+
+// MenuHandler handles HTTP requests for weekly meal menus
+type MenuHandler struct {
+	menuService MenuService
+}
+
+// NewMenuHandler creates a new menu handler
+func NewMenuHandler(s MenuService) *MenuHandler {
+	return &MenuHandler{
+		menuService: s,
+	}
+}
+
+// GetMenu handles GET requests for a week's menu, ?week= is any YYYY-MM-DD date in the week and defaults to this week.
+// Allergen conflicts are included for the children the user can see
+func (h *MenuHandler) GetMenu(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MenuHandler.GetMenu: Failed to get user ID from auth", err)
+		return
+	}
+
+	day := models.CenterNow()
+	if week := r.URL.Query().Get("week"); week != "" {
+		day, err = time.ParseInLocation(models.AttendanceDateFormat, week, models.CenterLocation())
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "MenuHandler.GetMenu: week must be a YYYY-MM-DD date", err)
+			return
+		}
+	}
+	week := models.WeekStart(day).Format(models.AttendanceDateFormat)
+
+	menu, conflicts, err := h.menuService.GetMenu(actorID, week)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("MenuHandler.GetMenu: No menu published for the week of %s", week))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"menu": menu, "conflicts": conflicts})
+}
+
+// PublishMenu handles PUT requests publishing or replacing the menu for the week containing {week}
+func (h *MenuHandler) PublishMenu(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MenuHandler.PublishMenu: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		Items []models.MenuItem `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "MenuHandler.PublishMenu: Failed to decode JSON request", err)
+		return
+	}
+	menu, err := models.NewMenu(r.PathValue("week"), req.Items, actorID, time.Now())
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "MenuHandler.PublishMenu: Invalid menu", err)
+		return
+	}
+
+	published, conflicts, err := h.menuService.PublishMenu(*menu)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, fmt.Sprintf("MenuHandler.PublishMenu: Failed to publish menu for the week of %s", menu.Week), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"menu": published, "conflicts": conflicts})
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MessageService interface implemented in services package
+type MessageService interface {
+	StartDirectThread(actorID string, thread models.Thread) (models.Thread, error)
+	StartClassroomThread(actorID string, thread models.Thread) (models.Thread, error)
+	GetThreads(actorID string) ([]models.Thread, error)
+	UnreadCount(userID string) (int, error)
+	GetMessages(actorID string, threadID string) ([]models.Message, error)
+	SendMessage(ctx context.Context, actorID string, message models.Message) (models.Message, error)
+	MarkRead(actorID string, threadID string) (models.ReadReceipt, error)
+	GetAttachment(ctx context.Context, actorID string, threadID string, messageID string, index int) (*models.ImageStream, error)
+}
+
+// MessageHandler handles HTTP requests for guardian and staff messaging
+type MessageHandler struct {
+	messageService MessageService
+}
+
+// NewMessageHandler creates a new message handler
+func NewMessageHandler(s MessageService) *MessageHandler {
+	return &MessageHandler{
+		messageService: s,
+	}
+}
+
+// GetThreads handles GET requests for the acting user's threads with unread counts
+func (h *MessageHandler) GetThreads(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MessageHandler.GetThreads: Failed to get user ID from auth", err)
+		return
+	}
+
+	threads, err := h.messageService.GetThreads(actorID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "MessageHandler.GetThreads: Failed to retrieve threads")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(threads)
+}
+
+// StartThread handles POST requests opening a direct thread with {"userId"} or a classroom broadcast with {"classroom"}
+func (h *MessageHandler) StartThread(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MessageHandler.StartThread: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		Kind      models.ThreadKind `json:"kind"`
+		UserID    string            `json:"userId"`
+		Classroom string            `json:"classroom"`
+		Subject   string            `json:"subject"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "MessageHandler.StartThread: Failed to decode JSON request", err)
+		return
+	}
+
+	var thread models.Thread
+	switch req.Kind {
+	case models.ThreadDirect:
+		draft, err := models.NewDirectThread(utils.NewID(), req.Subject, actorID, req.UserID, time.Now())
+		if err != nil || req.UserID == "" {
+			utils.WriteJSONError(w, http.StatusBadRequest, "MessageHandler.StartThread: Direct threads need another userId", err)
+			return
+		}
+		thread, err = h.messageService.StartDirectThread(actorID, *draft)
+		if err != nil {
+			writeServiceError(w, err, http.StatusBadRequest, "MessageHandler.StartThread: Failed to start direct thread")
+			return
+		}
+	case models.ThreadClassroom:
+		draft, err := models.NewClassroomThread(utils.NewID(), req.Subject, actorID, req.Classroom, time.Now())
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "MessageHandler.StartThread: Invalid classroom thread", err)
+			return
+		}
+		thread, err = h.messageService.StartClassroomThread(actorID, *draft)
+		if err != nil {
+			writeServiceError(w, err, http.StatusBadRequest, "MessageHandler.StartThread: Failed to start classroom thread")
+			return
+		}
+	default:
+		utils.WriteJSONError(w, http.StatusBadRequest, "MessageHandler.StartThread: kind must be direct or classroom", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(thread)
+}
+
+// GetMessages handles GET requests for a thread's messages with read receipts
+func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MessageHandler.GetMessages: Failed to get user ID from auth", err)
+		return
+	}
+
+	messages, err := h.messageService.GetMessages(actorID, threadID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("MessageHandler.GetMessages: Failed to retrieve messages for thread %s", threadID))
+		return
+	}
+
+	response := []map[string]interface{}{}
+	for _, message := range messages {
+		response = append(response, buildMessageResponse(message))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SendMessage handles POST requests posting to a thread, attachments reference images already uploaded by the sender
+func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MessageHandler.SendMessage: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		Body        string                     `json:"body"`
+		Attachments []models.MessageAttachment `json:"attachments"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "MessageHandler.SendMessage: Failed to decode JSON request", err)
+		return
+	}
+	draft, err := models.NewMessage(utils.NewID(), threadID, actorID, req.Body, req.Attachments, time.Now())
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "MessageHandler.SendMessage: Invalid message", err)
+		return
+	}
+
+	message, err := h.messageService.SendMessage(r.Context(), actorID, *draft)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("MessageHandler.SendMessage: Failed to send message to thread %s", threadID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(buildMessageResponse(message))
+}
+
+// MarkRead handles POST requests recording that the acting user has read a thread
+func (h *MessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MessageHandler.MarkRead: Failed to get user ID from auth", err)
+		return
+	}
+
+	receipt, err := h.messageService.MarkRead(actorID, threadID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("MessageHandler.MarkRead: Failed to mark thread %s read", threadID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(receipt)
+}
+
+// GetAttachment handles GET requests for an image attached to a message
+func (h *MessageHandler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("id")
+	messageID := r.PathValue("messageId")
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "MessageHandler.GetAttachment: Attachment index must be a number", err)
+		return
+	}
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "MessageHandler.GetAttachment: Failed to get user ID from auth", err)
+		return
+	}
+
+	stream, err := h.messageService.GetAttachment(r.Context(), actorID, threadID, messageID, index)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("MessageHandler.GetAttachment: Failed to get attachment %d of message %s", index, messageID))
+		return
+	}
+	serveImageStream(w, r, fmt.Sprintf("%s-%d", messageID, index), stream)
+}
+
+// Helper function to package JSON response, adding the URLs attachments are served from
+func buildMessageResponse(message models.Message) map[string]interface{} {
+	attachmentURLs := []string{}
+	for i := range message.Attachments {
+		attachmentURLs = append(attachmentURLs, fmt.Sprintf("/api/threads/%s/messages/%s/attachments/%d", message.ThreadID, message.ID, i))
+	}
+	return map[string]interface{}{
+		"id":             message.ID,
+		"threadId":       message.ThreadID,
+		"senderId":       message.SenderID,
+		"body":           message.Body,
+		"attachments":    message.Attachments,
+		"attachmentUrls": attachmentURLs,
+		"sentAt":         message.SentAt,
+		"readBy":         message.ReadBy,
+	}
+}
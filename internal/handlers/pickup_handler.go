@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"littleeinsteinchildcare/backend/internal/imaging"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+)
+
+// PickupService interface implemented in services package
+type PickupService interface {
+	GetPickups(actorID string, childID string) ([]models.AuthorizedPickup, error)
+	AddPickup(ctx context.Context, actorID string, pickup models.AuthorizedPickup, photo []byte, photoType string) (models.AuthorizedPickup, error)
+	RemovePickup(ctx context.Context, actorID string, childID string, pickupID string) error
+	GetPickupPhoto(ctx context.Context, actorID string, childID string, pickupID string) ([]byte, string, error)
+	GetAudits(actorID string, childID string) ([]models.PickupAudit, error)
+}
+
+// PickupHandler handles HTTP requests for a child's authorized pickup list and its audit trail
+type PickupHandler struct {
+	pickupService PickupService
+}
+
+// NewPickupHandler creates a new pickup handler
+func NewPickupHandler(s PickupService) *PickupHandler {
+	return &PickupHandler{
+		pickupService: s,
+	}
+}
+
+// GetPickups handles GET requests for a child's authorized pickup list
+func (h *PickupHandler) GetPickups(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "PickupHandler.GetPickups: Failed to get user ID from auth", err)
+		return
+	}
+
+	pickups, err := h.pickupService.GetPickups(actorID, childID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("PickupHandler.GetPickups: Failed to get pickup list for Child with ID %s", childID))
+		return
+	}
+
+	responses := []map[string]interface{}{}
+	for _, pickup := range pickups {
+		responses = append(responses, buildPickupResponse(pickup))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responses)
+}
+
+// AddPickup handles multipart POST requests adding an adult, with an optional "photo" file, to a child's pickup list
+func (h *PickupHandler) AddPickup(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "PickupHandler.AddPickup: Failed to get user ID from auth", err)
+		return
+	}
+	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "PickupHandler.AddPickup: Unable to parse form", err)
+		return
+	}
+
+	pickup, err := models.NewAuthorizedPickup(utils.NewID(), childID, r.FormValue("name"), r.FormValue("relationship"), r.FormValue("phone"), actorID)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("PickupHandler.AddPickup: %v", err), err)
+		return
+	}
+
+	var photo []byte
+	var photoType string
+	file, _, err := r.FormFile("photo")
+	if err == nil {
+		defer file.Close()
+		photoType, _, err = imaging.Validate(file, MaxImagePixels)
+		if errors.Is(err, imaging.ErrUnsupportedFormat) {
+			utils.WriteJSONError(w, http.StatusUnsupportedMediaType, "PickupHandler.AddPickup: Only JPEG, PNG and GIF photos are accepted", err)
+			return
+		}
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "PickupHandler.AddPickup: Invalid photo", err)
+			return
+		}
+		buffer := bytes.NewBuffer(nil)
+		if _, err := io.Copy(buffer, file); err != nil {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "PickupHandler.AddPickup: Failed to read photo", err)
+			return
+		}
+		photo = buffer.Bytes()
+	}
+
+	created, err := h.pickupService.AddPickup(r.Context(), actorID, *pickup, photo, photoType)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest, fmt.Sprintf("PickupHandler.AddPickup: Failed to add pickup for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(buildPickupResponse(created))
+}
+
+// RemovePickup handles DELETE requests removing an adult from a child's pickup list
+func (h *PickupHandler) RemovePickup(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	pickupID := r.PathValue("pickupId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "PickupHandler.RemovePickup: Failed to get user ID from auth", err)
+		return
+	}
+
+	if err := h.pickupService.RemovePickup(r.Context(), actorID, childID, pickupID); err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("PickupHandler.RemovePickup: Failed to remove pickup %s from Child with ID %s", pickupID, childID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPickupPhoto handles GET requests for an authorized pickup's photo
+func (h *PickupHandler) GetPickupPhoto(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	pickupID := r.PathValue("pickupId")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "PickupHandler.GetPickupPhoto: Failed to get user ID from auth", err)
+		return
+	}
+
+	data, contentType, err := h.pickupService.GetPickupPhoto(r.Context(), actorID, childID, pickupID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("PickupHandler.GetPickupPhoto: Failed to get photo for pickup %s", pickupID))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// GetAudits handles GET requests for a child's checkout audit trail
+func (h *PickupHandler) GetAudits(w http.ResponseWriter, r *http.Request) {
+	childID := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "PickupHandler.GetAudits: Failed to get user ID from auth", err)
+		return
+	}
+
+	audits, err := h.pickupService.GetAudits(actorID, childID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound, fmt.Sprintf("PickupHandler.GetAudits: Failed to get pickup audit for Child with ID %s", childID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(audits)
+}
+
+// Helper function to package JSON response
+func buildPickupResponse(pickup models.AuthorizedPickup) map[string]interface{} {
+	photoURL := ""
+	if pickup.PhotoBlob != "" {
+		photoURL = fmt.Sprintf("/api/children/%s/pickups/%s/photo", pickup.ChildID, pickup.ID)
+	}
+	return map[string]interface{}{
+		"id":           pickup.ID,
+		"childId":      pickup.ChildID,
+		"name":         pickup.Name,
+		"relationship": pickup.Relationship,
+		"phone":        pickup.Phone,
+		"photoUrl":     photoURL,
+		"addedBy":      pickup.AddedBy,
+		"addedAt":      pickup.AddedAt,
+	}
+}
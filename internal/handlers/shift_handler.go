@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+)
+
+// ShiftService interface implemented in services package
+type ShiftService interface {
+	ClockIn(actorID string, classroomID string) (models.Shift, error)
+	ClockOut(actorID string) (models.Shift, error)
+}
+
+// RatioService interface implemented in services package
+type RatioService interface {
+	GetRatios(actorID string) ([]models.ClassroomRatio, error)
+}
+
+// ShiftHandler handles HTTP requests for staff shifts and the ratios they feed
+type ShiftHandler struct {
+	shiftService ShiftService
+	ratioService RatioService
+}
+
+// NewShiftHandler creates a new shift handler
+func NewShiftHandler(s ShiftService, rs RatioService) *ShiftHandler {
+	return &ShiftHandler{
+		shiftService: s,
+		ratioService: rs,
+	}
+}
+
+// ClockIn handles POST requests starting the caller's shift in a classroom
+func (h *ShiftHandler) ClockIn(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ShiftHandler.ClockIn: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		Classroom string `json:"classroom"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Classroom == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "ShiftHandler.ClockIn: Missing or invalid classroom", err)
+		return
+	}
+
+	shift, err := h.shiftService.ClockIn(actorID, req.Classroom)
+	if err != nil {
+		writeShiftError(w, err, fmt.Sprintf("ShiftHandler.ClockIn: Failed to clock in to Classroom with ID %s", req.Classroom))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shift)
+}
+
+// ClockOut handles POST requests ending the caller's open shift
+func (h *ShiftHandler) ClockOut(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ShiftHandler.ClockOut: Failed to get user ID from auth", err)
+		return
+	}
+
+	shift, err := h.shiftService.ClockOut(actorID)
+	if err != nil {
+		writeShiftError(w, err, "ShiftHandler.ClockOut: Failed to clock out")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(shift)
+}
+
+// GetRatios handles GET requests for the live staff-to-child ratio of each classroom
+func (h *ShiftHandler) GetRatios(w http.ResponseWriter, r *http.Request) {
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "ShiftHandler.GetRatios: Failed to get user ID from auth", err)
+		return
+	}
+
+	ratios, err := h.ratioService.GetRatios(actorID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError, "ShiftHandler.GetRatios: Failed to compute classroom ratios")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ratios)
+}
+
+// Helper - map shift state errors to 409 and other failures through writeServiceError
+func writeShiftError(w http.ResponseWriter, err error, msg string) {
+	if errors.Is(err, services.ErrAlreadyClockedIn) || errors.Is(err, services.ErrNotClockedIn) {
+		utils.WriteJSONError(w, http.StatusConflict, msg, err)
+		return
+	}
+	writeServiceError(w, err, http.StatusBadRequest, msg)
+}
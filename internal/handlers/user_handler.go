@@ -3,15 +3,17 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"littleeinsteinchildcare/backend/firebase"
 	"littleeinsteinchildcare/backend/internal/common"
 	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
 	"littleeinsteinchildcare/backend/internal/utils"
+	"log"
 	"net/http"
-
-	"cloud.google.com/go/firestore"
+	"strings"
 )
 
 // UserService interface implemented in services package
@@ -19,19 +21,34 @@ type UserService interface {
 	CreateUser(user models.User) error
 	GetUserByID(id string) (models.User, error)
 	GetAllUsers() ([]models.User, error)
-	DeleteUserByID(id string) error
-	UpdateUser(user models.User) (models.User, error)
+	DeleteUserByID(actorID string, id string) error
+	UpdateUser(actorID string, user models.User) (models.User, error)
+}
+
+// RoleService interface implemented in services package
+type RoleService interface {
+	ChangeRole(ctx context.Context, userID string, role models.Role, classrooms []string, changedBy string) (models.User, error)
+	ApplyClaims(ctx context.Context, userID string, role models.Role) error
+	GetRoleHistory(userID string) ([]models.RoleChange, error)
 }
 
 // UserHandler handles HTTP requests related to users
 type UserHandler struct {
-	userService UserService
+	userService    UserService
+	inviteService  InviteService
+	roleService    RoleService
+	childService   ChildService
+	messageService MessageService
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(s UserService) *UserHandler {
+func NewUserHandler(s UserService, is InviteService, rs RoleService, cs ChildService, ms MessageService) *UserHandler {
 	return &UserHandler{
-		userService: s,
+		userService:    s,
+		inviteService:  is,
+		roleService:    rs,
+		childService:   cs,
+		messageService: ms,
 	}
 }
 
@@ -46,7 +63,14 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := buildUserResponse(user)
+	actorID, _ := utils.GetUserIDFromAuth(r)
+	// Linked children are only shown to the family themselves and staff allowed to see each child
+	linked := h.visibleChildren(actorID, map[string][]models.Child{user.ID: h.linkedChildren(user.ID)})
+	response := buildUserResponse(user, linked[user.ID])
+	// Unread messages are private, only shown to the user themselves
+	if actorID == user.ID {
+		response["UnreadMessages"] = h.unreadMessages(user.ID)
+	}
 
 	// Return JSON response
 	w.WriteHeader(http.StatusOK)
@@ -60,11 +84,19 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 		utils.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("UserHandler.GetAllUser: Failed to retrieve list of users"), err)
 	}
 
+	// Load every guardian link once instead of once per user
+	childrenByGuardian, err := h.childService.GetChildrenByGuardians()
+	if err != nil {
+		log.Printf("UserHandler.GetAllUsers: Failed to load linked children: %v", err)
+	}
+
+	actorID, _ := utils.GetUserIDFromAuth(r)
+	childrenByGuardian = h.visibleChildren(actorID, childrenByGuardian)
 	var responses []map[string]interface{}
 	// For idx, value
 	for _, user := range users {
 		// Create response with a list of user data
-		resp := buildUserResponse(user)
+		resp := buildUserResponse(user, childrenByGuardian[user.ID])
 		responses = append(responses, resp)
 	}
 
@@ -76,6 +108,12 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 // UpdateUser handles PUT requests for a specific user
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "UserHandler.UpdateUser: Failed to get user ID from auth", err)
+		return
+	}
+
 	newData, err := io.ReadAll(r.Body)
 
 	if err != nil {
@@ -91,23 +129,95 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err = h.userService.UpdateUser(user)
+	user, err = h.userService.UpdateUser(actorID, user)
+	if errors.Is(err, services.ErrForbidden) {
+		utils.WriteJSONError(w, http.StatusForbidden, "UserHandler.UpdateUser: Not allowed to update this user", err)
+		return
+	}
 	if err != nil {
 		utils.WriteJSONError(w, http.StatusNotFound, "UserHandler.UpdateUser: User does not exist", err)
 		return
 	}
-	response := buildUserResponse(user)
+	response := buildUserResponse(user, h.linkedChildren(user.ID))
 
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// UpdateUserRole handles admin-only PUT requests that change a user's role and custom claims
+func (h *UserHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	adminID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "UserHandler.UpdateUserRole: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		Role       string   `json:"role"`
+		Classrooms []string `json:"classrooms"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Role == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "UserHandler.UpdateUserRole: Missing or invalid role", err)
+		return
+	}
+	role, err := models.ParseRole(req.Role)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("UserHandler.UpdateUserRole: %v", err), err)
+		return
+	}
+
+	user, err := h.roleService.ChangeRole(r.Context(), id, role, req.Classrooms, adminID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSelfDemotion):
+			utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("UserHandler.UpdateUserRole: %v", err), err)
+		case user.ID != "":
+			utils.WriteJSONError(w, http.StatusBadGateway, "UserHandler.UpdateUserRole: Role saved but Firebase claims could not be updated", err)
+		default:
+			utils.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("UserHandler.UpdateUserRole: Failed to update role for User with ID %s", id), err)
+		}
+		return
+	}
+
+	response := buildUserResponse(user, h.linkedChildren(user.ID))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetUserRoleHistory handles admin-only GET requests for the role change audit trail of a user
+func (h *UserHandler) GetUserRoleHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	changes, err := h.roleService.GetRoleHistory(id)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, fmt.Sprintf("UserHandler.GetUserRoleHistory: Failed to retrieve role history for User with ID %s", id), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(changes)
+}
+
 // DeleteUser handles DELETE requests to remove an existing user
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
-	err := h.userService.DeleteUserByID(id)
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "UserHandler.DeleteUser: Failed to get user ID from auth", err)
+		return
+	}
+
+	err = h.userService.DeleteUserByID(actorID, id)
+	if errors.Is(err, services.ErrForbidden) {
+		utils.WriteJSONError(w, http.StatusForbidden, fmt.Sprintf("UserHandler.DeleteUser: Not allowed to delete User with ID %s", id), err)
+		return
+	}
 	if err != nil {
 		utils.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("UserHandler.DeleteUser: Failed to delete User with ID %s", id), err)
 		return
@@ -147,43 +257,28 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to fetch user info from Firebase", http.StatusInternalServerError)
 		return
 	}
-	fsClient, err := firebase.Firestore(ctx)
-	if err != nil {
-		http.Error(w, "Failed to initialize Firestore client", http.StatusInternalServerError)
+	// The invite is claimed before the account exists and only a successful claim grants its role,
+	// a sign up that loses the race or comes later with the same email is a parent
+	role := models.RoleParent
+	invite, claimed, err := h.claimInvite(uid, email)
+	if errors.Is(err, services.ErrInviteUsed) {
+		// Already signed up, skip user creation
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "User already created",
+		})
 		return
 	}
-	defer fsClient.Close()
-
-	role := "user" // default
-	isAdmin := false
-
-	docSnap, err := fsClient.Collection("invitedUsers").Doc(email).Get(ctx)
-	if err == nil {
-		if val, ok := docSnap.Data()["admin"].(bool); ok && val {
-			role = "admin"
-			isAdmin = true
-		}
-
-		if signedUp, ok := docSnap.Data()["signedUp"].(bool); ok && signedUp {
-			// Already signed up, skip user creation
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(map[string]string{
-				"message": "User already created",
-			})
-			return
-		}
-
+	if err != nil && !errors.Is(err, services.ErrInviteNotFound) {
+		// The invite may still grant a role, the client retries rather than signing up as a parent
+		utils.WriteJSONError(w, http.StatusServiceUnavailable, "UserHandler.CreateUser: Failed to check invite", err)
+		return
 	}
-
-	if isAdmin {
-		go func(email string) {
-			if err := firebase.SetAdminClaimForEmail(context.Background(), firebase.Init(), email); err != nil {
-				fmt.Printf("Failed to set admin claim for %s: %v\n", email, err)
-			}
-		}(email)
-
+	if claimed {
+		role = invite.Role
 	}
+
 	// Construct user model
 	user := models.User{
 		ID:    uid,
@@ -194,6 +289,9 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 	// Store user in DB
 	if err := h.userService.CreateUser(user); err != nil {
+		if claimed {
+			h.releaseInvite(uid, invite)
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusConflict)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -202,15 +300,13 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	_, err = fsClient.Collection("invitedUsers").Doc(email).Update(ctx, []firestore.Update{
-		{Path: "signedUp", Value: true},
-	})
-	if err != nil {
-		fmt.Printf("Warning: failed to update signedUp flag in Firestore: %v\n", err)
+	if claimed {
+		h.applyInvitedRole(ctx, user.ID, invite)
+		h.linkInvitedChildren(user.ID, email, invite)
 	}
 
 	// On success
-	response := buildUserResponse(user)
+	response := buildUserResponse(user, h.linkedChildren(user.ID))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
@@ -218,28 +314,35 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 // SyncFirebaseUser handles POST requests to sync Firebase users with backend database
 func (h *UserHandler) SyncFirebaseUser(w http.ResponseWriter, r *http.Request) {
-	userData, err := utils.DecodeJSONRequest(r)
-	if err != nil {
-		utils.WriteJSONError(w, http.StatusBadRequest, "UserHandler.SyncFirebaseUser: Failed to decode JSON request", err)
+	ctx := r.Context()
+
+	// Identity comes from the verified token, never the body, since the invite for the email decides the role
+	uid, ok := utils.GetContextString(ctx, common.ContextUID)
+	if !ok || uid == "" {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "UserHandler.SyncFirebaseUser: Missing UID in context", nil)
+		return
+	}
+	email, ok := utils.GetContextString(ctx, common.ContextEmail)
+	if !ok || email == "" {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "UserHandler.SyncFirebaseUser: Missing Email in context", nil)
 		return
 	}
 
-	// Extract Firebase user data
-	uid, ok := userData["uid"].(string)
-	if !ok {
-		utils.WriteJSONError(w, http.StatusBadRequest, "UserHandler.SyncFirebaseUser: Missing or invalid uid", nil)
+	userData, err := utils.DecodeJSONRequest(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "UserHandler.SyncFirebaseUser: Failed to decode JSON request", err)
 		return
 	}
 
-	email, ok := userData["email"].(string)
-	if !ok {
-		utils.WriteJSONError(w, http.StatusBadRequest, "UserHandler.SyncFirebaseUser: Missing or invalid email", nil)
+	// Older clients still send the uid, it must be the caller's own
+	if bodyUID, ok := userData["uid"].(string); ok && bodyUID != "" && bodyUID != uid {
+		utils.WriteJSONError(w, http.StatusForbidden, "UserHandler.SyncFirebaseUser: uid does not match the authenticated user", nil)
 		return
 	}
 
 	name, ok := userData["name"].(string)
 	if !ok {
-		name = userData["displayName"].(string) // Fallback to displayName
+		name, _ = userData["displayName"].(string) // Fallback to displayName
 		if name == "" {
 			name = "User" // Default name
 		}
@@ -249,41 +352,144 @@ func (h *UserHandler) SyncFirebaseUser(w http.ResponseWriter, r *http.Request) {
 	existingUser, err := h.userService.GetUserByID(uid)
 	if err == nil {
 		// User exists, return existing user
-		response := buildUserResponse(existingUser)
+		response := buildUserResponse(existingUser, h.linkedChildren(existingUser.ID))
+		response["UnreadMessages"] = h.unreadMessages(existingUser.ID)
 		w.WriteHeader(http.StatusOK)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(response)
 		return
 	}
 
-	// User doesn't exist, create new user
+	// User doesn't exist, create new user with the role granted by their invite when this sign up claims it
+	role := models.RoleParent // Default role for Firebase users
+	// An invite another sign up already claimed leaves this one a parent
+	invite, claimed, err := h.claimInvite(uid, email)
+	if err != nil && !errors.Is(err, services.ErrInviteNotFound) && !errors.Is(err, services.ErrInviteUsed) {
+		// The invite may still grant a role, the client retries rather than signing up as a parent
+		utils.WriteJSONError(w, http.StatusServiceUnavailable, "UserHandler.SyncFirebaseUser: Failed to check invite", err)
+		return
+	}
+	if claimed {
+		role = invite.Role
+	}
 	user := models.User{
 		ID:    uid,
 		Name:  name,
 		Email: email,
-		Role:  "parent", // Default role for Firebase users
+		Role:  role,
 	}
 
 	err = h.userService.CreateUser(user)
 	if err != nil {
+		if claimed {
+			h.releaseInvite(uid, invite)
+		}
 		utils.WriteJSONError(w, http.StatusConflict, "UserHandler.SyncFirebaseUser: Failed to create user", err)
 		return
 	}
+	if claimed {
+		h.applyInvitedRole(ctx, user.ID, invite)
+		h.linkInvitedChildren(user.ID, email, invite)
+	}
 
-	response := buildUserResponse(user)
+	response := buildUserResponse(user, h.linkedChildren(user.ID))
 	w.WriteHeader(http.StatusCreated)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// Helper - narrow linked children to the ones the requesting user may see, showing only their own when that fails
+func (h *UserHandler) visibleChildren(actorID string, byGuardian map[string][]models.Child) map[string][]models.Child {
+	visible, err := h.childService.VisibleLinkedChildren(actorID, byGuardian)
+	if err != nil {
+		log.Printf("UserHandler: Failed to check which linked children %s may see: %v", actorID, err)
+	}
+	return visible
+}
+
+// Helper - look up the children linked to a guardian, logging rather than failing the user request
+func (h *UserHandler) linkedChildren(userID string) []models.Child {
+	children, err := h.childService.GetChildrenByGuardian(userID)
+	if err != nil {
+		log.Printf("UserHandler: Failed to load children linked to %s: %v", userID, err)
+	}
+	return children
+}
+
+// Helper - count a user's unread messages, logging failures rather than failing the user response
+func (h *UserHandler) unreadMessages(userID string) int {
+	unread, err := h.messageService.UnreadCount(userID)
+	if err != nil {
+		log.Printf("UserHandler: Failed to count unread messages for %s: %v", userID, err)
+		return 0
+	}
+	return unread
+}
+
+// Helper - mark the invite for the verified token email used, reporting whether this sign up claimed it.
+// Missing invites are expected, other failures are logged and returned for the caller to handle
+func (h *UserHandler) claimInvite(userID string, email string) (models.Invite, bool, error) {
+	invite, err := h.inviteService.ClaimInvite(email)
+	if err != nil {
+		if !errors.Is(err, services.ErrInviteNotFound) {
+			log.Printf("UserHandler: Failed to claim invite for %s: %v", userID, err)
+		}
+		return models.Invite{}, false, err
+	}
+	return invite, true, nil
+}
+
+// Helper - hand a claimed invite back when the account could not be created, logging rather than failing
+func (h *UserHandler) releaseInvite(userID string, invite models.Invite) {
+	if err := h.inviteService.ReleaseInvite(invite); err != nil {
+		log.Printf("UserHandler: Failed to release invite claimed by %s: %v", userID, err)
+	}
+}
+
+// Helper - apply the claims for a role granted by an invite, logging rather than failing sign up since
+// the claims reconciler applies stored roles it finds missing
+func (h *UserHandler) applyInvitedRole(ctx context.Context, userID string, invite models.Invite) {
+	if !invite.IsAdmin() {
+		return
+	}
+	if err := h.roleService.ApplyClaims(ctx, userID, invite.Role); err != nil {
+		log.Printf("UserHandler: Failed to apply invited role for %s: %v", userID, err)
+	}
+}
+
+// Helper - link a new account to the children on the invite it claimed, logging rather than failing sign up
+func (h *UserHandler) linkInvitedChildren(userID string, email string, invite models.Invite) {
+	if len(invite.ChildIDs) == 0 || !strings.EqualFold(invite.Email, email) {
+		return
+	}
+	if err := h.childService.LinkInvitedGuardian(userID, invite.ChildIDs); err != nil {
+		log.Printf("UserHandler: Failed to link %s to invited children: %v", userID, err)
+	}
+}
+
 // Helper - build response object from User data
-func buildUserResponse(user models.User) map[string]interface{} {
+func buildUserResponse(user models.User, children []models.Child) map[string]interface{} {
+	linked := []map[string]interface{}{}
+	for _, child := range children {
+		entry := map[string]interface{}{
+			"id":        child.ID,
+			"name":      child.Name,
+			"classroom": child.Classroom,
+		}
+		if len(child.Guardians) > 0 {
+			entry["relationship"] = child.Guardians[0].Relationship
+		}
+		linked = append(linked, entry)
+	}
+
 	response := map[string]interface{}{
-		"ID":       user.ID,
-		"Username": user.Name,
-		"Email":    user.Email,
-		"Role":     user.Role,
-		"Images":   user.Images,
+		"ID":         user.ID,
+		"Username":   user.Name,
+		"Email":      user.Email,
+		"Role":       user.Role,
+		"Images":     user.Images,
+		"Classrooms": user.Classrooms,
+		"Children":   linked,
 	}
 	return response
 }
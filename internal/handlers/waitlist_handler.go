@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"littleeinsteinchildcare/backend/internal/utils"
+	"net/http"
+)
+
+// WaitlistService interface implemented in services package
+type WaitlistService interface {
+	SubmitApplication(application models.Application) (models.Application, error)
+	GetWaitlist(ageGroup string, status models.ApplicationStatus) ([]models.Application, error)
+	GetApplication(id string) (models.Application, error)
+	UpdateApplication(newData models.Application) (models.Application, error)
+	TransitionApplication(id string, status models.ApplicationStatus) (models.Application, error)
+	EnrollApplication(actorID string, id string, childID string, classroom string) (models.Application, models.Child, error)
+}
+
+// WaitlistHandler handles HTTP requests for enrollment applications and the admin waitlist
+type WaitlistHandler struct {
+	waitlistService WaitlistService
+}
+
+// NewWaitlistHandler creates a new waitlist handler
+func NewWaitlistHandler(s WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{
+		waitlistService: s,
+	}
+}
+
+// applicationRequest is the JSON body of the public application form.
+// Website is a honeypot field hidden from people, only bots fill it in
+type applicationRequest struct {
+	ParentName     string `json:"parentName"`
+	Email          string `json:"email"`
+	Phone          string `json:"phone"`
+	ChildName      string `json:"childName"`
+	ChildBirthdate string `json:"childBirthdate"`
+	DesiredStart   string `json:"desiredStart"`
+	Notes          string `json:"notes"`
+	Website        string `json:"website"`
+}
+
+// SubmitApplication handles POST requests from the public enrollment form
+func (h *WaitlistHandler) SubmitApplication(w http.ResponseWriter, r *http.Request) {
+	var req applicationRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 16<<10)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "WaitlistHandler.SubmitApplication: Failed to decode JSON request", err)
+		return
+	}
+	// Accept spam without storing it so bots don't learn they were caught
+	if req.Website != "" {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	application, err := models.NewApplication(utils.NewID(), req.ParentName, req.Email, req.Phone, req.ChildName, req.ChildBirthdate, req.DesiredStart, req.Notes)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "WaitlistHandler.SubmitApplication: Invalid application", err)
+		return
+	}
+	if _, err := h.waitlistService.SubmitApplication(*application); err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "WaitlistHandler.SubmitApplication: Failed to store application", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"id": application.ID, "status": string(application.Status)})
+}
+
+// GetWaitlist handles GET requests for the waitlist, optionally filtered by ?ageGroup= and ?status=
+func (h *WaitlistHandler) GetWaitlist(w http.ResponseWriter, r *http.Request) {
+	var status models.ApplicationStatus
+	if value := r.URL.Query().Get("status"); value != "" {
+		parsed, err := models.ParseApplicationStatus(value)
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "WaitlistHandler.GetWaitlist: Invalid status", err)
+			return
+		}
+		status = parsed
+	}
+
+	applications, err := h.waitlistService.GetWaitlist(r.URL.Query().Get("ageGroup"), status)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "WaitlistHandler.GetWaitlist: Failed to retrieve waitlist", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(applications)
+}
+
+// GetApplication handles GET requests for a single application
+func (h *WaitlistHandler) GetApplication(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	application, err := h.waitlistService.GetApplication(id)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("WaitlistHandler.GetApplication: Failed to find application with ID %s", id), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(application)
+}
+
+// UpdateApplication handles PUT requests changing an application's rank, desired start date, phone or notes
+func (h *WaitlistHandler) UpdateApplication(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var req struct {
+		Rank         int    `json:"rank"`
+		DesiredStart string `json:"desiredStart"`
+		Phone        string `json:"phone"`
+		Notes        string `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "WaitlistHandler.UpdateApplication: Failed to decode JSON request", err)
+		return
+	}
+
+	newData := models.Application{ID: id, Rank: req.Rank, DesiredStart: req.DesiredStart, Phone: req.Phone, Notes: req.Notes}
+	application, err := h.waitlistService.UpdateApplication(newData)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("WaitlistHandler.UpdateApplication: Failed to update application with ID %s", id), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(application)
+}
+
+// TransitionApplication handles POST requests recording a tour, offer or decline
+func (h *WaitlistHandler) TransitionApplication(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "WaitlistHandler.TransitionApplication: Failed to decode JSON request", err)
+		return
+	}
+	status, err := models.ParseApplicationStatus(req.Status)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "WaitlistHandler.TransitionApplication: Invalid status", err)
+		return
+	}
+
+	application, err := h.waitlistService.TransitionApplication(id, status)
+	if err != nil {
+		writeWaitlistError(w, err, http.StatusNotFound, fmt.Sprintf("WaitlistHandler.TransitionApplication: Failed to move application with ID %s to %s", id, status))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(application)
+}
+
+// EnrollApplication handles POST requests converting an offered application into a child record and guardian invite
+func (h *WaitlistHandler) EnrollApplication(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	actorID, err := utils.GetUserIDFromAuth(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "WaitlistHandler.EnrollApplication: Failed to get user ID from auth", err)
+		return
+	}
+
+	var req struct {
+		Classroom string `json:"classroom"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Classroom == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "WaitlistHandler.EnrollApplication: Missing classroom or invalid JSON request", err)
+		return
+	}
+
+	application, child, err := h.waitlistService.EnrollApplication(actorID, id, utils.NewID(), req.Classroom)
+	if err != nil {
+		writeWaitlistError(w, err, http.StatusBadRequest, fmt.Sprintf("WaitlistHandler.EnrollApplication: Failed to enroll application with ID %s", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{"application": application, "child": child})
+}
+
+// Helper - map workflow errors to 409, forbidden errors to 403 and everything else to the given status
+func writeWaitlistError(w http.ResponseWriter, err error, status int, msg string) {
+	if errors.Is(err, services.ErrApplicationState) {
+		utils.WriteJSONError(w, http.StatusConflict, msg, err)
+		return
+	}
+	writeServiceError(w, err, status, msg)
+}
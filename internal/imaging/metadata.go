@@ -0,0 +1,367 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+)
+
+// JPEG quality used when a photo has to be re-encoded to apply its orientation
+const orientedJPEGQuality = 92
+
+// EXIF orientation tag in IFD0
+const exifOrientationTag = 0x0112
+
+var (
+	jpegSignature = []byte{0xFF, 0xD8}
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader    = []byte("Exif\x00\x00")
+	mpfHeader     = []byte("MPF\x00")
+)
+
+// PNG chunks that carry EXIF, free text or timestamps rather than pixels
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// Sanitize copies an image from src to dst without its EXIF, XMP, IPTC and comment metadata (GPS coordinates,
+// camera serials, timestamps). A JPEG whose EXIF orientation is not upright is re-encoded right way up so it still
+// displays correctly once the orientation tag is gone, from upright when the caller already decoded src with
+// DecodeUpright and by decoding it here when upright is nil; everything else is copied a segment at a time so the
+// file is never held in memory. JPEGs end at their end of image marker, dropping the extra pictures (each with its
+// own EXIF) that phones append. Formats other than JPEG and PNG are copied unchanged with sanitized false
+func Sanitize(dst io.Writer, src io.Reader, upright image.Image) (sanitized bool, err error) {
+	reader := bufio.NewReader(src)
+	head, _ := reader.Peek(len(pngSignature))
+	switch {
+	case bytes.HasPrefix(head, jpegSignature):
+		err = sanitizeJPEG(dst, reader, upright)
+	case bytes.HasPrefix(head, pngSignature):
+		err = stripPNGMetadata(dst, reader)
+	default:
+		if _, err := io.Copy(dst, reader); err != nil {
+			return false, fmt.Errorf("imaging.Sanitize: %w", err)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("imaging.Sanitize: %w", err)
+	}
+	return true, nil
+}
+
+// DecodeUpright decodes a JPEG, PNG or GIF, applies its EXIF orientation and rewinds src. Truncated and corrupted
+// files fail here, so an upload decoded once up front can reuse the pixels for Sanitize and its resized variants
+func DecodeUpright(src io.ReadSeeker) (*image.RGBA, string, error) {
+	img, format, err := Decode(src)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, "", fmt.Errorf("imaging.DecodeUpright: Failed to rewind image: %w", err)
+	}
+	upright := Orient(ToRGBA(img), Orientation(src))
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, "", fmt.Errorf("imaging.DecodeUpright: Failed to rewind image: %w", err)
+	}
+	return upright, format, nil
+}
+
+// Orient applies an EXIF orientation (1-8) so the returned image is upright
+func Orient(src *image.RGBA, orientation int) *image.RGBA {
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	// Orientations 5-8 swap width and height
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for sy := 0; sy < height; sy++ {
+		for sx := 0; sx < width; sx++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				dx, dy = width-1-sx, sy
+			case 3: // Rotated 180
+				dx, dy = width-1-sx, height-1-sy
+			case 4: // Mirrored vertically
+				dx, dy = sx, height-1-sy
+			case 5: // Transposed
+				dx, dy = sy, sx
+			case 6: // Rotated 90 clockwise
+				dx, dy = height-1-sy, sx
+			case 7: // Transversed
+				dx, dy = height-1-sy, width-1-sx
+			case 8: // Rotated 90 counter-clockwise
+				dx, dy = sy, width-1-sx
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
+
+// Orientation reads the EXIF orientation (1-8) from a JPEG's header, 1 (upright) for other formats or when there is none.
+// Photos stored before uploads were sanitized may still carry the tag
+func Orientation(src io.Reader) int {
+	reader := bufio.NewReader(src)
+	if head, _ := reader.Peek(len(jpegSignature)); !bytes.HasPrefix(head, jpegSignature) {
+		return 1
+	}
+	segments, err := readJPEGHeader(reader)
+	if err != nil {
+		return 1
+	}
+	return jpegOrientation(segments)
+}
+
+// jpegSegment is a marker segment from the header of a JPEG, raw holds the marker, length and payload
+type jpegSegment struct {
+	marker byte
+	raw    []byte
+}
+
+// Helper - strip a JPEG's metadata segments, or re-encode it upright when its orientation needs applying
+func sanitizeJPEG(dst io.Writer, src *bufio.Reader, upright image.Image) error {
+	segments, err := readJPEGHeader(src)
+	if err != nil {
+		return err
+	}
+
+	if orientation := jpegOrientation(segments); orientation > 1 && orientation <= 8 {
+		if upright == nil {
+			// The decoder needs the header segments back in front of the scan data; the encoder writes no metadata
+			header := bytes.NewBuffer(append([]byte{}, jpegSignature...))
+			for _, segment := range segments {
+				header.Write(segment.raw)
+			}
+			img, err := jpeg.Decode(io.MultiReader(header, src))
+			if err != nil {
+				return err
+			}
+			upright = Orient(ToRGBA(img), orientation)
+		}
+		return jpeg.Encode(dst, upright, &jpeg.Options{Quality: orientedJPEGQuality})
+	}
+
+	if _, err := dst.Write(jpegSignature); err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if len(segment.raw) > 4 && isJPEGMetadata(segment.marker, segment.raw[4:]) {
+			continue
+		}
+		if _, err := dst.Write(segment.raw); err != nil {
+			return err
+		}
+	}
+	return copyJPEGScans(dst, src)
+}
+
+// Helper - report whether a JPEG segment only carries metadata. JFIF (APP0), ICC colour profiles (APP2) and Adobe
+// colour transforms (APP14) are kept since decoders need them, the multi-picture index (also APP2) is not
+func isJPEGMetadata(marker byte, payload []byte) bool {
+	switch {
+	case marker == 0xFE:
+		return true
+	case marker == 0xE2:
+		return bytes.HasPrefix(payload, mpfHeader)
+	case marker == 0xEE:
+		return false
+	}
+	return marker >= 0xE1 && marker <= 0xEF
+}
+
+// Helper - copy the entropy coded scans that follow the first start of scan, up to and including the end of image
+// marker. Progressive JPEGs interleave table and scan segments, which are copied whole, while metadata segments are
+// dropped. Anything after the end of image, such as the extra pictures and their EXIF that phones append, is discarded
+func copyJPEGScans(dst io.Writer, src *bufio.Reader) error {
+	out := bufio.NewWriter(dst)
+	for {
+		b, err := src.ReadByte()
+		if err == io.EOF {
+			// Truncated after the image data, decoders show what is there
+			return out.Flush()
+		}
+		if err != nil {
+			return err
+		}
+		if b != 0xFF {
+			out.WriteByte(b)
+			continue
+		}
+
+		// Markers may be padded with any number of 0xFF fill bytes
+		marker := byte(0xFF)
+		for marker == 0xFF {
+			if marker, err = src.ReadByte(); err != nil {
+				return errors.New("truncated JPEG marker")
+			}
+		}
+		switch {
+		case marker == 0x00 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// Stuffed 0xFF data byte, or a standalone or restart marker inside the scan
+			out.Write([]byte{0xFF, marker})
+			continue
+		case marker == 0xD9:
+			out.Write([]byte{0xFF, marker})
+			return out.Flush()
+		}
+
+		lengthBytes := make([]byte, 2)
+		if _, err := io.ReadFull(src, lengthBytes); err != nil {
+			return errors.New("truncated JPEG segment")
+		}
+		length := int(binary.BigEndian.Uint16(lengthBytes))
+		if length < 2 {
+			return errors.New("invalid JPEG segment length")
+		}
+		payload := make([]byte, length-2)
+		if _, err := io.ReadFull(src, payload); err != nil {
+			return errors.New("truncated JPEG segment")
+		}
+		if isJPEGMetadata(marker, payload) {
+			continue
+		}
+		out.Write([]byte{0xFF, marker})
+		out.Write(lengthBytes)
+		out.Write(payload)
+	}
+}
+
+// Helper - read a JPEG's segments up to and including the start of scan, leaving src at the image data
+func readJPEGHeader(src *bufio.Reader) ([]jpegSegment, error) {
+	if _, err := src.Discard(len(jpegSignature)); err != nil {
+		return nil, err
+	}
+
+	segments := []jpegSegment{}
+	for {
+		prefix, err := src.ReadByte()
+		if err != nil {
+			return nil, errors.New("JPEG has no image data")
+		}
+		if prefix != 0xFF {
+			return nil, errors.New("malformed JPEG segment")
+		}
+		// Markers may be padded with any number of 0xFF fill bytes
+		marker := byte(0xFF)
+		for marker == 0xFF {
+			if marker, err = src.ReadByte(); err != nil {
+				return nil, errors.New("truncated JPEG marker")
+			}
+		}
+
+		// Standalone markers have no length
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			segments = append(segments, jpegSegment{marker: marker, raw: []byte{0xFF, marker}})
+			continue
+		}
+		if marker == 0xD9 {
+			return nil, errors.New("JPEG has no image data")
+		}
+
+		lengthBytes := make([]byte, 2)
+		if _, err := io.ReadFull(src, lengthBytes); err != nil {
+			return nil, errors.New("truncated JPEG segment")
+		}
+		length := int(binary.BigEndian.Uint16(lengthBytes))
+		if length < 2 {
+			return nil, errors.New("invalid JPEG segment length")
+		}
+		raw := make([]byte, 2+length)
+		raw[0], raw[1] = 0xFF, marker
+		copy(raw[2:], lengthBytes)
+		if _, err := io.ReadFull(src, raw[4:]); err != nil {
+			return nil, errors.New("truncated JPEG segment")
+		}
+		segments = append(segments, jpegSegment{marker: marker, raw: raw})
+
+		if marker == 0xDA {
+			return segments, nil
+		}
+	}
+}
+
+// Helper - copy a PNG a chunk at a time, skipping its text, EXIF and timestamp chunks
+func stripPNGMetadata(dst io.Writer, src io.Reader) error {
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(src, signature); err != nil {
+		return err
+	}
+	if _, err := dst.Write(signature); err != nil {
+		return err
+	}
+
+	// Each chunk is a 4 byte length, 4 byte type, the data and a 4 byte CRC
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(src, header); err != nil {
+			return errors.New("PNG has no end chunk")
+		}
+		remaining := int64(binary.BigEndian.Uint32(header)) + 4
+		chunkType := string(header[4:])
+
+		var err error
+		if pngMetadataChunks[chunkType] {
+			_, err = io.CopyN(io.Discard, src, remaining)
+		} else if _, err = dst.Write(header); err == nil {
+			_, err = io.CopyN(dst, src, remaining)
+		}
+		if err != nil {
+			return fmt.Errorf("truncated PNG chunk %s: %w", chunkType, err)
+		}
+		if chunkType == "IEND" {
+			return nil
+		}
+	}
+}
+
+// Helper - read the orientation tag from a JPEG's EXIF segment, 1 (upright) when there is none
+func jpegOrientation(segments []jpegSegment) int {
+	for _, segment := range segments {
+		if segment.marker == 0xE1 && bytes.HasPrefix(segment.raw[4:], exifHeader) {
+			return tiffOrientation(segment.raw[4+len(exifHeader):])
+		}
+	}
+	return 1
+}
+
+// Helper - find the orientation entry in the first IFD of an EXIF TIFF block
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 1
+}
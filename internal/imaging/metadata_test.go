@@ -0,0 +1,252 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+)
+
+// Helper - encode a solid JPEG of the given size
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("Failed to encode JPEG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// Helper - encode a solid PNG of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: 0x40, G: 0x80, B: 0xC0, A: 0xFF})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// Helper - build a JPEG marker segment with its length
+func jpegMarkerSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// Helper - an APP1 EXIF segment holding an orientation tag and a GPS marker string
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2A\x00\x00\x00\x08")
+	tiff = append(tiff, 0x00, 0x01) // One IFD entry
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], exifOrientationTag)
+	binary.BigEndian.PutUint16(entry[2:], 3) // SHORT
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, []byte("GPS 51.5N 0.1W")...)
+	return jpegMarkerSegment(0xE1, append(append([]byte{}, exifHeader...), tiff...))
+}
+
+// Helper - insert segments straight after a JPEG's start of image marker
+func withSegments(jpegData []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, jpegData[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+	return append(out, jpegData[2:]...)
+}
+
+// Helper - insert a chunk straight after a PNG's IHDR chunk
+func withPNGChunk(pngData []byte, chunkType string, data []byte) []byte {
+	ihdrEnd := len(pngSignature) + 8 + 13 + 4
+	chunk := make([]byte, 4, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(chunk[4:]))
+	chunk = append(chunk, crc...)
+
+	out := append([]byte{}, pngData[:ihdrEnd]...)
+	out = append(out, chunk...)
+	return append(out, pngData[ihdrEnd:]...)
+}
+
+func TestSanitize(t *testing.T) {
+	plain := testJPEG(t, 20, 10)
+	iccSegment := jpegMarkerSegment(0xE2, []byte("ICC_PROFILE\x00\x01\x01colour"))
+
+	tests := []struct {
+		name          string
+		input         []byte
+		upright       image.Image // Passed to Sanitize as the already decoded pixels
+		wantSanitized bool
+		wantErr       bool
+		wantSize      image.Point // Zero when the output is not decoded
+		mustContain   []string
+		mustNotHave   []string
+	}{
+		{
+			name:          "upright JPEG loses its EXIF and comment",
+			input:         withSegments(plain, exifSegment(1), jpegMarkerSegment(0xFE, []byte("taken at home"))),
+			wantSanitized: true,
+			wantSize:      image.Pt(20, 10),
+			mustNotHave:   []string{"Exif", "GPS", "taken at home"},
+		},
+		{
+			name:          "rotated JPEG is re-encoded upright",
+			input:         withSegments(plain, exifSegment(6)),
+			wantSanitized: true,
+			wantSize:      image.Pt(10, 20),
+			mustNotHave:   []string{"Exif", "GPS"},
+		},
+		{
+			name:          "rotated JPEG is re-encoded from pixels already decoded",
+			input:         withSegments(plain, exifSegment(6)),
+			upright:       image.NewRGBA(image.Rect(0, 0, 6, 12)),
+			wantSanitized: true,
+			wantSize:      image.Pt(6, 12),
+			mustNotHave:   []string{"Exif", "GPS"},
+		},
+		{
+			name:          "colour profile is kept and the multi-picture index is dropped",
+			input:         withSegments(plain, iccSegment, jpegMarkerSegment(0xE2, []byte("MPF\x00index"))),
+			wantSanitized: true,
+			wantSize:      image.Pt(20, 10),
+			mustContain:   []string{"ICC_PROFILE"},
+			mustNotHave:   []string{"MPF"},
+		},
+		{
+			name:          "pictures appended after the end of image are dropped",
+			input:         append(append([]byte{}, plain...), withSegments(plain, exifSegment(1))...),
+			wantSanitized: true,
+			wantSize:      image.Pt(20, 10),
+			mustNotHave:   []string{"Exif", "GPS"},
+		},
+		{
+			name:          "PNG loses its text chunk",
+			input:         withPNGChunk(testPNG(t, 8, 4), "tEXt", []byte("Comment\x00GPS 51.5N 0.1W")),
+			wantSanitized: true,
+			wantSize:      image.Pt(8, 4),
+			mustNotHave:   []string{"tEXt", "GPS"},
+		},
+		{
+			name:          "other formats are copied unchanged",
+			input:         []byte("GIF89a not really a gif"),
+			wantSanitized: false,
+			mustContain:   []string{"GIF89a not really a gif"},
+		},
+		{
+			name:    "JPEG truncated inside its header",
+			input:   withSegments(plain, exifSegment(1))[:30],
+			wantErr: true,
+		},
+		{
+			name:    "JPEG with no image data",
+			input:   []byte{0xFF, 0xD8, 0xFF, 0xD9},
+			wantErr: true,
+		},
+		{
+			name:    "PNG truncated before its end chunk",
+			input:   testPNG(t, 8, 4)[:40],
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			sanitized, err := Sanitize(&out, bytes.NewReader(tt.input), tt.upright)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Sanitize() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sanitize() failed: %v", err)
+			}
+			if sanitized != tt.wantSanitized {
+				t.Errorf("Sanitize() sanitized = %v, want %v", sanitized, tt.wantSanitized)
+			}
+			for _, text := range tt.mustContain {
+				if !bytes.Contains(out.Bytes(), []byte(text)) {
+					t.Errorf("Sanitize() output is missing %q", text)
+				}
+			}
+			for _, text := range tt.mustNotHave {
+				if bytes.Contains(out.Bytes(), []byte(text)) {
+					t.Errorf("Sanitize() output still contains %q", text)
+				}
+			}
+			if tt.wantSize == (image.Point{}) {
+				return
+			}
+			img, _, err := image.Decode(bytes.NewReader(out.Bytes()))
+			if err != nil {
+				t.Fatalf("Sanitized image does not decode: %v", err)
+			}
+			if size := img.Bounds().Size(); size != tt.wantSize {
+				t.Errorf("Sanitized image is %v, want %v", size, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestDecodeUpright(t *testing.T) {
+	plain := testJPEG(t, 20, 10)
+
+	tests := []struct {
+		name       string
+		input      []byte
+		wantSize   image.Point
+		wantFormat string
+		wantErr    bool
+	}{
+		{name: "upright JPEG", input: withSegments(plain, exifSegment(1)), wantSize: image.Pt(20, 10), wantFormat: "jpeg"},
+		{name: "rotated JPEG", input: withSegments(plain, exifSegment(6)), wantSize: image.Pt(10, 20), wantFormat: "jpeg"},
+		{name: "PNG", input: testPNG(t, 8, 4), wantSize: image.Pt(8, 4), wantFormat: "png"},
+		{name: "truncated JPEG with a valid header", input: plain[:len(plain)-20], wantErr: true},
+		{name: "truncated PNG with a valid header", input: testPNG(t, 8, 4)[:40], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := bytes.NewReader(tt.input)
+			upright, format, err := DecodeUpright(src)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("DecodeUpright() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeUpright() failed: %v", err)
+			}
+			if size := upright.Bounds().Size(); size != tt.wantSize || format != tt.wantFormat {
+				t.Errorf("DecodeUpright() = %v, %q, want %v, %q", size, format, tt.wantSize, tt.wantFormat)
+			}
+			// The upload is sanitized and stored from the same reader, so it must be rewound
+			if rest, _ := io.ReadAll(src); !bytes.Equal(rest, tt.input) {
+				t.Errorf("DecodeUpright() left the reader %d bytes in", len(tt.input)-len(rest))
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package imaging decodes, resizes and re-encodes uploaded photos using only the standard library
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	// Registered for image.Decode, only the first frame of an animated GIF is used
+	_ "image/gif"
+)
+
+// JPEG quality used for resized variants
+const jpegQuality = 85
+
+// Decode reads a JPEG, PNG or GIF and returns it with its format name
+func Decode(src io.Reader) (image.Image, string, error) {
+	img, format, err := image.Decode(src)
+	if err != nil {
+		return nil, "", fmt.Errorf("imaging.Decode: %w", err)
+	}
+	return img, format, nil
+}
+
+// ToRGBA copies an image into an RGBA buffer with its origin at 0,0
+func ToRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// Fit shrinks an image so its longest edge is at most maxDimension, keeping the aspect ratio.
+// Each output pixel averages the block of source pixels it covers, which keeps downscaled photos smooth.
+// Images already small enough are returned unchanged
+func Fit(src *image.RGBA, maxDimension int) *image.RGBA {
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	if width <= maxDimension && height <= maxDimension {
+		return src
+	}
+	newWidth, newHeight := maxDimension, maxDimension
+	if width >= height {
+		newHeight = max(1, height*maxDimension/width)
+	} else {
+		newWidth = max(1, width*maxDimension/height)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		y0, y1 := y*height/newHeight, max((y+1)*height/newHeight, y*height/newHeight+1)
+		for x := 0; x < newWidth; x++ {
+			x0, x1 := x*width/newWidth, max((x+1)*width/newWidth, x*width/newWidth+1)
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += uint64(row[i])
+					g += uint64(row[i+1])
+					b += uint64(row[i+2])
+					a += uint64(row[i+3])
+					count++
+				}
+			}
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / count)
+			dst.Pix[offset+1] = uint8(g / count)
+			dst.Pix[offset+2] = uint8(b / count)
+			dst.Pix[offset+3] = uint8(a / count)
+		}
+	}
+	return dst
+}
+
+// Encode writes an image as JPEG when the source was a JPEG and as PNG otherwise, so transparency survives
+func Encode(img image.Image, format string) ([]byte, string, error) {
+	var buffer bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buffer, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, "", fmt.Errorf("imaging.Encode: %w", err)
+		}
+		return buffer.Bytes(), "image/jpeg", nil
+	}
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, "", fmt.Errorf("imaging.Encode: %w", err)
+	}
+	return buffer.Bytes(), "image/png", nil
+}
@@ -0,0 +1,66 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrUnsupportedFormat is returned when an upload's contents are not one of the allowed image formats
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	// ErrTooManyPixels is returned when an image's dimensions exceed the allowed pixel count
+	ErrTooManyPixels = errors.New("image dimensions too large")
+)
+
+// AllowedFormats maps each accepted content type to the extension given to stored blobs
+var AllowedFormats = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Validate detects an upload's format from its leading bytes rather than trusting the client, then reads its
+// dimensions from the header so decompression bombs are refused without allocating their pixels. src is rewound
+// afterwards and the detected content type and blob extension are returned
+func Validate(src io.ReadSeeker, maxPixels int) (contentType string, extension string, err error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", "", fmt.Errorf("imaging.Validate: Failed to read image: %w", err)
+	}
+	contentType = http.DetectContentType(head[:n])
+	extension, ok := AllowedFormats[contentType]
+	if !ok {
+		return "", "", fmt.Errorf("imaging.Validate: %w: %s", ErrUnsupportedFormat, contentType)
+	}
+
+	if err := rewind(src); err != nil {
+		return "", "", err
+	}
+	cfg, _, err := image.DecodeConfig(src)
+	if err != nil {
+		return "", "", fmt.Errorf("imaging.Validate: Failed to read image header: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return "", "", errors.New("imaging.Validate: Image has no pixels")
+	}
+	if cfg.Width > maxPixels/cfg.Height {
+		return "", "", fmt.Errorf("imaging.Validate: %w: %dx%d exceeds %d pixels", ErrTooManyPixels, cfg.Width, cfg.Height, maxPixels)
+	}
+
+	if err := rewind(src); err != nil {
+		return "", "", err
+	}
+	return contentType, extension, nil
+}
+
+// Helper - seek back to the start of an upload between passes
+func rewind(src io.Seeker) error {
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("imaging.Validate: Failed to rewind image: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	const maxPixels = 1000
+	jpegData := testJPEG(t, 20, 10)
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, image.NewPaletted(image.Rect(0, 0, 4, 4), []color.Color{color.Black}), nil); err != nil {
+		t.Fatalf("Failed to encode GIF: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		input           []byte
+		wantContentType string
+		wantExtension   string
+		wantErr         error // Matched with errors.Is when set
+		wantAnyErr      bool
+	}{
+		{name: "JPEG", input: jpegData, wantContentType: "image/jpeg", wantExtension: ".jpg"},
+		{name: "PNG", input: testPNG(t, 8, 4), wantContentType: "image/png", wantExtension: ".png"},
+		{name: "GIF", input: gifData.Bytes(), wantContentType: "image/gif", wantExtension: ".gif"},
+		{name: "plain text", input: []byte("definitely not an image"), wantErr: ErrUnsupportedFormat},
+		{name: "HTML", input: []byte("<html><script>alert(1)</script></html>"), wantErr: ErrUnsupportedFormat},
+		{name: "too many pixels", input: testJPEG(t, 50, 50), wantErr: ErrTooManyPixels},
+		{name: "JPEG signature only", input: []byte{0xFF, 0xD8, 0xFF}, wantAnyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := bytes.NewReader(tt.input)
+			contentType, extension, err := Validate(src, maxPixels)
+			if tt.wantErr != nil || tt.wantAnyErr {
+				if err == nil {
+					t.Fatalf("Validate() succeeded, want an error")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() failed: %v", err)
+			}
+			if contentType != tt.wantContentType || extension != tt.wantExtension {
+				t.Errorf("Validate() = %q, %q, want %q, %q", contentType, extension, tt.wantContentType, tt.wantExtension)
+			}
+			// The upload is stored from the same reader, so it must be rewound
+			rest, _ := io.ReadAll(src)
+			if !bytes.Equal(rest, tt.input) {
+				t.Errorf("Validate() left the reader %d bytes in", len(tt.input)-len(rest))
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PhotoVisibility controls which families can see an album photo
+type PhotoVisibility string
+
+const (
+	PhotoTagged    PhotoVisibility = "tagged"    // Only guardians of the children tagged in the photo
+	PhotoClassroom PhotoVisibility = "classroom" // Every family with a child in the album's classroom
+)
+
+// ParsePhotoVisibility validates a visibility supplied by a client, defaulting to tagged
+func ParsePhotoVisibility(value string) (PhotoVisibility, error) {
+	visibility := PhotoVisibility(strings.ToLower(strings.TrimSpace(value)))
+	switch visibility {
+	case "":
+		return PhotoTagged, nil
+	case PhotoTagged, PhotoClassroom:
+		return visibility, nil
+	}
+	return "", fmt.Errorf("invalid photo visibility %q: must be tagged or classroom", value)
+}
+
+// Album groups photos from an event or a classroom. An album without a classroom is center wide
+type Album struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	EventID    string    `json:"eventId,omitempty"`
+	Classroom  string    `json:"classroom,omitempty"`
+	CreatedBy  string    `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+	PhotoCount int       `json:"photoCount"` // Photos the viewing user can see, not stored
+}
+
+// AlbumPhoto is an image in an album with the children appearing in it
+type AlbumPhoto struct {
+	ID          string          `json:"id"`
+	AlbumID     string          `json:"albumId"`
+	BlobName    string          `json:"-"`
+	ContentType string          `json:"contentType"`
+	Caption     string          `json:"caption"`
+	ChildIDs    []string        `json:"childIds"`
+	Visibility  PhotoVisibility `json:"visibility"`
+	UploadedBy  string          `json:"uploadedBy"`
+	UploadedAt  time.Time       `json:"uploadedAt"`
+}
+
+func NewAlbum(id string, title string, eventID string, classroom string, createdBy string, at time.Time) (*Album, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("album title is required")
+	}
+	return &Album{
+		ID:        id,
+		Title:     title,
+		EventID:   eventID,
+		Classroom: classroom,
+		CreatedBy: createdBy,
+		CreatedAt: at,
+	}, nil
+}
+
+func NewAlbumPhoto(id string, albumID string, contentType string, caption string, childIDs []string, visibility PhotoVisibility, uploadedBy string, at time.Time) *AlbumPhoto {
+	return &AlbumPhoto{
+		ID:          id,
+		AlbumID:     albumID,
+		ContentType: contentType,
+		Caption:     strings.TrimSpace(caption),
+		ChildIDs:    uniqueIDs(childIDs),
+		Visibility:  visibility,
+		UploadedBy:  uploadedBy,
+		UploadedAt:  at,
+	}
+}
+
+// HasChild reports whether a child is tagged in the photo
+func (photoModel AlbumPhoto) HasChild(childID string) bool {
+	for _, id := range photoModel.ChildIDs {
+		if id == childID {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper - drop blanks and repeats while keeping order
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// PhotoReview flags a child tagged in a photo without consent for how the photo is shared
+type PhotoReview struct {
+	AlbumID    string       `json:"albumId"`
+	AlbumTitle string       `json:"albumTitle"`
+	PhotoID    string       `json:"photoId"`
+	ChildID    string       `json:"childId"`
+	ChildName  string       `json:"childName"`
+	Consent    MediaConsent `json:"consent"`
+	Required   MediaConsent `json:"required"`
+	Reason     string       `json:"reason"`
+}
+
+// SharingLevel is the consent a tagged child needs before the photo is shown to other families:
+// classroom for classroom albums and public for center wide albums
+func (albumModel Album) SharingLevel() MediaConsent {
+	if albumModel.Classroom == "" {
+		return MediaConsentPublic
+	}
+	return MediaConsentClassroom
+}
+
+// ReviewPhoto returns the tagged children whose consent does not cover the photo, given every child by ID.
+// A photo of a child without any consent always needs review; otherwise it does when it reaches other families,
+// by being shared with the classroom or tagging more than one family's children
+func ReviewPhoto(album Album, photo AlbumPhoto, children map[string]Child) []PhotoReview {
+	reviews := []PhotoReview{}
+	required := album.SharingLevel()
+	sharedWithOthers := photo.Visibility == PhotoClassroom || len(photo.ChildIDs) > 1
+	for _, childID := range photo.ChildIDs {
+		child, ok := children[childID]
+		if !ok {
+			continue
+		}
+		reason := ""
+		switch {
+		case !child.MediaConsent.Allows(MediaConsentInternal):
+			reason = "no media consent"
+		case sharedWithOthers && !child.MediaConsent.Allows(required):
+			reason = fmt.Sprintf("shared with other families but consent is %s", child.MediaConsent)
+		default:
+			continue
+		}
+		reviews = append(reviews, PhotoReview{
+			AlbumID:    album.ID,
+			AlbumTitle: album.Title,
+			PhotoID:    photo.ID,
+			ChildID:    child.ID,
+			ChildName:  child.Name,
+			Consent:    child.MediaConsent,
+			Required:   required,
+			Reason:     reason,
+		})
+	}
+	return reviews
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AllergySeverity is how serious a reaction to an allergen is
+type AllergySeverity string
+
+// Valid allergy severities, unspecified covers allergies recorded before severities were tracked
+const (
+	AllergyUnspecified  AllergySeverity = ""
+	AllergyMild         AllergySeverity = "mild"
+	AllergyModerate     AllergySeverity = "moderate"
+	AllergySevere       AllergySeverity = "severe"
+	AllergyAnaphylactic AllergySeverity = "anaphylactic"
+)
+
+// ParseAllergySeverity validates a severity supplied by a client
+func ParseAllergySeverity(value string) (AllergySeverity, error) {
+	severity := AllergySeverity(strings.ToLower(strings.TrimSpace(value)))
+	switch severity {
+	case AllergyUnspecified, AllergyMild, AllergyModerate, AllergySevere, AllergyAnaphylactic:
+		return severity, nil
+	}
+	return "", fmt.Errorf("invalid allergy severity %q: must be mild, moderate, severe, or anaphylactic", value)
+}
+
+// Allergy is something a child must avoid, with what to watch for and what to do
+type Allergy struct {
+	Allergen  string          `json:"allergen"`
+	Severity  AllergySeverity `json:"severity"`
+	Reaction  string          `json:"reaction"`
+	Treatment string          `json:"treatment"`
+}
+
+// UnmarshalJSON also accepts a bare allergen name, the format allergies were stored and sent in before they were structured
+func (allergyModel *Allergy) UnmarshalJSON(data []byte) error {
+	var allergen string
+	if err := json.Unmarshal(data, &allergen); err == nil {
+		*allergyModel = Allergy{Allergen: allergen}
+		return nil
+	}
+	type plainAllergy Allergy
+	var allergy plainAllergy
+	if err := json.Unmarshal(data, &allergy); err != nil {
+		return err
+	}
+	*allergyModel = Allergy(allergy)
+	return nil
+}
+
+// IsSevere reports whether staff should treat the allergy as life threatening
+func (allergyModel Allergy) IsSevere() bool {
+	return allergyModel.Severity == AllergySevere || allergyModel.Severity == AllergyAnaphylactic
+}
+
+// ValidateAllergies trims and checks each allergy, rejecting blanks and duplicate allergens
+func ValidateAllergies(allergies []Allergy) ([]Allergy, error) {
+	validated := []Allergy{}
+	seen := make(map[string]bool)
+	for _, allergy := range allergies {
+		allergy.Allergen = strings.TrimSpace(allergy.Allergen)
+		if allergy.Allergen == "" {
+			return nil, errors.New("each allergy needs an allergen")
+		}
+		key := strings.ToLower(allergy.Allergen)
+		if seen[key] {
+			return nil, fmt.Errorf("allergen %q is listed more than once", allergy.Allergen)
+		}
+		seen[key] = true
+		severity, err := ParseAllergySeverity(string(allergy.Severity))
+		if err != nil {
+			return nil, err
+		}
+		allergy.Severity = severity
+		validated = append(validated, allergy)
+	}
+	return validated, nil
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// AttendanceDateFormat is the layout of attendance dates, which are also the table partition keys
+const AttendanceDateFormat = "2006-01-02"
+
+// AttendanceRecord is one visit by a child, from drop-off to pickup
+type AttendanceRecord struct {
+	ID              string    `json:"id"`
+	ChildID         string    `json:"childId"`
+	Classroom       string    `json:"classroom"`
+	Date            string    `json:"date"` // YYYY-MM-DD
+	DroppedOffBy    string    `json:"droppedOffBy"`
+	CheckInAt       time.Time `json:"checkInAt"`
+	CheckInStaffID  string    `json:"checkInStaffId"`
+	PickedUpBy      string    `json:"pickedUpBy,omitempty"`
+	CheckOutAt      time.Time `json:"checkOutAt,omitempty"`
+	CheckOutStaffID string    `json:"checkOutStaffId,omitempty"`
+	ETag            string    `json:"-"` // Version of the stored row, so a visit is checked out by one request only
+}
+
+// AttendanceRosterEntry is an enrolled child with today's visits, latest last
+type AttendanceRosterEntry struct {
+	Child   Child              `json:"child"`
+	Present bool               `json:"present"`
+	Visits  []AttendanceRecord `json:"visits"`
+}
+
+// AttendanceRoster shows who is present in a classroom on a given day
+type AttendanceRoster struct {
+	Classroom    string                  `json:"classroom"`
+	Date         string                  `json:"date"`
+	PresentCount int                     `json:"presentCount"`
+	Children     []AttendanceRosterEntry `json:"children"`
+}
+
+func NewAttendanceRecord(id string, child Child, droppedOffBy string, staffID string, at time.Time) (*AttendanceRecord, error) {
+	if droppedOffBy == "" {
+		return nil, errors.New("the adult dropping off is required")
+	}
+	return &AttendanceRecord{
+		ID:             id,
+		ChildID:        child.ID,
+		Classroom:      child.Classroom,
+		Date:           at.Format(AttendanceDateFormat),
+		DroppedOffBy:   droppedOffBy,
+		CheckInAt:      at,
+		CheckInStaffID: staffID,
+	}, nil
+}
+
+// IsCheckedOut reports whether the child has been picked up for this visit
+func (record AttendanceRecord) IsCheckedOut() bool {
+	return !record.CheckOutAt.IsZero()
+}
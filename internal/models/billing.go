@@ -0,0 +1,207 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BillingMonthFormat is the layout of the calendar month an invoice covers
+const BillingMonthFormat = "2006-01"
+
+// BillingFrequency is how often a tuition rate is charged
+type BillingFrequency string
+
+const (
+	BillingWeekly  BillingFrequency = "weekly"
+	BillingMonthly BillingFrequency = "monthly"
+)
+
+// ParseBillingFrequency validates a frequency supplied by a client
+func ParseBillingFrequency(value string) (BillingFrequency, error) {
+	switch BillingFrequency(strings.ToLower(strings.TrimSpace(value))) {
+	case BillingWeekly:
+		return BillingWeekly, nil
+	case BillingMonthly:
+		return BillingMonthly, nil
+	}
+	return "", fmt.Errorf("invalid billing frequency %q: must be weekly or monthly", value)
+}
+
+// InvoiceStatus is where an invoice is in its lifecycle
+type InvoiceStatus string
+
+const (
+	InvoiceOpen InvoiceStatus = "open"
+	InvoicePaid InvoiceStatus = "paid"
+	InvoiceVoid InvoiceStatus = "void"
+)
+
+// LineItemKind groups invoice lines for rendering and reporting
+type LineItemKind string
+
+const (
+	LineItemTuition         LineItemKind = "tuition"
+	LineItemSiblingDiscount LineItemKind = "sibling_discount"
+	LineItemLatePickup      LineItemKind = "late_pickup"
+)
+
+// TuitionPlan is what a child's family is charged, amounts are in cents.
+// The sibling discount applies when the family has more than one child billed, to every child but the most expensive
+type TuitionPlan struct {
+	ChildID                string           `json:"childId"`
+	FamilyID               string           `json:"familyId"` // User ID of the guardian who receives and pays invoices
+	Frequency              BillingFrequency `json:"frequency"`
+	RateCents              int64            `json:"rateCents"`
+	SiblingDiscountPercent int              `json:"siblingDiscountPercent"`
+	StartDate              string           `json:"startDate"`         // YYYY-MM-DD
+	EndDate                string           `json:"endDate,omitempty"` // YYYY-MM-DD, empty while enrolled
+}
+
+// InvoiceLineItem is one charge or credit on an invoice, credits are negative
+type InvoiceLineItem struct {
+	Kind        LineItemKind `json:"kind"`
+	ChildID     string       `json:"childId,omitempty"`
+	Description string       `json:"description"`
+	AmountCents int64        `json:"amountCents"`
+}
+
+// Invoice bills a family for one calendar month
+type Invoice struct {
+	ID         string            `json:"id"`
+	FamilyID   string            `json:"familyId"`
+	Month      string            `json:"month"` // YYYY-MM
+	LineItems  []InvoiceLineItem `json:"lineItems"`
+	TotalCents int64             `json:"totalCents"`
+	Status     InvoiceStatus     `json:"status"`
+	IssuedAt   time.Time         `json:"issuedAt"`
+	DueDate    string            `json:"dueDate"` // YYYY-MM-DD
+	PaidAt     time.Time         `json:"paidAt,omitempty"`
+	PaymentRef string            `json:"paymentRef,omitempty"`
+	VoidedAt   time.Time         `json:"voidedAt,omitempty"`
+	ETag       string            `json:"-"` // Version of the stored row, updates fail if the invoice changed since it was read
+}
+
+// LatePickupPolicy charges a per minute fee for children collected after closing, once the grace period has passed
+type LatePickupPolicy struct {
+	ClosingTime       time.Duration // Offset from local midnight
+	Grace             time.Duration
+	FeeCentsPerMinute int64
+}
+
+func NewTuitionPlan(childID string, familyID string, frequency BillingFrequency, rateCents int64, siblingDiscountPercent int, startDate string, endDate string) (*TuitionPlan, error) {
+	if familyID == "" {
+		return nil, errors.New("familyId is required")
+	}
+	if rateCents <= 0 {
+		return nil, errors.New("rateCents must be positive")
+	}
+	if siblingDiscountPercent < 0 || siblingDiscountPercent > 100 {
+		return nil, errors.New("siblingDiscountPercent must be between 0 and 100")
+	}
+	start, err := time.Parse(AttendanceDateFormat, startDate)
+	if err != nil {
+		return nil, errors.New("startDate must be a YYYY-MM-DD date")
+	}
+	if endDate != "" {
+		end, err := time.Parse(AttendanceDateFormat, endDate)
+		if err != nil {
+			return nil, errors.New("endDate must be a YYYY-MM-DD date")
+		}
+		if end.Before(start) {
+			return nil, errors.New("endDate cannot be before startDate")
+		}
+	}
+	return &TuitionPlan{
+		ChildID:                childID,
+		FamilyID:               familyID,
+		Frequency:              frequency,
+		RateCents:              rateCents,
+		SiblingDiscountPercent: siblingDiscountPercent,
+		StartDate:              startDate,
+		EndDate:                endDate,
+	}, nil
+}
+
+// ActiveOn reports whether the plan covers a YYYY-MM-DD date
+func (planModel TuitionPlan) ActiveOn(date string) bool {
+	return date >= planModel.StartDate && (planModel.EndDate == "" || date <= planModel.EndDate)
+}
+
+// ChargeForMonth returns the tuition owed for a month starting on the given day and how many periods it covers.
+// Monthly plans charge the full rate if active on any day, weekly plans charge for each active week starting on a Monday
+func (planModel TuitionPlan) ChargeForMonth(month time.Time) (int64, int) {
+	periods := 0
+	for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+		if !planModel.ActiveOn(day.Format(AttendanceDateFormat)) {
+			continue
+		}
+		if planModel.Frequency == BillingMonthly {
+			return planModel.RateCents, 1
+		}
+		if day.Weekday() == time.Monday {
+			periods++
+		}
+	}
+	return planModel.RateCents * int64(periods), periods
+}
+
+// LateMinutes returns how many billable minutes after closing a child was collected, 0 inside the grace period
+func (policy LatePickupPolicy) LateMinutes(checkOutAt time.Time) int {
+	local := checkOutAt.In(CenterLocation())
+	late := local.Sub(ClockTime(local, policy.ClosingTime))
+	if late <= policy.Grace {
+		return 0
+	}
+	return int((late + time.Minute - 1) / time.Minute)
+}
+
+// NewInvoice totals the line items of a family's monthly invoice, due the given number of days after issue
+func NewInvoice(id string, familyID string, month string, lineItems []InvoiceLineItem, issuedAt time.Time, dueDays int) *Invoice {
+	var total int64
+	for _, item := range lineItems {
+		total += item.AmountCents
+	}
+	return &Invoice{
+		ID:         id,
+		FamilyID:   familyID,
+		Month:      month,
+		LineItems:  lineItems,
+		TotalCents: total,
+		Status:     InvoiceOpen,
+		IssuedAt:   issuedAt,
+		DueDate:    issuedAt.AddDate(0, 0, dueDays).Format(AttendanceDateFormat),
+	}
+}
+
+// MarkPaid moves an open invoice to paid
+func (invoiceModel *Invoice) MarkPaid(reference string, at time.Time) error {
+	if invoiceModel.Status != InvoiceOpen {
+		return fmt.Errorf("only open invoices can be paid, invoice is %s", invoiceModel.Status)
+	}
+	invoiceModel.Status = InvoicePaid
+	invoiceModel.PaymentRef = reference
+	invoiceModel.PaidAt = at
+	return nil
+}
+
+// Void cancels an open invoice
+func (invoiceModel *Invoice) Void(at time.Time) error {
+	if invoiceModel.Status != InvoiceOpen {
+		return fmt.Errorf("only open invoices can be voided, invoice is %s", invoiceModel.Status)
+	}
+	invoiceModel.Status = InvoiceVoid
+	invoiceModel.VoidedAt = at
+	return nil
+}
+
+// FormatCents renders an amount in cents as dollars, e.g. -1250 as "-$12.50"
+func FormatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s$%d.%02d", sign, cents/100, cents%100)
+}
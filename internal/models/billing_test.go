@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// Helper - run a test with the center in Chicago, restoring the previous zone afterwards
+func useChicago(t *testing.T) *time.Location {
+	t.Helper()
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+	previous := CenterLocation()
+	SetCenterLocation(chicago)
+	t.Cleanup(func() { SetCenterLocation(previous) })
+	return chicago
+}
+
+func TestChargeForMonth(t *testing.T) {
+	chicago := useChicago(t)
+	// March 2025 has Mondays on the 3rd, 10th, 17th, 24th and 31st and a daylight saving change on the 9th
+	march := time.Date(2025, time.March, 1, 0, 0, 0, 0, chicago)
+
+	tests := []struct {
+		name        string
+		frequency   BillingFrequency
+		startDate   string
+		endDate     string
+		wantCents   int64
+		wantPeriods int
+	}{
+		{name: "weekly all month", frequency: BillingWeekly, startDate: "2025-01-01", wantCents: 50000, wantPeriods: 5},
+		{name: "weekly starting mid week", frequency: BillingWeekly, startDate: "2025-03-12", wantCents: 30000, wantPeriods: 3},
+		{name: "weekly starting on a Monday", frequency: BillingWeekly, startDate: "2025-03-10", wantCents: 40000, wantPeriods: 4},
+		{name: "weekly ending mid month", frequency: BillingWeekly, startDate: "2025-01-01", endDate: "2025-03-19", wantCents: 30000, wantPeriods: 3},
+		{name: "weekly ending the Sunday before a Monday", frequency: BillingWeekly, startDate: "2025-01-01", endDate: "2025-03-16", wantCents: 20000, wantPeriods: 2},
+		{name: "weekly for a single Monday", frequency: BillingWeekly, startDate: "2025-03-10", endDate: "2025-03-10", wantCents: 10000, wantPeriods: 1},
+		{name: "weekly active without a Monday", frequency: BillingWeekly, startDate: "2025-03-11", endDate: "2025-03-16", wantCents: 0, wantPeriods: 0},
+		{name: "weekly starting next month", frequency: BillingWeekly, startDate: "2025-04-01", wantCents: 0, wantPeriods: 0},
+		{name: "monthly starting on the last day", frequency: BillingMonthly, startDate: "2025-03-31", wantCents: 10000, wantPeriods: 1},
+		{name: "monthly ending on the first day", frequency: BillingMonthly, startDate: "2025-01-01", endDate: "2025-03-01", wantCents: 10000, wantPeriods: 1},
+		{name: "monthly ended last month", frequency: BillingMonthly, startDate: "2025-01-01", endDate: "2025-02-28", wantCents: 0, wantPeriods: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := TuitionPlan{Frequency: tt.frequency, RateCents: 10000, StartDate: tt.startDate, EndDate: tt.endDate}
+			cents, periods := plan.ChargeForMonth(march)
+			if cents != tt.wantCents || periods != tt.wantPeriods {
+				t.Errorf("ChargeForMonth() = %d, %d, want %d, %d", cents, periods, tt.wantCents, tt.wantPeriods)
+			}
+		})
+	}
+}
+
+func TestLateMinutes(t *testing.T) {
+	chicago := useChicago(t)
+	policy := LatePickupPolicy{ClosingTime: 18 * time.Hour, Grace: 10 * time.Minute, FeeCentsPerMinute: 100}
+
+	tests := []struct {
+		name       string
+		checkOutAt time.Time
+		want       int
+	}{
+		{name: "before closing", checkOutAt: time.Date(2025, time.June, 4, 17, 30, 0, 0, chicago), want: 0},
+		{name: "inside the grace period", checkOutAt: time.Date(2025, time.June, 4, 18, 5, 0, 0, chicago), want: 0},
+		{name: "at the end of the grace period", checkOutAt: time.Date(2025, time.June, 4, 18, 10, 0, 0, chicago), want: 0},
+		{name: "just past the grace period rounds up", checkOutAt: time.Date(2025, time.June, 4, 18, 10, 1, 0, chicago), want: 11},
+		{name: "whole minutes late", checkOutAt: time.Date(2025, time.June, 4, 18, 25, 0, 0, chicago), want: 25},
+		{name: "read in the center time zone", checkOutAt: time.Date(2025, time.June, 4, 23, 20, 0, 0, time.UTC), want: 20},
+		{name: "spring forward day", checkOutAt: time.Date(2025, time.March, 9, 18, 15, 0, 0, chicago), want: 15},
+		{name: "fall back day", checkOutAt: time.Date(2025, time.November, 2, 18, 15, 0, 0, chicago), want: 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.LateMinutes(tt.checkOutAt); got != tt.want {
+				t.Errorf("LateMinutes(%v) = %d, want %d", tt.checkOutAt, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// centerLocation is the time zone the center operates in. Calendar dates and clock times are read in this zone
+// rather than the server's, it is the server's zone until SetCenterLocation is called at startup
+var centerLocation = time.Local
+
+// SetCenterLocation sets the center's time zone, called once at startup before requests are served
+func SetCenterLocation(loc *time.Location) {
+	if loc != nil {
+		centerLocation = loc
+	}
+}
+
+// CenterLocation returns the center's time zone
+func CenterLocation() *time.Location {
+	return centerLocation
+}
+
+// CenterNow returns the current time in the center's time zone
+func CenterNow() time.Time {
+	return time.Now().In(centerLocation)
+}
+
+// ClockTime returns the time on t's calendar day at a clock time given as an offset from midnight. The offset is read
+// as wall clock time, so on a day with a daylight saving change 18 hours after midnight is still 18:00
+func ClockTime(t time.Time, offset time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, int(offset/time.Second), 0, t.Location())
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Relationship describes how a guardian is related to a child
+type Relationship string
+
+// Valid guardian relationship types
+const (
+	RelationshipMother        Relationship = "mother"
+	RelationshipFather        Relationship = "father"
+	RelationshipParent        Relationship = "parent"
+	RelationshipGrandparent   Relationship = "grandparent"
+	RelationshipLegalGuardian Relationship = "legal_guardian"
+	RelationshipOther         Relationship = "other"
+)
+
+// ParseRelationship validates a relationship type supplied by a client
+func ParseRelationship(value string) (Relationship, error) {
+	relationship := Relationship(strings.ToLower(strings.TrimSpace(value)))
+	switch relationship {
+	case RelationshipMother, RelationshipFather, RelationshipParent, RelationshipGrandparent, RelationshipLegalGuardian, RelationshipOther:
+		return relationship, nil
+	}
+	return "", errors.New("invalid relationship: must be mother, father, parent, grandparent, legal_guardian, or other")
+}
+
+// Child is a child enrolled at the center
+type Child struct {
+	ID           string
+	Name         string
+	Birthdate    string // YYYY-MM-DD
+	Classroom    string
+	Allergies    []Allergy
+	Notes        string
+	MediaConsent MediaConsent // How widely photos of the child may be shared
+	// Who last changed MediaConsent and when, a guardian or an admin
+	MediaConsentBy string
+	MediaConsentAt time.Time
+	Guardians      []GuardianLink
+}
+
+// GuardianLink connects a User account to a Child
+type GuardianLink struct {
+	ChildID      string       `json:"childId"`
+	UserID       string       `json:"userId"`
+	Relationship Relationship `json:"relationship"`
+}
+
+func NewChild(id string, name string, birthdate string, classroom string, allergies []Allergy, notes string) (*Child, error) {
+	if name == "" {
+		return nil, errors.New("child name is required")
+	}
+	if _, err := time.Parse("2006-01-02", birthdate); err != nil {
+		return nil, errors.New("birthdate must use the YYYY-MM-DD format")
+	}
+	allergies, err := ValidateAllergies(allergies)
+	if err != nil {
+		return nil, err
+	}
+	return &Child{
+		ID:           id,
+		Name:         name,
+		Birthdate:    birthdate,
+		Classroom:    classroom,
+		Allergies:    allergies,
+		Notes:        notes,
+		MediaConsent: MediaConsentNone,
+	}, nil
+}
+
+func (childModel *Child) Update(newData Child) error {
+	if newData.ID != childModel.ID {
+		return errors.New("Invalid ID when trying to update fields in Child")
+	}
+	if newData.Name != "" {
+		childModel.Name = newData.Name
+	}
+	if newData.Birthdate != "" {
+		if _, err := time.Parse("2006-01-02", newData.Birthdate); err != nil {
+			return errors.New("birthdate must use the YYYY-MM-DD format")
+		}
+		childModel.Birthdate = newData.Birthdate
+	}
+	if newData.Classroom != "" {
+		childModel.Classroom = newData.Classroom
+	}
+	if newData.Allergies != nil {
+		allergies, err := ValidateAllergies(newData.Allergies)
+		if err != nil {
+			return err
+		}
+		childModel.Allergies = allergies
+	}
+	if newData.Notes != "" {
+		childModel.Notes = newData.Notes
+	}
+	if newData.MediaConsent != "" {
+		childModel.MediaConsent = newData.MediaConsent
+		childModel.MediaConsentBy = newData.MediaConsentBy
+		childModel.MediaConsentAt = newData.MediaConsentAt
+	}
+	return nil
+}
+
+// HasGuardian reports whether the user is linked to the child as a guardian
+func (childModel *Child) HasGuardian(userID string) bool {
+	for _, link := range childModel.Guardians {
+		if link.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// AgeInMonths returns the child's age in whole months on the given day, or -1 if the birthdate is invalid
+func (childModel *Child) AgeInMonths(at time.Time) int {
+	birthdate, err := time.Parse("2006-01-02", childModel.Birthdate)
+	if err != nil {
+		return -1
+	}
+	months := (at.Year()-birthdate.Year())*12 + int(at.Month()-birthdate.Month())
+	if at.Day() < birthdate.Day() {
+		months--
+	}
+	return months
+}
+
+// HasSevereAllergy reports whether any of the child's allergies is severe or anaphylactic
+func (childModel *Child) HasSevereAllergy() bool {
+	for _, allergy := range childModel.Allergies {
+		if allergy.IsSevere() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ClaimsReconcileResult summarises one pass of the admin claims reconciler
+type ClaimsReconcileResult struct {
+	StartedAt    time.Time `json:"startedAt"`
+	FinishedAt   time.Time `json:"finishedAt"`
+	UsersChecked int       `json:"usersChecked"`
+	Granted      []string  `json:"granted"`
+	Revoked      []string  `json:"revoked"`
+	Missing      []string  `json:"missing"`
+	Errors       []string  `json:"errors"`
+	Error        string    `json:"error,omitempty"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "errors"
+
+// Classroom groups enrolled children with the teachers assigned to them
+type Classroom struct {
+	ID       string
+	Name     string
+	AgeGroup string
+	Capacity int
+	// Licensing ratio rules by child age, DefaultRatioRules apply when empty
+	RatioRules []RatioRule
+}
+
+// ClassroomRoster is a classroom with its assigned teachers and enrolled children resolved
+type ClassroomRoster struct {
+	Classroom Classroom
+	Teachers  []User
+	Children  []Child
+}
+
+func NewClassroom(id string, name string, ageGroup string, capacity int) (*Classroom, error) {
+	if name == "" {
+		return nil, errors.New("classroom name is required")
+	}
+	if capacity < 0 {
+		return nil, errors.New("classroom capacity cannot be negative")
+	}
+	return &Classroom{
+		ID:       id,
+		Name:     name,
+		AgeGroup: ageGroup,
+		Capacity: capacity,
+	}, nil
+}
+
+func (classroomModel *Classroom) Update(newData Classroom) error {
+	if newData.ID != classroomModel.ID {
+		return errors.New("Invalid ID when trying to update fields in Classroom")
+	}
+	if newData.Name != "" {
+		classroomModel.Name = newData.Name
+	}
+	if newData.AgeGroup != "" {
+		classroomModel.AgeGroup = newData.AgeGroup
+	}
+	if newData.Capacity > 0 {
+		classroomModel.Capacity = newData.Capacity
+	}
+	if newData.RatioRules != nil {
+		if err := ValidateRatioRules(newData.RatioRules); err != nil {
+			return err
+		}
+		classroomModel.RatioRules = newData.RatioRules
+	}
+	return nil
+}
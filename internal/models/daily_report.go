@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// DailyEntryType identifies what a daily report entry records
+type DailyEntryType string
+
+// Valid daily report entry types
+const (
+	DailyEntryMeal      DailyEntryType = "meal"
+	DailyEntryNap       DailyEntryType = "nap"
+	DailyEntryToileting DailyEntryType = "toileting" // diaper changes and potty visits
+	DailyEntryMood      DailyEntryType = "mood"
+	DailyEntryActivity  DailyEntryType = "activity"
+	DailyEntryPhoto     DailyEntryType = "photo"
+)
+
+// ParseDailyEntryType validates an entry type supplied by a client
+func ParseDailyEntryType(value string) (DailyEntryType, error) {
+	entryType := DailyEntryType(strings.ToLower(strings.TrimSpace(value)))
+	switch entryType {
+	case DailyEntryMeal, DailyEntryNap, DailyEntryToileting, DailyEntryMood, DailyEntryActivity, DailyEntryPhoto:
+		return entryType, nil
+	}
+	return "", errors.New("invalid entry type: must be meal, nap, toileting, mood, activity, or photo")
+}
+
+// DailyEntry is one thing a teacher logged about a child during the day.
+// Which fields are set depends on Type: meals use Meal and Amount, naps use StartAt and EndAt,
+// toileting uses Kind (e.g. wet, bm, potty), moods use Mood and photos reference an uploaded image
+type DailyEntry struct {
+	ID         string         `json:"id"`
+	ChildID    string         `json:"childId"`
+	Date       string         `json:"date"` // YYYY-MM-DD, same layout as attendance dates
+	Type       DailyEntryType `json:"type"`
+	StartAt    time.Time      `json:"startAt"`
+	EndAt      time.Time      `json:"endAt,omitempty"`
+	Meal       string         `json:"meal,omitempty"`
+	Amount     string         `json:"amount,omitempty"`
+	Kind       string         `json:"kind,omitempty"`
+	Mood       string         `json:"mood,omitempty"`
+	Notes      string         `json:"notes,omitempty"`
+	ImageOwner string         `json:"imageOwner,omitempty"`
+	ImageName  string         `json:"imageName,omitempty"`
+	StaffID    string         `json:"staffId"`
+}
+
+// DailyReport groups a child's entries for one day
+type DailyReport struct {
+	ChildID         string       `json:"childId"`
+	ChildName       string       `json:"childName"`
+	Date            string       `json:"date"`
+	Meals           []DailyEntry `json:"meals"`
+	Naps            []DailyEntry `json:"naps"`
+	Toileting       []DailyEntry `json:"toileting"`
+	Moods           []DailyEntry `json:"moods"`
+	Activities      []DailyEntry `json:"activities"`
+	Photos          []DailyEntry `json:"photos"`
+	TotalNapMinutes int          `json:"totalNapMinutes"`
+}
+
+// Validate checks the fields required by the entry's type
+func (entry DailyEntry) Validate() error {
+	if entry.StartAt.IsZero() {
+		return errors.New("entry time is required")
+	}
+	switch entry.Type {
+	case DailyEntryMeal:
+		if entry.Meal == "" {
+			return errors.New("meal entries need a meal, e.g. breakfast, lunch or snack")
+		}
+	case DailyEntryNap:
+		if !entry.EndAt.IsZero() && !entry.EndAt.After(entry.StartAt) {
+			return errors.New("nap end must be after its start")
+		}
+	case DailyEntryToileting:
+		if entry.Kind == "" {
+			return errors.New("toileting entries need a kind, e.g. wet, bm or potty")
+		}
+	case DailyEntryMood:
+		if entry.Mood == "" {
+			return errors.New("mood entries need a mood")
+		}
+	case DailyEntryActivity:
+		if entry.Notes == "" {
+			return errors.New("activity entries need a description in notes")
+		}
+	case DailyEntryPhoto:
+		if entry.ImageOwner == "" || entry.ImageName == "" {
+			return errors.New("photo entries need the uploaded image's owner and name")
+		}
+	default:
+		return errors.New("invalid entry type")
+	}
+	return nil
+}
+
+// NewDailyReport sorts entries, which must be in time order, into a report
+func NewDailyReport(child Child, date string, entries []DailyEntry) DailyReport {
+	report := DailyReport{
+		ChildID:    child.ID,
+		ChildName:  child.Name,
+		Date:       date,
+		Meals:      []DailyEntry{},
+		Naps:       []DailyEntry{},
+		Toileting:  []DailyEntry{},
+		Moods:      []DailyEntry{},
+		Activities: []DailyEntry{},
+		Photos:     []DailyEntry{},
+	}
+	for _, entry := range entries {
+		switch entry.Type {
+		case DailyEntryMeal:
+			report.Meals = append(report.Meals, entry)
+		case DailyEntryNap:
+			report.Naps = append(report.Naps, entry)
+			if !entry.EndAt.IsZero() {
+				report.TotalNapMinutes += int(entry.EndAt.Sub(entry.StartAt).Minutes())
+			}
+		case DailyEntryToileting:
+			report.Toileting = append(report.Toileting, entry)
+		case DailyEntryMood:
+			report.Moods = append(report.Moods, entry)
+		case DailyEntryActivity:
+			report.Activities = append(report.Activities, entry)
+		case DailyEntryPhoto:
+			report.Photos = append(report.Photos, entry)
+		}
+	}
+	return report
+}
+
+// IsEmpty reports whether nothing was logged for the day
+func (report DailyReport) IsEmpty() bool {
+	return len(report.Meals)+len(report.Naps)+len(report.Toileting)+len(report.Moods)+len(report.Activities)+len(report.Photos) == 0
+}
@@ -11,8 +11,12 @@ type Event struct {
 	Location    string
 	Description string
 	Color       string
+	Classroom   string // Optional classroom ID the event belongs to
 	Creator     User
 	Invitees    []User
+	// Classrooms invited as a whole, their enrolled children's guardians are added to Invitees when the event is read.
+	// On update nil leaves the stored classrooms unchanged while an empty slice clears them
+	InvitedClassrooms []string
 }
 
 func NewEvent(id string, name string, date string, starttime string, endtime string, location string, description string, color string, creator User, invitees []User) *Event {
@@ -55,8 +59,14 @@ func (eventModel *Event) Update(newData Event) error {
 	if newData.Color != "" {
 		eventModel.Color = newData.Color
 	}
+	if newData.Classroom != "" {
+		eventModel.Classroom = newData.Classroom
+	}
 	if len(newData.Invitees) > 0 {
 		eventModel.Invitees = newData.Invitees
 	}
+	if newData.InvitedClassrooms != nil {
+		eventModel.InvitedClassrooms = newData.InvitedClassrooms
+	}
 	return nil
 }
@@ -11,6 +11,9 @@ type EventEntity struct {
 	Location    string
 	Description string
 	Color       string
+	Classroom   string
 	CreatorID   string `json:"Creator"`
 	InviteeIDs  string `json:"Invitees"`
+	// CSV of classroom IDs invited as a whole
+	InvitedClassroomIDs string `json:"InvitedClassrooms"`
 }
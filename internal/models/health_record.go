@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HealthRecordType is the kind of document a health record describes
+type HealthRecordType string
+
+const (
+	HealthImmunization HealthRecordType = "immunization"
+	HealthPhysical     HealthRecordType = "physical"
+)
+
+// HealthRequirementPhysical names the physical exam in the list of required records, every other requirement is a vaccine
+const HealthRequirementPhysical = "physical"
+
+// ParseHealthRecordType validates a record type sent by a client
+func ParseHealthRecordType(value string) (HealthRecordType, error) {
+	switch HealthRecordType(strings.ToLower(value)) {
+	case HealthImmunization:
+		return HealthImmunization, nil
+	case HealthPhysical:
+		return HealthPhysical, nil
+	}
+	return "", fmt.Errorf("invalid health record type %q", value)
+}
+
+// HealthRecord is an immunization or physical exam for a child, optionally backed by a scanned document
+type HealthRecord struct {
+	ID           string           `json:"id"`
+	ChildID      string           `json:"childId"`
+	Type         HealthRecordType `json:"type"`
+	Vaccine      string           `json:"vaccine,omitempty"`
+	Dose         int              `json:"dose,omitempty"`
+	Date         string           `json:"date"`
+	ExpiresOn    string           `json:"expiresOn,omitempty"`
+	DocumentBlob string           `json:"-"`
+	DocumentType string           `json:"documentType,omitempty"`
+	UploadedBy   string           `json:"uploadedBy"`
+	UploadedAt   time.Time        `json:"uploadedAt"`
+}
+
+// ComplianceStatus is why a required record needs attention
+type ComplianceStatus string
+
+const (
+	ComplianceMissing  ComplianceStatus = "missing"
+	ComplianceExpired  ComplianceStatus = "expired"
+	ComplianceExpiring ComplianceStatus = "expiring"
+)
+
+// ComplianceIssue is a required record that is missing, expired or about to expire
+type ComplianceIssue struct {
+	Requirement string           `json:"requirement"`
+	Status      ComplianceStatus `json:"status"`
+	ExpiresOn   string           `json:"expiresOn,omitempty"`
+}
+
+// ChildCompliance lists the outstanding health record issues for one child
+type ChildCompliance struct {
+	ChildID   string            `json:"childId"`
+	ChildName string            `json:"childName"`
+	Classroom string            `json:"classroom"`
+	Issues    []ComplianceIssue `json:"issues"`
+}
+
+func NewHealthRecord(id string, childID string, recordType HealthRecordType, vaccine string, dose int, date string, expiresOn string, uploadedBy string) (*HealthRecord, error) {
+	recordDate, err := time.Parse(AttendanceDateFormat, date)
+	if err != nil {
+		return nil, errors.New("date must be a YYYY-MM-DD date")
+	}
+	if recordDate.After(time.Now()) {
+		return nil, errors.New("date cannot be in the future")
+	}
+	if expiresOn != "" {
+		expiry, err := time.Parse(AttendanceDateFormat, expiresOn)
+		if err != nil {
+			return nil, errors.New("expiresOn must be a YYYY-MM-DD date")
+		}
+		if !expiry.After(recordDate) {
+			return nil, errors.New("expiresOn must be after the record date")
+		}
+	}
+	vaccine = strings.TrimSpace(vaccine)
+	if recordType == HealthImmunization && vaccine == "" {
+		return nil, errors.New("vaccine is required for immunization records")
+	}
+	if recordType == HealthPhysical {
+		vaccine = ""
+		dose = 0
+	}
+	if dose < 0 {
+		return nil, errors.New("dose cannot be negative")
+	}
+	return &HealthRecord{
+		ID:         id,
+		ChildID:    childID,
+		Type:       recordType,
+		Vaccine:    vaccine,
+		Dose:       dose,
+		Date:       date,
+		ExpiresOn:  expiresOn,
+		UploadedBy: uploadedBy,
+		UploadedAt: time.Now(),
+	}, nil
+}
+
+// Requirement is the name of the required record this record satisfies
+func (recordModel HealthRecord) Requirement() string {
+	if recordModel.Type == HealthPhysical {
+		return HealthRequirementPhysical
+	}
+	return recordModel.Vaccine
+}
+
+// CheckCompliance compares a child's records against the required list, using the most recent record for each
+// requirement. Records without an expiry never expire, records expiring within the warning window are flagged
+func CheckCompliance(child Child, records []HealthRecord, required []string, at time.Time, warning time.Duration) ChildCompliance {
+	sorted := append([]HealthRecord{}, records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date > sorted[j].Date
+	})
+
+	compliance := ChildCompliance{ChildID: child.ID, ChildName: child.Name, Classroom: child.Classroom, Issues: []ComplianceIssue{}}
+	today := at.Format(AttendanceDateFormat)
+	warnFrom := at.Add(warning).Format(AttendanceDateFormat)
+	for _, requirement := range required {
+		var latest *HealthRecord
+		for i := range sorted {
+			if strings.EqualFold(sorted[i].Requirement(), requirement) {
+				latest = &sorted[i]
+				break
+			}
+		}
+
+		switch {
+		case latest == nil:
+			compliance.Issues = append(compliance.Issues, ComplianceIssue{Requirement: requirement, Status: ComplianceMissing})
+		case latest.ExpiresOn == "":
+		case latest.ExpiresOn < today:
+			compliance.Issues = append(compliance.Issues, ComplianceIssue{Requirement: requirement, Status: ComplianceExpired, ExpiresOn: latest.ExpiresOn})
+		case latest.ExpiresOn <= warnFrom:
+			compliance.Issues = append(compliance.Issues, ComplianceIssue{Requirement: requirement, Status: ComplianceExpiring, ExpiresOn: latest.ExpiresOn})
+		}
+	}
+	return compliance
+}
@@ -1,12 +1,25 @@
 package models
 
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
 type Image struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	URL         string `json:"url"`
-	ContentType string `json:"contentType"`
-	Size        int64  `json:"size"`
-	UploadedAt  string `json:"uploadedAt"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	URL         string      `json:"url"`
+	ContentType string      `json:"contentType"`
+	Size        int64       `json:"size"`
+	UploadedAt  string      `json:"uploadedAt"`
+	Classroom   string      `json:"classroom,omitempty"`
+	Variants    []ImageSize `json:"variants,omitempty"`   // Resized copies stored alongside the original
+	Sanitized   bool        `json:"sanitized"`            // EXIF, GPS and other metadata were removed before storing
+	URLExpires  string      `json:"urlExpires,omitempty"` // When the signed URL stops working
+	Hash        string      `json:"hash,omitempty"`       // SHA-256 of the file as uploaded, hex encoded
+	Duplicate   bool        `json:"duplicate,omitempty"`  // The upload matched an image the user already had
 }
 
 type ImageUploadResponse struct {
@@ -14,3 +27,51 @@ type ImageUploadResponse struct {
 	Message string `json:"message"`
 	Image   *Image `json:"image,omitempty"`
 }
+
+// SignedImageURL is a short-lived link to an image that a browser can load without an Authorization header.
+// Against the local emulator it is the API proxy path instead, which still needs the bearer token
+type SignedImageURL struct {
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Proxied   bool       `json:"proxied"`
+}
+
+// ImageStream is a stored image opened for reading, Content fetches byte ranges on demand so seeking to serve
+// an HTTP Range request never downloads the skipped part
+type ImageStream struct {
+	Content      io.ReadSeekCloser
+	ContentType  string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
+// ImageSize selects the original upload or one of its resized variants
+type ImageSize string
+
+const (
+	ImageSizeThumb    ImageSize = "thumb"
+	ImageSizeMedium   ImageSize = "medium"
+	ImageSizeOriginal ImageSize = "original"
+)
+
+// ImageVariants lists the resized variants generated for each upload with the longest edge of each in pixels
+var ImageVariants = []struct {
+	Size         ImageSize
+	MaxDimension int
+}{
+	{ImageSizeThumb, 256},
+	{ImageSizeMedium, 1280},
+}
+
+// ParseImageSize validates a size supplied by a client, defaulting to the original
+func ParseImageSize(value string) (ImageSize, error) {
+	size := ImageSize(strings.ToLower(strings.TrimSpace(value)))
+	switch size {
+	case "":
+		return ImageSizeOriginal, nil
+	case ImageSizeThumb, ImageSizeMedium, ImageSizeOriginal:
+		return size, nil
+	}
+	return "", fmt.Errorf("invalid image size %q: must be thumb, medium, or original", value)
+}
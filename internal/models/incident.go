@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// IncidentStatus is where an incident report is in its workflow
+type IncidentStatus string
+
+// Incident reports move from draft to submitted when guardians are notified, then to acknowledged once a guardian signs off
+const (
+	IncidentDraft        IncidentStatus = "draft"
+	IncidentSubmitted    IncidentStatus = "submitted"
+	IncidentAcknowledged IncidentStatus = "acknowledged"
+)
+
+// Incident is a report of an injury or other incident involving a child
+type Incident struct {
+	ID             string         `json:"id"`
+	ChildID        string         `json:"childId"`
+	Classroom      string         `json:"classroom"`
+	OccurredAt     time.Time      `json:"occurredAt"`
+	Location       string         `json:"location"`
+	Description    string         `json:"description"`
+	FirstAid       string         `json:"firstAid"`
+	Witnesses      []string       `json:"witnesses"`
+	AuthorID       string         `json:"authorId"`
+	Status         IncidentStatus `json:"status"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	SubmittedAt    time.Time      `json:"submittedAt,omitempty"`
+	AcknowledgedAt time.Time      `json:"acknowledgedAt,omitempty"`
+	AcknowledgedBy string         `json:"acknowledgedBy,omitempty"`
+}
+
+func NewIncident(id string, child Child, occurredAt time.Time, location string, description string, firstAid string, witnesses []string, authorID string) (*Incident, error) {
+	if occurredAt.IsZero() {
+		return nil, errors.New("incident time is required")
+	}
+	if occurredAt.After(time.Now().Add(5 * time.Minute)) {
+		return nil, errors.New("incident time cannot be in the future")
+	}
+	if strings.TrimSpace(description) == "" {
+		return nil, errors.New("incident description is required")
+	}
+	if witnesses == nil {
+		witnesses = []string{}
+	}
+	return &Incident{
+		ID:          id,
+		ChildID:     child.ID,
+		Classroom:   child.Classroom,
+		OccurredAt:  occurredAt,
+		Location:    location,
+		Description: description,
+		FirstAid:    firstAid,
+		Witnesses:   witnesses,
+		AuthorID:    authorID,
+		Status:      IncidentDraft,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// Update applies partial changes, only drafts may be edited
+func (incidentModel *Incident) Update(newData Incident) error {
+	if newData.ID != incidentModel.ID {
+		return errors.New("Invalid ID when trying to update fields in Incident")
+	}
+	if incidentModel.Status != IncidentDraft {
+		return errors.New("only draft incident reports can be edited")
+	}
+	if !newData.OccurredAt.IsZero() {
+		incidentModel.OccurredAt = newData.OccurredAt
+	}
+	if newData.Location != "" {
+		incidentModel.Location = newData.Location
+	}
+	if newData.Description != "" {
+		incidentModel.Description = newData.Description
+	}
+	if newData.FirstAid != "" {
+		incidentModel.FirstAid = newData.FirstAid
+	}
+	if newData.Witnesses != nil {
+		incidentModel.Witnesses = newData.Witnesses
+	}
+	return nil
+}
+
+// Submit moves a draft to submitted
+func (incidentModel *Incident) Submit(at time.Time) error {
+	if incidentModel.Status != IncidentDraft {
+		return errors.New("only draft incident reports can be submitted")
+	}
+	incidentModel.Status = IncidentSubmitted
+	incidentModel.SubmittedAt = at
+	return nil
+}
+
+// Acknowledge records a guardian's sign off on a submitted report
+func (incidentModel *Incident) Acknowledge(guardianID string, at time.Time) error {
+	if incidentModel.Status != IncidentSubmitted {
+		return errors.New("only submitted incident reports can be acknowledged")
+	}
+	incidentModel.Status = IncidentAcknowledged
+	incidentModel.AcknowledgedAt = at
+	incidentModel.AcknowledgedBy = guardianID
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Invite tracks an email address that has been invited to create an account
+type Invite struct {
+	Email     string
+	Role      Role
+	SignedUp  bool
+	InvitedAt time.Time
+	ChildIDs  []string // Children the new account is linked to as a guardian once it signs up
+	ETag      string   // Version of the stored row, so an invite can be claimed by one sign up only
+}
+
+func NewInvite(email string, role Role) *Invite {
+	return &Invite{
+		Email:     email,
+		Role:      role,
+		SignedUp:  false,
+		InvitedAt: time.Now().UTC(),
+	}
+}
+
+// IsAdmin reports whether the invite grants the admin role
+func (invite *Invite) IsAdmin() bool {
+	return invite.Role == RoleAdmin
+}
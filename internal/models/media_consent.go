@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MediaConsent is how widely a family allows photos of their child to be shared
+type MediaConsent string
+
+// Consent levels from most to least restrictive. Children without a recorded choice are treated as none
+const (
+	MediaConsentNone      MediaConsent = "none"      // Not shared with anyone outside the child's own family
+	MediaConsentInternal  MediaConsent = "internal"  // Staff only, never shown to other families
+	MediaConsentClassroom MediaConsent = "classroom" // Other families in the child's classroom
+	MediaConsentPublic    MediaConsent = "public"    // Every family and the center website
+)
+
+var mediaConsentRank = map[MediaConsent]int{
+	MediaConsentNone:      0,
+	MediaConsentInternal:  1,
+	MediaConsentClassroom: 2,
+	MediaConsentPublic:    3,
+}
+
+// ParseMediaConsent validates a consent level supplied by a client
+func ParseMediaConsent(value string) (MediaConsent, error) {
+	consent := MediaConsent(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := mediaConsentRank[consent]; !ok {
+		return "", fmt.Errorf("invalid media consent %q: must be none, internal, classroom, or public", value)
+	}
+	return consent, nil
+}
+
+// Allows reports whether the consent covers sharing at the given level
+func (c MediaConsent) Allows(level MediaConsent) bool {
+	return mediaConsentRank[c] >= mediaConsentRank[level]
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MedicationDoseTolerance is how far from a scheduled time a dose may be given without a warning
+const MedicationDoseTolerance = 30 * time.Minute
+
+// MedicationAuthorization is a guardian's signed permission for staff to give a child a medication
+type MedicationAuthorization struct {
+	ID              string    `json:"id"`
+	ChildID         string    `json:"childId"`
+	Drug            string    `json:"drug"`
+	Dose            string    `json:"dose"`
+	Instructions    string    `json:"instructions"`
+	Times           []string  `json:"times"`           // Scheduled "HH:MM" local times, empty when given as needed
+	MinIntervalMins int       `json:"minIntervalMins"` // Minimum minutes between doses, 0 when unrestricted
+	StartDate       string    `json:"startDate"`       // YYYY-MM-DD
+	EndDate         string    `json:"endDate"`         // YYYY-MM-DD
+	SignedBy        string    `json:"signedBy"`
+	Signature       string    `json:"signature"` // Guardian's typed full name
+	SignedAt        time.Time `json:"signedAt"`
+	RevokedAt       time.Time `json:"revokedAt,omitempty"`
+}
+
+// MedicationDose is one administered dose, with any warnings raised when it was logged
+type MedicationDose struct {
+	ID              string    `json:"id"`
+	ChildID         string    `json:"childId"`
+	AuthorizationID string    `json:"authorizationId"`
+	Drug            string    `json:"drug"`
+	Dose            string    `json:"dose"`
+	AdministeredAt  time.Time `json:"administeredAt"`
+	StaffID         string    `json:"staffId"`
+	Notes           string    `json:"notes"`
+	Warnings        []string  `json:"warnings"`
+}
+
+func NewMedicationAuthorization(id string, childID string, drug string, dose string, instructions string, times []string, minIntervalMins int, startDate string, endDate string, signedBy string, signature string) (*MedicationAuthorization, error) {
+	drug = strings.TrimSpace(drug)
+	dose = strings.TrimSpace(dose)
+	signature = strings.TrimSpace(signature)
+	if drug == "" || dose == "" {
+		return nil, errors.New("drug and dose are required")
+	}
+	if signature == "" {
+		return nil, errors.New("a guardian signature is required")
+	}
+	start, err := time.Parse(AttendanceDateFormat, startDate)
+	if err != nil {
+		return nil, errors.New("startDate must be a YYYY-MM-DD date")
+	}
+	end, err := time.Parse(AttendanceDateFormat, endDate)
+	if err != nil {
+		return nil, errors.New("endDate must be a YYYY-MM-DD date")
+	}
+	if end.Before(start) {
+		return nil, errors.New("endDate cannot be before startDate")
+	}
+	if times == nil {
+		times = []string{}
+	}
+	for _, scheduled := range times {
+		if _, err := time.Parse("15:04", scheduled); err != nil {
+			return nil, fmt.Errorf("scheduled time %q must use the HH:MM format", scheduled)
+		}
+	}
+	if minIntervalMins < 0 {
+		return nil, errors.New("minIntervalMins cannot be negative")
+	}
+	return &MedicationAuthorization{
+		ID:              id,
+		ChildID:         childID,
+		Drug:            drug,
+		Dose:            dose,
+		Instructions:    instructions,
+		Times:           times,
+		MinIntervalMins: minIntervalMins,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		SignedBy:        signedBy,
+		Signature:       signature,
+		SignedAt:        time.Now(),
+	}, nil
+}
+
+// IsRevoked reports whether the guardian has withdrawn the authorization
+func (authorizationModel MedicationAuthorization) IsRevoked() bool {
+	return !authorizationModel.RevokedAt.IsZero()
+}
+
+// CheckDose returns warnings for a dose given at the given time outside what the guardian authorized.
+// previous holds earlier doses given under this authorization
+func (authorizationModel MedicationAuthorization) CheckDose(at time.Time, previous []MedicationDose) []string {
+	warnings := []string{}
+	if authorizationModel.IsRevoked() && at.After(authorizationModel.RevokedAt) {
+		warnings = append(warnings, fmt.Sprintf("authorization was revoked on %s", authorizationModel.RevokedAt.In(CenterLocation()).Format(time.RFC1123)))
+	}
+
+	local := at.In(CenterLocation())
+	date := local.Format(AttendanceDateFormat)
+	if date < authorizationModel.StartDate || date > authorizationModel.EndDate {
+		warnings = append(warnings, fmt.Sprintf("dose is outside the authorized dates %s to %s", authorizationModel.StartDate, authorizationModel.EndDate))
+	}
+
+	if len(authorizationModel.Times) > 0 {
+		nearSchedule := false
+		for _, scheduled := range authorizationModel.Times {
+			clock, _ := time.Parse("15:04", scheduled)
+			scheduledAt := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, CenterLocation())
+			if diff := local.Sub(scheduledAt); diff >= -MedicationDoseTolerance && diff <= MedicationDoseTolerance {
+				nearSchedule = true
+				break
+			}
+		}
+		if !nearSchedule {
+			warnings = append(warnings, fmt.Sprintf("dose is not within %v of a scheduled time (%s)", MedicationDoseTolerance, strings.Join(authorizationModel.Times, ", ")))
+		}
+	}
+
+	if authorizationModel.MinIntervalMins > 0 {
+		minInterval := time.Duration(authorizationModel.MinIntervalMins) * time.Minute
+		for _, dose := range previous {
+			if gap := at.Sub(dose.AdministeredAt); gap >= 0 && gap < minInterval {
+				warnings = append(warnings, fmt.Sprintf("only %v since the previous dose, at least %v is required", gap.Round(time.Minute), minInterval))
+				break
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MealType is the meal or snack a menu item is served at
+type MealType string
+
+// Meals served each day, in the order they are served
+const (
+	MealBreakfast      MealType = "breakfast"
+	MealMorningSnack   MealType = "morning_snack"
+	MealLunch          MealType = "lunch"
+	MealAfternoonSnack MealType = "afternoon_snack"
+)
+
+var mealOrder = map[MealType]int{MealBreakfast: 0, MealMorningSnack: 1, MealLunch: 2, MealAfternoonSnack: 3}
+
+// ParseMealType validates a meal supplied by a client
+func ParseMealType(value string) (MealType, error) {
+	meal := MealType(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := mealOrder[meal]; !ok {
+		return "", fmt.Errorf("invalid meal %q: must be breakfast, morning_snack, lunch, or afternoon_snack", value)
+	}
+	return meal, nil
+}
+
+// MenuItem is one dish served on a day, tagged with the allergens it contains
+type MenuItem struct {
+	Date      string   `json:"date"` // YYYY-MM-DD within the menu's week
+	Meal      MealType `json:"meal"`
+	Name      string   `json:"name"`
+	Allergens []string `json:"allergens"`
+}
+
+// Menu is the published food plan for the week starting on Week, a Monday
+type Menu struct {
+	Week        string     `json:"week"` // YYYY-MM-DD of the Monday
+	Items       []MenuItem `json:"items"`
+	PublishedBy string     `json:"publishedBy"`
+	PublishedAt time.Time  `json:"publishedAt"`
+}
+
+// AllergenConflict flags a menu item containing something a child is allergic to
+type AllergenConflict struct {
+	Date      string          `json:"date"`
+	Meal      MealType        `json:"meal"`
+	Item      string          `json:"item"`
+	Allergen  string          `json:"allergen"`
+	ChildID   string          `json:"childId"`
+	ChildName string          `json:"childName"`
+	Classroom string          `json:"classroom"`
+	Severity  AllergySeverity `json:"severity"`
+}
+
+// WeekStart returns the Monday of the week containing t
+func WeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func NewMenu(week string, items []MenuItem, publishedBy string, publishedAt time.Time) (*Menu, error) {
+	monday, err := time.Parse(AttendanceDateFormat, week)
+	if err != nil {
+		return nil, errors.New("week must use the YYYY-MM-DD format")
+	}
+	monday = WeekStart(monday)
+	sunday := monday.AddDate(0, 0, 6)
+
+	cleaned := make([]MenuItem, 0, len(items))
+	for _, item := range items {
+		date, err := time.Parse(AttendanceDateFormat, item.Date)
+		if err != nil {
+			return nil, errors.New("menu item dates must use the YYYY-MM-DD format")
+		}
+		if date.Before(monday) || date.After(sunday) {
+			return nil, fmt.Errorf("menu item date %s is outside the week of %s", item.Date, monday.Format(AttendanceDateFormat))
+		}
+		meal, err := ParseMealType(string(item.Meal))
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSpace(item.Name)
+		if name == "" {
+			return nil, errors.New("menu item name is required")
+		}
+		allergens := []string{}
+		for _, allergen := range item.Allergens {
+			if allergen = strings.ToLower(strings.TrimSpace(allergen)); allergen != "" {
+				allergens = append(allergens, allergen)
+			}
+		}
+		cleaned = append(cleaned, MenuItem{Date: item.Date, Meal: meal, Name: name, Allergens: allergens})
+	}
+	sort.SliceStable(cleaned, func(i, j int) bool {
+		if cleaned[i].Date != cleaned[j].Date {
+			return cleaned[i].Date < cleaned[j].Date
+		}
+		return mealOrder[cleaned[i].Meal] < mealOrder[cleaned[j].Meal]
+	})
+
+	return &Menu{
+		Week:        monday.Format(AttendanceDateFormat),
+		Items:       cleaned,
+		PublishedBy: publishedBy,
+		PublishedAt: publishedAt,
+	}, nil
+}
+
+// FindAllergenConflicts matches each item's allergen tags against the children's allergies. Names match when either
+// contains the other, so a "nuts" tag flags a "tree nuts" allergy; extra flags are safer than missed ones
+func FindAllergenConflicts(menu Menu, children []Child) []AllergenConflict {
+	conflicts := []AllergenConflict{}
+	for _, item := range menu.Items {
+		for _, tag := range item.Allergens {
+			for _, child := range children {
+				for _, allergy := range child.Allergies {
+					allergen := strings.ToLower(strings.TrimSpace(allergy.Allergen))
+					if allergen == "" || !(strings.Contains(allergen, tag) || strings.Contains(tag, allergen)) {
+						continue
+					}
+					conflicts = append(conflicts, AllergenConflict{
+						Date:      item.Date,
+						Meal:      item.Meal,
+						Item:      item.Name,
+						Allergen:  allergy.Allergen,
+						ChildID:   child.ID,
+						ChildName: child.Name,
+						Classroom: child.Classroom,
+						Severity:  allergy.Severity,
+					})
+				}
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ThreadKind separates one-to-one conversations from classroom broadcasts
+type ThreadKind string
+
+const (
+	ThreadDirect    ThreadKind = "direct"    // A guardian and a staff member
+	ThreadClassroom ThreadKind = "classroom" // Staff posting to every guardian with a child in the classroom
+)
+
+// Limits on a single message
+const (
+	MaxMessageLength      = 4000
+	MaxMessageAttachments = 5
+)
+
+// Thread is a conversation. Direct threads list their two participants, classroom threads are
+// open to the classroom's guardians and the staff covering it
+type Thread struct {
+	ID             string     `json:"id"`
+	Kind           ThreadKind `json:"kind"`
+	Subject        string     `json:"subject"`
+	Classroom      string     `json:"classroom,omitempty"`
+	ParticipantIDs []string   `json:"participantIds,omitempty"`
+	CreatedBy      string     `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastMessageAt  time.Time  `json:"lastMessageAt"`
+	UnreadCount    int        `json:"unreadCount"` // For the user viewing the thread, not stored
+}
+
+// MessageAttachment references an image the sender has already uploaded
+type MessageAttachment struct {
+	ImageOwner string `json:"imageOwner"`
+	ImageName  string `json:"imageName"`
+}
+
+// Message is a single post in a thread
+type Message struct {
+	ID          string              `json:"id"`
+	ThreadID    string              `json:"threadId"`
+	SenderID    string              `json:"senderId"`
+	Body        string              `json:"body"`
+	Attachments []MessageAttachment `json:"attachments"`
+	SentAt      time.Time           `json:"sentAt"`
+	ReadBy      []string            `json:"readBy"` // Users whose read receipt covers the message, not stored
+}
+
+// ReadReceipt records how far through a thread a user has read and how many messages have arrived since
+type ReadReceipt struct {
+	ThreadID   string    `json:"threadId"`
+	UserID     string    `json:"userId"`
+	LastReadAt time.Time `json:"lastReadAt"`
+	Unread     int       `json:"unread"`
+}
+
+func NewDirectThread(id string, subject string, creatorID string, otherID string, at time.Time) (*Thread, error) {
+	if creatorID == otherID {
+		return nil, errors.New("cannot start a conversation with yourself")
+	}
+	return &Thread{
+		ID:             id,
+		Kind:           ThreadDirect,
+		Subject:        strings.TrimSpace(subject),
+		ParticipantIDs: []string{creatorID, otherID},
+		CreatedBy:      creatorID,
+		CreatedAt:      at,
+		LastMessageAt:  at,
+	}, nil
+}
+
+func NewClassroomThread(id string, subject string, creatorID string, classroom string, at time.Time) (*Thread, error) {
+	subject = strings.TrimSpace(subject)
+	if subject == "" || classroom == "" {
+		return nil, errors.New("classroom broadcasts need a classroom and subject")
+	}
+	return &Thread{
+		ID:            id,
+		Kind:          ThreadClassroom,
+		Subject:       subject,
+		Classroom:     classroom,
+		CreatedBy:     creatorID,
+		CreatedAt:     at,
+		LastMessageAt: at,
+	}, nil
+}
+
+func NewMessage(id string, threadID string, senderID string, body string, attachments []MessageAttachment, at time.Time) (*Message, error) {
+	body = strings.TrimSpace(body)
+	if body == "" && len(attachments) == 0 {
+		return nil, errors.New("message needs a body or an attachment")
+	}
+	if len(body) > MaxMessageLength {
+		return nil, fmt.Errorf("message cannot be longer than %d characters", MaxMessageLength)
+	}
+	if len(attachments) > MaxMessageAttachments {
+		return nil, fmt.Errorf("message cannot have more than %d attachments", MaxMessageAttachments)
+	}
+	for _, attachment := range attachments {
+		if attachment.ImageOwner == "" || attachment.ImageName == "" {
+			return nil, errors.New("attachments need an imageOwner and imageName")
+		}
+	}
+	if attachments == nil {
+		attachments = []MessageAttachment{}
+	}
+	return &Message{
+		ID:          id,
+		ThreadID:    threadID,
+		SenderID:    senderID,
+		Body:        body,
+		Attachments: attachments,
+		SentAt:      at,
+	}, nil
+}
+
+// HasParticipant reports whether a user is one of a direct thread's participants
+func (threadModel Thread) HasParticipant(userID string) bool {
+	for _, id := range threadModel.ParticipantIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CountUnread counts messages from other users sent after the user's last read time
+func CountUnread(messages []Message, userID string, lastReadAt time.Time) int {
+	unread := 0
+	for _, message := range messages {
+		if message.SenderID != userID && message.SentAt.After(lastReadAt) {
+			unread++
+		}
+	}
+	return unread
+}
+
+// FillReadBy sets each message's ReadBy from the thread's read receipts, leaving out the sender
+func FillReadBy(messages []Message, receipts []ReadReceipt) {
+	for i := range messages {
+		messages[i].ReadBy = []string{}
+		for _, receipt := range receipts {
+			if receipt.UserID != messages[i].SenderID && !receipt.LastReadAt.Before(messages[i].SentAt) {
+				messages[i].ReadBy = append(messages[i].ReadBy, receipt.UserID)
+			}
+		}
+	}
+}
+
+// QuietHours is a daily window, as offsets from midnight in the center's time zone, in which message emails are held back.
+// The window may wrap past midnight, and equal Start and End turn quiet hours off
+type QuietHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Contains reports whether t falls inside the quiet window. Start and End are read as clock times on t's day,
+// so the window keeps its clock times on daylight saving days
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.Start == q.End {
+		return false
+	}
+	start, end := ClockTime(t, q.Start), ClockTime(t, q.End)
+	if q.Start < q.End {
+		return !t.Before(start) && t.Before(end)
+	}
+	return !t.Before(start) || t.Before(end)
+}
+
+// StartBefore returns when the quiet window that ends at end began, the day before when the window wraps past midnight
+func (q QuietHours) StartBefore(end time.Time) time.Time {
+	start := ClockTime(end, q.Start)
+	if !start.Before(end) {
+		start = ClockTime(end.AddDate(0, 0, -1), q.Start)
+	}
+	return start
+}
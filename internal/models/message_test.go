@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHours(t *testing.T) {
+	chicago := useChicago(t)
+	overnight := QuietHours{Start: 21 * time.Hour, End: 7 * time.Hour}
+	daytime := QuietHours{Start: 12 * time.Hour, End: 13 * time.Hour}
+
+	tests := []struct {
+		name      string
+		quiet     QuietHours
+		at        time.Time
+		want      bool
+		wantStart time.Time // Checked when set, the window start for a window ending at at
+	}{
+		{name: "overnight before the window", quiet: overnight, at: time.Date(2025, time.June, 4, 20, 59, 0, 0, chicago), want: false},
+		{name: "overnight at the start", quiet: overnight, at: time.Date(2025, time.June, 4, 21, 0, 0, 0, chicago), want: true},
+		{name: "overnight after midnight", quiet: overnight, at: time.Date(2025, time.June, 5, 6, 59, 0, 0, chicago), want: true},
+		{name: "overnight at the end", quiet: overnight, at: time.Date(2025, time.June, 5, 7, 0, 0, 0, chicago), want: false,
+			wantStart: time.Date(2025, time.June, 4, 21, 0, 0, 0, chicago)},
+		{name: "daytime inside", quiet: daytime, at: time.Date(2025, time.June, 4, 12, 30, 0, 0, chicago), want: true},
+		{name: "daytime at the end", quiet: daytime, at: time.Date(2025, time.June, 4, 13, 0, 0, 0, chicago), want: false,
+			wantStart: time.Date(2025, time.June, 4, 12, 0, 0, 0, chicago)},
+		{name: "spring forward morning still quiet", quiet: overnight, at: time.Date(2025, time.March, 9, 6, 30, 0, 0, chicago), want: true},
+		{name: "spring forward ends at seven", quiet: overnight, at: time.Date(2025, time.March, 9, 7, 0, 0, 0, chicago), want: false,
+			wantStart: time.Date(2025, time.March, 8, 21, 0, 0, 0, chicago)},
+		{name: "fall back morning no longer quiet at seven", quiet: overnight, at: time.Date(2025, time.November, 2, 7, 0, 0, 0, chicago), want: false,
+			wantStart: time.Date(2025, time.November, 1, 21, 0, 0, 0, chicago)},
+		{name: "fall back evening quiet from nine", quiet: overnight, at: time.Date(2025, time.November, 2, 21, 0, 0, 0, chicago), want: true},
+		{name: "disabled", quiet: QuietHours{Start: 22 * time.Hour, End: 22 * time.Hour}, at: time.Date(2025, time.June, 4, 22, 0, 0, 0, chicago), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quiet.Contains(tt.at); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+			if tt.wantStart.IsZero() {
+				return
+			}
+			if got := tt.quiet.StartBefore(tt.at); !got.Equal(tt.wantStart) {
+				t.Errorf("StartBefore(%v) = %v, want %v", tt.at, got, tt.wantStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AuthorizedPickup is an adult a guardian has approved to collect their child
+type AuthorizedPickup struct {
+	ID           string    `json:"id"`
+	ChildID      string    `json:"childId"`
+	Name         string    `json:"name"`
+	Relationship string    `json:"relationship"`
+	Phone        string    `json:"phone"`
+	PhotoBlob    string    `json:"-"` // Blob name of the optional photo, served through the pickup photo endpoint
+	AddedBy      string    `json:"addedBy"`
+	AddedAt      time.Time `json:"addedAt"`
+}
+
+// PickupAudit records a checkout attempt and whether the adult was on the child's authorized list
+type PickupAudit struct {
+	ChildID        string    `json:"childId"`
+	AttendanceID   string    `json:"attendanceId,omitempty"`
+	PickedUpBy     string    `json:"pickedUpBy"`
+	PickupID       string    `json:"pickupId,omitempty"` // Matching authorized pickup or guardian user ID
+	Authorized     bool      `json:"authorized"`
+	Allowed        bool      `json:"allowed"`
+	OverrideReason string    `json:"overrideReason,omitempty"`
+	StaffID        string    `json:"staffId"`
+	At             time.Time `json:"at"`
+}
+
+func NewAuthorizedPickup(id string, childID string, name string, relationship string, phone string, addedBy string) (*AuthorizedPickup, error) {
+	name = strings.TrimSpace(name)
+	phone = strings.TrimSpace(phone)
+	if name == "" {
+		return nil, errors.New("authorized pickup name is required")
+	}
+	if phone == "" {
+		return nil, errors.New("authorized pickup phone number is required")
+	}
+	return &AuthorizedPickup{
+		ID:           id,
+		ChildID:      childID,
+		Name:         name,
+		Relationship: strings.TrimSpace(relationship),
+		Phone:        phone,
+		AddedBy:      addedBy,
+		AddedAt:      time.Now(),
+	}, nil
+}
+
+// MatchesName reports whether a name given at checkout refers to this person
+func (pickup AuthorizedPickup) MatchesName(name string) bool {
+	return NamesMatch(pickup.Name, name)
+}
+
+// NamesMatch compares two people's names ignoring case and spacing
+func NamesMatch(a string, b string) bool {
+	a = strings.Join(strings.Fields(a), " ")
+	b = strings.Join(strings.Fields(b), " ")
+	return a != "" && strings.EqualFold(a, b)
+}
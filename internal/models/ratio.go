@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// RatioRule is the licensing ratio for children within an age band, MaxAgeMonths of 0 means no upper bound
+type RatioRule struct {
+	MinAgeMonths     int `json:"minAgeMonths"`
+	MaxAgeMonths     int `json:"maxAgeMonths"`
+	ChildrenPerStaff int `json:"childrenPerStaff"`
+}
+
+// DefaultRatioRules apply to classrooms that have not configured their own
+var DefaultRatioRules = []RatioRule{
+	{MinAgeMonths: 0, MaxAgeMonths: 18, ChildrenPerStaff: 4},
+	{MinAgeMonths: 18, MaxAgeMonths: 36, ChildrenPerStaff: 6},
+	{MinAgeMonths: 36, MaxAgeMonths: 60, ChildrenPerStaff: 10},
+	{MinAgeMonths: 60, MaxAgeMonths: 0, ChildrenPerStaff: 15},
+}
+
+// ClassroomRatio is the live staff-to-child compliance of one classroom
+type ClassroomRatio struct {
+	Classroom       string    `json:"classroom"`
+	ClassroomName   string    `json:"classroomName"`
+	ChildrenPresent int       `json:"childrenPresent"`
+	StaffPresent    int       `json:"staffPresent"`
+	StaffRequired   int       `json:"staffRequired"`
+	InRatio         bool      `json:"inRatio"`
+	CheckedAt       time.Time `json:"checkedAt"`
+}
+
+// ValidateRatioRules checks configured rules before they are stored on a classroom
+func ValidateRatioRules(rules []RatioRule) error {
+	for _, rule := range rules {
+		if rule.ChildrenPerStaff <= 0 {
+			return errors.New("childrenPerStaff must be greater than zero")
+		}
+		if rule.MinAgeMonths < 0 || (rule.MaxAgeMonths != 0 && rule.MaxAgeMonths <= rule.MinAgeMonths) {
+			return errors.New("ratio rule age range is invalid")
+		}
+	}
+	return nil
+}
+
+// RequiredStaff returns how many staff the children present need. Each child takes up 1/ChildrenPerStaff
+// of a staff member according to the rule for their age, so mixed-age rooms are weighted by their youngest children.
+// Children outside every rule, or with an unknown age, are counted against the strictest rule
+func RequiredStaff(rules []RatioRule, childAgesInMonths []int) int {
+	if len(childAgesInMonths) == 0 {
+		return 0
+	}
+	if len(rules) == 0 {
+		rules = DefaultRatioRules
+	}
+	strictest := rules[0].ChildrenPerStaff
+	for _, rule := range rules {
+		if rule.ChildrenPerStaff < strictest {
+			strictest = rule.ChildrenPerStaff
+		}
+	}
+
+	load := 0.0
+	for _, age := range childAgesInMonths {
+		perStaff := strictest
+		for _, rule := range rules {
+			if age >= rule.MinAgeMonths && (rule.MaxAgeMonths == 0 || age < rule.MaxAgeMonths) {
+				perStaff = rule.ChildrenPerStaff
+				break
+			}
+		}
+		load += 1.0 / float64(perStaff)
+	}
+	// Round away floating point noise before taking the ceiling
+	return int(math.Ceil(math.Round(load*1e6) / 1e6))
+}
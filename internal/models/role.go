@@ -0,0 +1,82 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// Role identifies what a user is allowed to do
+type Role string
+
+// Valid user roles
+const (
+	RoleAdmin    Role = "admin"
+	RoleDirector Role = "director"
+	RoleTeacher  Role = "teacher"
+	RoleParent   Role = "parent"
+)
+
+// Permission names an action that is checked against a user's role
+type Permission string
+
+const (
+	PermissionManageUsers  Permission = "manage_users"
+	PermissionManageRoles  Permission = "manage_roles"
+	PermissionManageEvents Permission = "manage_events"
+	PermissionManagePhotos Permission = "manage_photos"
+	// PermissionManageChildren covers child records and guardian links
+	PermissionManageChildren Permission = "manage_children"
+	// PermissionManageClassrooms covers creating classrooms and assigning teachers to them
+	PermissionManageClassrooms Permission = "manage_classrooms"
+	// PermissionManageAttendance covers recording drop-off and pickup
+	PermissionManageAttendance Permission = "manage_attendance"
+	// PermissionOverridePickup allows releasing a child to an adult missing from its pickup list when a reason is recorded
+	PermissionOverridePickup Permission = "override_pickup"
+	// PermissionManageBilling covers tuition plans and generating, voiding and viewing every family's invoices
+	PermissionManageBilling Permission = "manage_billing"
+)
+
+// rolePermissions lists what each role may do; teacher permissions only apply to their own classrooms
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin:    {PermissionManageUsers, PermissionManageRoles, PermissionManageEvents, PermissionManagePhotos, PermissionManageChildren, PermissionManageClassrooms, PermissionManageAttendance, PermissionOverridePickup, PermissionManageBilling},
+	RoleDirector: {PermissionManageUsers, PermissionManageEvents, PermissionManagePhotos, PermissionManageChildren, PermissionManageClassrooms, PermissionManageAttendance, PermissionManageBilling},
+	RoleTeacher:  {PermissionManageEvents, PermissionManagePhotos, PermissionManageChildren, PermissionManageAttendance},
+	RoleParent:   {},
+}
+
+// ParseRole validates a role name supplied by a client
+func ParseRole(value string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := rolePermissions[role]; !ok {
+		return "", errors.New("invalid role: must be admin, director, teacher, or parent")
+	}
+	return role, nil
+}
+
+// NormalizeRole maps stored role strings, including legacy values such as "user" and "Parent", onto a Role
+func NormalizeRole(value string) Role {
+	if role, err := ParseRole(value); err == nil {
+		return role
+	}
+	return RoleParent
+}
+
+// Can reports whether the role grants a permission
+func (r Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaff reports whether the role belongs to center staff
+func (r Role) IsStaff() bool {
+	return r == RoleAdmin || r == RoleDirector || r == RoleTeacher
+}
+
+// IsClassroomScoped reports whether the role's permissions are limited to assigned classrooms
+func (r Role) IsClassroomScoped() bool {
+	return r == RoleTeacher
+}
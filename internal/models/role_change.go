@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RoleChange records who changed a user's role and when
+type RoleChange struct {
+	UserID    string    `json:"userId"`
+	OldRole   Role      `json:"oldRole"`
+	NewRole   Role      `json:"newRole"`
+	ChangedBy string    `json:"changedBy"`
+	ChangedAt time.Time `json:"changedAt"`
+}
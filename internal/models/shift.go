@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Shift is a staff member's time on the floor in a classroom, from clock-in to clock-out
+type Shift struct {
+	ID         string    `json:"id"`
+	StaffID    string    `json:"staffId"`
+	Classroom  string    `json:"classroom"`
+	Date       string    `json:"date"` // YYYY-MM-DD, same layout as attendance dates
+	ClockInAt  time.Time `json:"clockInAt"`
+	ClockOutAt time.Time `json:"clockOutAt,omitempty"`
+}
+
+// IsOpen reports whether the staff member is still clocked in
+func (shift Shift) IsOpen() bool {
+	return shift.ClockOutAt.IsZero()
+}
@@ -5,14 +5,15 @@ import (
 )
 
 type User struct {
-	ID     string
-	Name   string
-	Email  string
-	Role   string
-	Images []string
+	ID         string
+	Name       string
+	Email      string
+	Role       Role
+	Images     []string
+	Classrooms []string // Classroom IDs a teacher is assigned to
 }
 
-func NewUser(id string, name string, email string, role string, images []string) *User {
+func NewUser(id string, name string, email string, role Role, images []string) *User {
 	return &User{
 		ID:     id,
 		Name:   name,
@@ -22,6 +23,7 @@ func NewUser(id string, name string, email string, role string, images []string)
 	}
 }
 
+// Update copies profile fields onto the user, Role and Classrooms are only changed through the role endpoint
 func (userModel *User) Update(newUserData User) error {
 
 	if newUserData.ID != userModel.ID {
@@ -33,9 +35,6 @@ func (userModel *User) Update(newUserData User) error {
 	if newUserData.Email != "" {
 		userModel.Email = newUserData.Email
 	}
-	if newUserData.Role != "" {
-		userModel.Role = newUserData.Role
-	}
 
 	if newUserData.Images != nil {
 		if len(newUserData.Images) >= 3 {
@@ -63,6 +62,16 @@ func (userModel *User) Update(newUserData User) error {
 	return nil
 }
 
+// HasClassroom reports whether the user is assigned to the classroom
+func (userModel *User) HasClassroom(classroomID string) bool {
+	for _, id := range userModel.Classrooms {
+		if id == classroomID {
+			return true
+		}
+	}
+	return false
+}
+
 func (userModel *User) UpdateImages(newUserData User) error {
 	if len(newUserData.Images) >= 3 {
 		return errors.New("User Max Number of Images exceeded")
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ApplicationStatus is where an enrollment application is in intake
+type ApplicationStatus string
+
+// Applications move applied → toured → offered → enrolled, and may be declined at any step before enrollment
+const (
+	ApplicationApplied  ApplicationStatus = "applied"
+	ApplicationToured   ApplicationStatus = "toured"
+	ApplicationOffered  ApplicationStatus = "offered"
+	ApplicationEnrolled ApplicationStatus = "enrolled"
+	ApplicationDeclined ApplicationStatus = "declined"
+)
+
+// applicationTransitions lists the statuses each status may move to
+var applicationTransitions = map[ApplicationStatus][]ApplicationStatus{
+	ApplicationApplied: {ApplicationToured, ApplicationOffered, ApplicationDeclined},
+	ApplicationToured:  {ApplicationOffered, ApplicationDeclined},
+	ApplicationOffered: {ApplicationEnrolled, ApplicationDeclined},
+}
+
+// ParseApplicationStatus validates a status supplied by a client
+func ParseApplicationStatus(value string) (ApplicationStatus, error) {
+	status := ApplicationStatus(strings.ToLower(strings.TrimSpace(value)))
+	switch status {
+	case ApplicationApplied, ApplicationToured, ApplicationOffered, ApplicationEnrolled, ApplicationDeclined:
+		return status, nil
+	}
+	return "", fmt.Errorf("invalid application status %q: must be applied, toured, offered, enrolled, or declined", value)
+}
+
+// Age groups applications are ordered within, by the child's age on the desired start date
+const (
+	AgeGroupInfant    = "infant"     // under 12 months
+	AgeGroupToddler   = "toddler"    // 12 to 35 months
+	AgeGroupPreschool = "preschool"  // 3 and 4 year olds
+	AgeGroupSchoolAge = "school_age" // 5 and up
+)
+
+// Application is a prospective family's request for a place, held on the waitlist for its age group
+type Application struct {
+	ID             string            `json:"id"`
+	ParentName     string            `json:"parentName"`
+	Email          string            `json:"email"`
+	Phone          string            `json:"phone"`
+	ChildName      string            `json:"childName"`
+	ChildBirthdate string            `json:"childBirthdate"` // YYYY-MM-DD
+	DesiredStart   string            `json:"desiredStart"`   // YYYY-MM-DD
+	AgeGroup       string            `json:"ageGroup"`
+	Notes          string            `json:"notes"`
+	Status         ApplicationStatus `json:"status"`
+	Rank           int               `json:"rank"` // Position within the age group's waitlist, lowest first
+	SubmittedAt    time.Time         `json:"submittedAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+	ChildID        string            `json:"childId,omitempty"` // Child record created on enrollment
+}
+
+func NewApplication(id string, parentName string, email string, phone string, childName string, childBirthdate string, desiredStart string, notes string) (*Application, error) {
+	parentName = strings.TrimSpace(parentName)
+	childName = strings.TrimSpace(childName)
+	if parentName == "" || childName == "" {
+		return nil, errors.New("parent and child names are required")
+	}
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return nil, errors.New("a valid email address is required")
+	}
+	birthdate, err := time.Parse(AttendanceDateFormat, childBirthdate)
+	if err != nil {
+		return nil, errors.New("childBirthdate must use the YYYY-MM-DD format")
+	}
+	start, err := time.Parse(AttendanceDateFormat, desiredStart)
+	if err != nil {
+		return nil, errors.New("desiredStart must use the YYYY-MM-DD format")
+	}
+	if start.Before(birthdate) {
+		return nil, errors.New("desiredStart cannot be before the child's birthdate")
+	}
+	now := time.Now()
+	return &Application{
+		ID:             id,
+		ParentName:     parentName,
+		Email:          strings.ToLower(address.Address),
+		Phone:          strings.TrimSpace(phone),
+		ChildName:      childName,
+		ChildBirthdate: childBirthdate,
+		DesiredStart:   desiredStart,
+		AgeGroup:       AgeGroupFor(childBirthdate, start),
+		Notes:          strings.TrimSpace(notes),
+		Status:         ApplicationApplied,
+		SubmittedAt:    now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// AgeGroupFor returns the age group of a child born on birthdate when they start on the given day
+func AgeGroupFor(birthdate string, at time.Time) string {
+	child := Child{Birthdate: birthdate}
+	months := child.AgeInMonths(at)
+	switch {
+	case months < 12:
+		return AgeGroupInfant
+	case months < 36:
+		return AgeGroupToddler
+	case months < 60:
+		return AgeGroupPreschool
+	}
+	return AgeGroupSchoolAge
+}
+
+// Update applies partial changes from an admin, recomputing the age group if the desired start moves
+func (applicationModel *Application) Update(newData Application) error {
+	if newData.ID != applicationModel.ID {
+		return errors.New("Invalid ID when trying to update fields in Application")
+	}
+	if newData.DesiredStart != "" {
+		start, err := time.Parse(AttendanceDateFormat, newData.DesiredStart)
+		if err != nil {
+			return errors.New("desiredStart must use the YYYY-MM-DD format")
+		}
+		applicationModel.DesiredStart = newData.DesiredStart
+		applicationModel.AgeGroup = AgeGroupFor(applicationModel.ChildBirthdate, start)
+	}
+	if newData.Rank > 0 {
+		applicationModel.Rank = newData.Rank
+	}
+	if newData.Phone != "" {
+		applicationModel.Phone = newData.Phone
+	}
+	if newData.Notes != "" {
+		applicationModel.Notes = newData.Notes
+	}
+	applicationModel.UpdatedAt = time.Now()
+	return nil
+}
+
+// Transition moves the application to a new status if the workflow allows it
+func (applicationModel *Application) Transition(status ApplicationStatus, at time.Time) error {
+	for _, allowed := range applicationTransitions[applicationModel.Status] {
+		if allowed == status {
+			applicationModel.Status = status
+			applicationModel.UpdatedAt = at
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot move application from %s to %s", applicationModel.Status, status)
+}
+
+// IsWaiting reports whether the application is still on the waitlist
+func (applicationModel Application) IsWaiting() bool {
+	return applicationModel.Status != ApplicationEnrolled && applicationModel.Status != ApplicationDeclined
+}
@@ -0,0 +1,231 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// Partition key shared by all album rows, photos are partitioned by album ID
+const AlbumPartitionKey = "Albums"
+
+// AlbumRepository stores photo albums and the photos in them
+type AlbumRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewAlbumRepo creates and returns a new AlbumRepository object
+func NewAlbumRepo(cfg config.AzTableConfig) (services.AlbumRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("AlbumRepository.NewAlbumRepo: %w", err)
+	}
+	return &AlbumRepository{serviceClient: *client}, nil
+}
+
+// GetAlbum retrieves a single album
+func (repo *AlbumRepository) GetAlbum(tableName string, id string) (models.Album, error) {
+	myEntity, err := repo.getEntity(tableName, AlbumPartitionKey, id)
+	if err != nil {
+		return models.Album{}, fmt.Errorf("AlbumRepository.GetAlbum: %w", err)
+	}
+	return albumFromEntity(myEntity), nil
+}
+
+// GetAlbums returns every album, newest first
+func (repo *AlbumRepository) GetAlbums(tableName string) ([]models.Album, error) {
+	albums := []models.Album{}
+	err := repo.listEntities(tableName, fmt.Sprintf("PartitionKey eq '%s'", AlbumPartitionKey), func(myEntity aztables.EDMEntity) {
+		albums = append(albums, albumFromEntity(myEntity))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AlbumRepository.GetAlbums: %w", err)
+	}
+	sort.Slice(albums, func(i, j int) bool {
+		return albums[i].CreatedAt.After(albums[j].CreatedAt)
+	})
+	return albums, nil
+}
+
+// UpsertAlbum creates or replaces an album, creating the table if it doesn't exist
+func (repo *AlbumRepository) UpsertAlbum(tableName string, album models.Album) error {
+	albumEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: AlbumPartitionKey,
+			RowKey:       album.ID,
+		},
+		Properties: map[string]any{
+			"Title":     album.Title,
+			"EventID":   album.EventID,
+			"Classroom": album.Classroom,
+			"CreatedBy": album.CreatedBy,
+			"CreatedAt": album.CreatedAt.UTC().Format(time.RFC3339),
+		},
+	}
+	if err := repo.upsertEntity(tableName, albumEntity); err != nil {
+		return fmt.Errorf("AlbumRepository.UpsertAlbum: %w", err)
+	}
+	return nil
+}
+
+// GetPhoto retrieves a single photo from an album
+func (repo *AlbumRepository) GetPhoto(tableName string, albumID string, id string) (models.AlbumPhoto, error) {
+	myEntity, err := repo.getEntity(tableName, albumID, id)
+	if err != nil {
+		return models.AlbumPhoto{}, fmt.Errorf("AlbumRepository.GetPhoto: %w", err)
+	}
+	return photoFromEntity(myEntity), nil
+}
+
+// GetPhotos returns photos matching an OData filter, such as one album's partition, oldest first
+func (repo *AlbumRepository) GetPhotos(tableName string, filter string) ([]models.AlbumPhoto, error) {
+	photos := []models.AlbumPhoto{}
+	err := repo.listEntities(tableName, filter, func(myEntity aztables.EDMEntity) {
+		photos = append(photos, photoFromEntity(myEntity))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AlbumRepository.GetPhotos: %w", err)
+	}
+	sort.Slice(photos, func(i, j int) bool {
+		return photos[i].UploadedAt.Before(photos[j].UploadedAt)
+	})
+	return photos, nil
+}
+
+// UpsertPhoto creates or replaces a photo record, creating the table if it doesn't exist
+func (repo *AlbumRepository) UpsertPhoto(tableName string, photo models.AlbumPhoto) error {
+	photoEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: photo.AlbumID,
+			RowKey:       photo.ID,
+		},
+		Properties: map[string]any{
+			"BlobName":    photo.BlobName,
+			"ContentType": photo.ContentType,
+			"Caption":     photo.Caption,
+			"ChildIDs":    strings.Join(photo.ChildIDs, ","),
+			"Visibility":  string(photo.Visibility),
+			"UploadedBy":  photo.UploadedBy,
+			"UploadedAt":  photo.UploadedAt.UTC().Format(time.RFC3339),
+		},
+	}
+	if err := repo.upsertEntity(tableName, photoEntity); err != nil {
+		return fmt.Errorf("AlbumRepository.UpsertPhoto: %w", err)
+	}
+	return nil
+}
+
+// DeletePhoto removes a photo record
+func (repo *AlbumRepository) DeletePhoto(tableName string, albumID string, id string) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	options := &aztables.DeleteEntityOptions{
+		IfMatch: to.Ptr(azcore.ETagAny),
+	}
+	_, err := tableClient.DeleteEntity(context.Background(), albumID, id, options)
+	if err != nil {
+		return fmt.Errorf("AlbumRepository.DeletePhoto: Failed to delete photo %s from %s: %w", id, tableName, err)
+	}
+	return nil
+}
+
+// Helper - fetch and decode a single entity
+func (repo *AlbumRepository) getEntity(tableName string, partitionKey string, rowKey string) (aztables.EDMEntity, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	resp, err := tableClient.GetEntity(context.Background(), partitionKey, rowKey, nil)
+	if err != nil {
+		return aztables.EDMEntity{}, fmt.Errorf("Failed to retrieve %s/%s from %s: %w", partitionKey, rowKey, tableName, err)
+	}
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(resp.Value, &myEntity); err != nil {
+		return aztables.EDMEntity{}, fmt.Errorf("Failed to deserialize entity: %w", err)
+	}
+	return myEntity, nil
+}
+
+// Helper - replace an entity, creating the table if it doesn't exist
+func (repo *AlbumRepository) upsertEntity(tableName string, entity aztables.EDMEntity) error {
+	serializedEntity, err := json.Marshal(entity)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize entity: %w", err)
+	}
+
+	// Table may already exist, error is expected in that case
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{
+		UpdateMode: aztables.UpdateModeReplace,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// Helper - page through the entities matching a filter, treating a missing table as empty
+func (repo *AlbumRepository) listEntities(tableName string, filter string, each func(aztables.EDMEntity)) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	options := &aztables.ListEntitiesOptions{}
+	if filter != "" {
+		options.Filter = &filter
+	}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("Failed to acquire next page: %w", err)
+		}
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return fmt.Errorf("Failed to unmarshal entity: %w", err)
+			}
+			each(myEntity)
+		}
+	}
+	return nil
+}
+
+// Helper - map a table entity onto an Album
+func albumFromEntity(myEntity aztables.EDMEntity) models.Album {
+	album := models.Album{ID: myEntity.RowKey}
+	album.Title, _ = myEntity.Properties["Title"].(string)
+	album.EventID, _ = myEntity.Properties["EventID"].(string)
+	album.Classroom, _ = myEntity.Properties["Classroom"].(string)
+	album.CreatedBy, _ = myEntity.Properties["CreatedBy"].(string)
+	if createdAt, ok := myEntity.Properties["CreatedAt"].(string); ok {
+		album.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	}
+	return album
+}
+
+// Helper - map a table entity onto an AlbumPhoto
+func photoFromEntity(myEntity aztables.EDMEntity) models.AlbumPhoto {
+	photo := models.AlbumPhoto{ID: myEntity.RowKey, AlbumID: myEntity.PartitionKey}
+	photo.BlobName, _ = myEntity.Properties["BlobName"].(string)
+	photo.ContentType, _ = myEntity.Properties["ContentType"].(string)
+	photo.Caption, _ = myEntity.Properties["Caption"].(string)
+	childIDs, _ := myEntity.Properties["ChildIDs"].(string)
+	photo.ChildIDs = splitCSV(childIDs)
+	visibility, _ := myEntity.Properties["Visibility"].(string)
+	photo.Visibility = models.PhotoVisibility(visibility)
+	photo.UploadedBy, _ = myEntity.Properties["UploadedBy"].(string)
+	if uploadedAt, ok := myEntity.Properties["UploadedAt"].(string); ok {
+		photo.UploadedAt, _ = time.Parse(time.RFC3339, uploadedAt)
+	}
+	return photo
+}
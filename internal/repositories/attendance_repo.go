@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// AttendanceRepository stores attendance records partitioned by date, one row per visit
+type AttendanceRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewAttendanceRepo creates and returns a new AttendanceRepository object
+func NewAttendanceRepo(cfg config.AzTableConfig) (services.AttendanceRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("AttendanceRepository.NewAttendanceRepo: %w", err)
+	}
+	return &AttendanceRepository{serviceClient: *client}, nil
+}
+
+// CreateRecord adds a check-in, creating the table if it doesn't exist
+func (repo *AttendanceRepository) CreateRecord(tableName string, record models.AttendanceRecord) error {
+	serializedEntity, err := json.Marshal(attendanceToEntity(record))
+	if err != nil {
+		return fmt.Errorf("AttendanceRepository.CreateRecord: Failed to serialize record: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if isConflict(err) {
+		return fmt.Errorf("AttendanceRepository.CreateRecord: Visit %s is already recorded: %w", record.ID, services.ErrAlreadyCheckedIn)
+	}
+	if err != nil {
+		return fmt.Errorf("AttendanceRepository.CreateRecord: Failed to add entity to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// UpdateRecord replaces a stored record only if it is unchanged since it was read, matching on record.ETag.
+// Used to record a checkout, so two staff checking out the same visit cannot both succeed
+func (repo *AttendanceRepository) UpdateRecord(tableName string, record models.AttendanceRecord) error {
+	if record.ETag == "" {
+		return fmt.Errorf("AttendanceRepository.UpdateRecord: Visit %s has no ETag", record.ID)
+	}
+	serializedEntity, err := json.Marshal(attendanceToEntity(record))
+	if err != nil {
+		return fmt.Errorf("AttendanceRepository.UpdateRecord: Failed to serialize record: %w", err)
+	}
+	tableClient := repo.serviceClient.NewClient(tableName)
+	etag := azcore.ETag(record.ETag)
+	_, err = tableClient.UpdateEntity(context.Background(), serializedEntity, &aztables.UpdateEntityOptions{
+		IfMatch:    &etag,
+		UpdateMode: aztables.UpdateModeReplace,
+	})
+	if isPreconditionFailed(err) {
+		return fmt.Errorf("AttendanceRepository.UpdateRecord: Visit %s was changed by another request: %w", record.ID, services.ErrNotCheckedIn)
+	}
+	if err != nil {
+		return fmt.Errorf("AttendanceRepository.UpdateRecord: Failed to update entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// GetRecords returns the records for a date ordered by check-in time, optionally narrowed by an OData filter
+func (repo *AttendanceRepository) GetRecords(tableName string, date string, filter string) ([]models.AttendanceRecord, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	query := fmt.Sprintf("PartitionKey eq '%s'", date)
+	if filter != "" {
+		query = fmt.Sprintf("%s and %s", query, filter)
+	}
+	options := &aztables.ListEntitiesOptions{
+		Filter: &query,
+	}
+	records := []models.AttendanceRecord{}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return records, nil
+			}
+			return nil, fmt.Errorf("AttendanceRepository.GetRecords: Failed to acquire next page: %w", err)
+		}
+
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("AttendanceRepository.GetRecords: Failed to unmarshal entity: %w", err)
+			}
+			records = append(records, attendanceFromEntity(myEntity))
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CheckInAt.Before(records[j].CheckInAt)
+	})
+	return records, nil
+}
+
+// Helper - build the table entity for an AttendanceRecord
+func attendanceToEntity(record models.AttendanceRecord) aztables.EDMEntity {
+	checkOutAt := ""
+	if record.IsCheckedOut() {
+		checkOutAt = record.CheckOutAt.UTC().Format(time.RFC3339)
+	}
+	return aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: record.Date,
+			RowKey:       record.ID,
+		},
+		Properties: map[string]any{
+			"ChildID":         record.ChildID,
+			"Classroom":       record.Classroom,
+			"DroppedOffBy":    record.DroppedOffBy,
+			"CheckInAt":       record.CheckInAt.UTC().Format(time.RFC3339),
+			"CheckInStaffID":  record.CheckInStaffID,
+			"PickedUpBy":      record.PickedUpBy,
+			"CheckOutAt":      checkOutAt,
+			"CheckOutStaffID": record.CheckOutStaffID,
+		},
+	}
+}
+
+// Helper - map a table entity onto an AttendanceRecord
+func attendanceFromEntity(myEntity aztables.EDMEntity) models.AttendanceRecord {
+	record := models.AttendanceRecord{ID: myEntity.RowKey, Date: myEntity.PartitionKey, ETag: myEntity.ETag}
+	record.ChildID, _ = myEntity.Properties["ChildID"].(string)
+	record.Classroom, _ = myEntity.Properties["Classroom"].(string)
+	record.DroppedOffBy, _ = myEntity.Properties["DroppedOffBy"].(string)
+	record.CheckInStaffID, _ = myEntity.Properties["CheckInStaffID"].(string)
+	record.PickedUpBy, _ = myEntity.Properties["PickedUpBy"].(string)
+	record.CheckOutStaffID, _ = myEntity.Properties["CheckOutStaffID"].(string)
+	if checkInAt, ok := myEntity.Properties["CheckInAt"].(string); ok {
+		record.CheckInAt, _ = time.Parse(time.RFC3339, checkInAt)
+	}
+	if checkOutAt, ok := myEntity.Properties["CheckOutAt"].(string); ok && checkOutAt != "" {
+		record.CheckOutAt, _ = time.Parse(time.RFC3339, checkOutAt)
+	}
+	return record
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"net/http"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// newServiceClient builds an aztables service client, using Managed Identity in production and shared key credentials otherwise
+func newServiceClient(cfg config.AzTableConfig) (*aztables.ServiceClient, error) {
+
+	if os.Getenv("APP_ENV") == "production" {
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Default Azure Credential for Managed Identity: %w", err)
+		}
+		client, err := aztables.NewServiceClient(cfg.AzureContainerName, cred, nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to initialize Default Credential service client: %w", err)
+		}
+		return client, nil
+	}
+
+	cred, err := aztables.NewSharedKeyCredential(cfg.AzureAccountName, cfg.AzureAccountKey)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create credentials: %w", err)
+	}
+	client, err := aztables.NewServiceClientWithSharedKey(cfg.AzureContainerName, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to initialize service client: %w", err)
+	}
+	return client, nil
+}
+
+// Helper - check whether a table error is a 404 response
+func isNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
+
+// Helper - check whether a table error reports that the table doesn't exist yet, which list queries treat as empty
+func isTableNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.ErrorCode == string(aztables.TableNotFound)
+}
+
+// Helper - check whether a table error is a 412 response, an If-Match ETag that no longer matches the stored row
+func isPreconditionFailed(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusPreconditionFailed
+}
+
+// Helper - check whether a table error is a 409 response, such as adding a row that already exists
+func isConflict(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusConflict
+}
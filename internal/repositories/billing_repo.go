@@ -0,0 +1,302 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// TuitionPlanPartitionKey is the single partition tuition plans are stored under, keyed by child ID
+const TuitionPlanPartitionKey = "TuitionPlans"
+
+// InvoicePartitionKey is the single partition invoices are stored under
+const InvoicePartitionKey = "Invoices"
+
+// BillingRepository stores tuition plans and invoices
+type BillingRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewBillingRepo creates and returns a new BillingRepository object
+func NewBillingRepo(cfg config.AzTableConfig) (services.BillingRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("BillingRepository.NewBillingRepo: %w", err)
+	}
+	return &BillingRepository{serviceClient: *client}, nil
+}
+
+// GetPlan returns a child's tuition plan
+func (repo *BillingRepository) GetPlan(tableName string, childID string) (models.TuitionPlan, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	response, err := tableClient.GetEntity(context.Background(), TuitionPlanPartitionKey, childID, nil)
+	if err != nil {
+		return models.TuitionPlan{}, fmt.Errorf("BillingRepository.GetPlan: Failed to get tuition plan for child %s: %w", childID, err)
+	}
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(response.Value, &myEntity); err != nil {
+		return models.TuitionPlan{}, fmt.Errorf("BillingRepository.GetPlan: Failed to unmarshal entity: %w", err)
+	}
+	return tuitionPlanFromEntity(myEntity), nil
+}
+
+// GetAllPlans returns every tuition plan
+func (repo *BillingRepository) GetAllPlans(tableName string) ([]models.TuitionPlan, error) {
+	plans := []models.TuitionPlan{}
+	err := repo.list(tableName, fmt.Sprintf("PartitionKey eq '%s'", TuitionPlanPartitionKey), func(myEntity aztables.EDMEntity) error {
+		plans = append(plans, tuitionPlanFromEntity(myEntity))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("BillingRepository.GetAllPlans: %w", err)
+	}
+	return plans, nil
+}
+
+// UpsertPlan adds or replaces a child's tuition plan, creating the table if it doesn't exist
+func (repo *BillingRepository) UpsertPlan(tableName string, plan models.TuitionPlan) error {
+	planEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: TuitionPlanPartitionKey,
+			RowKey:       plan.ChildID,
+		},
+		Properties: map[string]any{
+			"FamilyID":               plan.FamilyID,
+			"Frequency":              string(plan.Frequency),
+			"RateCents":              aztables.EDMInt64(plan.RateCents),
+			"SiblingDiscountPercent": int32(plan.SiblingDiscountPercent),
+			"StartDate":              plan.StartDate,
+			"EndDate":                plan.EndDate,
+		},
+	}
+	return repo.upsert(tableName, planEntity, "UpsertPlan")
+}
+
+// CreateInvoice adds a new invoice, mapping a clash with an existing invoice ID to services.ErrInvoiceExists
+func (repo *BillingRepository) CreateInvoice(tableName string, invoice models.Invoice) error {
+	invoiceEntity, err := invoiceToEntity(invoice)
+	if err != nil {
+		return fmt.Errorf("BillingRepository.CreateInvoice: %w", err)
+	}
+	serializedEntity, err := json.Marshal(invoiceEntity)
+	if err != nil {
+		return fmt.Errorf("BillingRepository.CreateInvoice: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if isConflict(err) {
+		return fmt.Errorf("BillingRepository.CreateInvoice: Invoice %s already exists: %w", invoice.ID, services.ErrInvoiceExists)
+	}
+	if err != nil {
+		return fmt.Errorf("BillingRepository.CreateInvoice: Failed to add entity to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// UpdateInvoice replaces a stored invoice only if it is unchanged since it was read, matching on invoice.ETag
+func (repo *BillingRepository) UpdateInvoice(tableName string, invoice models.Invoice) error {
+	if invoice.ETag == "" {
+		return fmt.Errorf("BillingRepository.UpdateInvoice: Invoice %s has no ETag", invoice.ID)
+	}
+	invoiceEntity, err := invoiceToEntity(invoice)
+	if err != nil {
+		return fmt.Errorf("BillingRepository.UpdateInvoice: %w", err)
+	}
+	serializedEntity, err := json.Marshal(invoiceEntity)
+	if err != nil {
+		return fmt.Errorf("BillingRepository.UpdateInvoice: Failed to serialize entity: %w", err)
+	}
+
+	tableClient := repo.serviceClient.NewClient(tableName)
+	etag := azcore.ETag(invoice.ETag)
+	_, err = tableClient.UpdateEntity(context.Background(), serializedEntity, &aztables.UpdateEntityOptions{
+		IfMatch:    &etag,
+		UpdateMode: aztables.UpdateModeReplace,
+	})
+	if isPreconditionFailed(err) {
+		return fmt.Errorf("BillingRepository.UpdateInvoice: Invoice %s was changed by another request: %w", invoice.ID, services.ErrInvoiceState)
+	}
+	if err != nil {
+		return fmt.Errorf("BillingRepository.UpdateInvoice: Failed to update entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// GetInvoice returns a single invoice
+func (repo *BillingRepository) GetInvoice(tableName string, id string) (models.Invoice, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	response, err := tableClient.GetEntity(context.Background(), InvoicePartitionKey, id, nil)
+	if err != nil {
+		return models.Invoice{}, fmt.Errorf("BillingRepository.GetInvoice: Failed to get invoice %s: %w", id, err)
+	}
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(response.Value, &myEntity); err != nil {
+		return models.Invoice{}, fmt.Errorf("BillingRepository.GetInvoice: Failed to unmarshal entity: %w", err)
+	}
+	myEntity.ETag = string(response.ETag)
+	return invoiceFromEntity(myEntity)
+}
+
+// GetInvoices returns the invoices matching an extra OData filter, newest month first
+func (repo *BillingRepository) GetInvoices(tableName string, filter string) ([]models.Invoice, error) {
+	query := fmt.Sprintf("PartitionKey eq '%s'", InvoicePartitionKey)
+	if filter != "" {
+		query = fmt.Sprintf("%s and %s", query, filter)
+	}
+
+	invoices := []models.Invoice{}
+	err := repo.list(tableName, query, func(myEntity aztables.EDMEntity) error {
+		invoice, err := invoiceFromEntity(myEntity)
+		if err != nil {
+			return err
+		}
+		invoices = append(invoices, invoice)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("BillingRepository.GetInvoices: %w", err)
+	}
+
+	sort.SliceStable(invoices, func(i, j int) bool {
+		if invoices[i].Month != invoices[j].Month {
+			return invoices[i].Month > invoices[j].Month
+		}
+		return invoices[i].IssuedAt.After(invoices[j].IssuedAt)
+	})
+	return invoices, nil
+}
+
+// Helper - upsert an entity with replace semantics, creating the table if it doesn't exist
+func (repo *BillingRepository) upsert(tableName string, entity aztables.EDMEntity, method string) error {
+	serializedEntity, err := json.Marshal(entity)
+	if err != nil {
+		return fmt.Errorf("BillingRepository.%s: Failed to serialize entity: %w", method, err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{UpdateMode: aztables.UpdateModeReplace})
+	if err != nil {
+		return fmt.Errorf("BillingRepository.%s: Failed to upsert entity in %s: %w", method, tableName, err)
+	}
+	return nil
+}
+
+// Helper - page through entities matching a filter, treating a missing table as empty
+func (repo *BillingRepository) list(tableName string, filter string, handle func(aztables.EDMEntity) error) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	options := &aztables.ListEntitiesOptions{
+		Filter: &filter,
+	}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("Failed to acquire next page: %w", err)
+		}
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return fmt.Errorf("Failed to unmarshal entity: %w", err)
+			}
+			if err := handle(myEntity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Helper - map a table entity onto a TuitionPlan
+func tuitionPlanFromEntity(myEntity aztables.EDMEntity) models.TuitionPlan {
+	plan := models.TuitionPlan{ChildID: myEntity.RowKey}
+	plan.FamilyID, _ = myEntity.Properties["FamilyID"].(string)
+	frequency, _ := myEntity.Properties["Frequency"].(string)
+	plan.Frequency = models.BillingFrequency(frequency)
+	plan.RateCents = entityInt64(myEntity.Properties["RateCents"])
+	if discount, ok := myEntity.Properties["SiblingDiscountPercent"].(int32); ok {
+		plan.SiblingDiscountPercent = int(discount)
+	}
+	plan.StartDate, _ = myEntity.Properties["StartDate"].(string)
+	plan.EndDate, _ = myEntity.Properties["EndDate"].(string)
+	return plan
+}
+
+// Helper - build the table entity for an Invoice
+func invoiceToEntity(invoice models.Invoice) (aztables.EDMEntity, error) {
+	if invoice.LineItems == nil {
+		invoice.LineItems = []models.InvoiceLineItem{}
+	}
+	lineItems, err := json.Marshal(invoice.LineItems)
+	if err != nil {
+		return aztables.EDMEntity{}, fmt.Errorf("Failed to serialize line items: %w", err)
+	}
+	return aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: InvoicePartitionKey,
+			RowKey:       invoice.ID,
+		},
+		Properties: map[string]any{
+			"FamilyID":   invoice.FamilyID,
+			"Month":      invoice.Month,
+			"LineItems":  string(lineItems),
+			"TotalCents": aztables.EDMInt64(invoice.TotalCents),
+			"Status":     string(invoice.Status),
+			"IssuedAt":   formatOptionalTime(invoice.IssuedAt),
+			"DueDate":    invoice.DueDate,
+			"PaidAt":     formatOptionalTime(invoice.PaidAt),
+			"PaymentRef": invoice.PaymentRef,
+			"VoidedAt":   formatOptionalTime(invoice.VoidedAt),
+		},
+	}, nil
+}
+
+// Helper - map a table entity onto an Invoice
+func invoiceFromEntity(myEntity aztables.EDMEntity) (models.Invoice, error) {
+	invoice := models.Invoice{ID: myEntity.RowKey, LineItems: []models.InvoiceLineItem{}}
+	invoice.FamilyID, _ = myEntity.Properties["FamilyID"].(string)
+	invoice.Month, _ = myEntity.Properties["Month"].(string)
+	if lineItems, ok := myEntity.Properties["LineItems"].(string); ok && lineItems != "" {
+		if err := json.Unmarshal([]byte(lineItems), &invoice.LineItems); err != nil {
+			return models.Invoice{}, fmt.Errorf("Failed to parse line items for invoice %s: %w", invoice.ID, err)
+		}
+	}
+	invoice.TotalCents = entityInt64(myEntity.Properties["TotalCents"])
+	status, _ := myEntity.Properties["Status"].(string)
+	invoice.Status = models.InvoiceStatus(status)
+	invoice.IssuedAt = parseOptionalTime(myEntity.Properties, "IssuedAt")
+	invoice.DueDate, _ = myEntity.Properties["DueDate"].(string)
+	invoice.PaidAt = parseOptionalTime(myEntity.Properties, "PaidAt")
+	invoice.PaymentRef, _ = myEntity.Properties["PaymentRef"].(string)
+	invoice.VoidedAt = parseOptionalTime(myEntity.Properties, "VoidedAt")
+	invoice.ETag = myEntity.ETag
+	return invoice, nil
+}
+
+// Helper - read an Edm.Int64 property, which the SDK may hand back as EDMInt64 or int64
+func entityInt64(value any) int64 {
+	switch v := value.(type) {
+	case aztables.EDMInt64:
+		return int64(v)
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	}
+	return 0
+}
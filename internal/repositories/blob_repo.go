@@ -3,6 +3,7 @@ package repositories
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -35,7 +36,10 @@ func (m *MITokenProvider) Token() (string, error) {
 }
 
 type BlobStorageService struct {
-	containerURL azblob.ContainerURL
+	containerURL  azblob.ContainerURL
+	containerName string
+	credential    *azblob.SharedKeyCredential
+	emulator      bool // The local emulator's host is only reachable from inside Docker, not from browsers
 }
 
 func NewBlobStorageService(accountName, accountKey, containerName string) (*BlobStorageService, error) {
@@ -108,11 +112,14 @@ func NewBlobStorageService(accountName, accountKey, containerName string) (*Blob
 	}
 
 	return &BlobStorageService{
-		containerURL: containerURL,
+		containerURL:  containerURL,
+		containerName: containerName,
+		credential:    credential,
+		emulator:      os.Getenv("APP_ENV") == "development",
 	}, nil
 }
 
-func (s *BlobStorageService) UploadImage(ctx context.Context, fileName string, contentType string, data []byte, userID string) (*models.Image, error) {
+func (s *BlobStorageService) UploadImage(ctx context.Context, fileName string, contentType string, data io.Reader, userID string, classroomID string, hash string) (*models.Image, error) {
 
 	cfg, err := config.LoadAzTableConfig()
 	if err != nil {
@@ -130,7 +137,7 @@ func (s *BlobStorageService) UploadImage(ctx context.Context, fileName string, c
 			ID:     userID,
 			Name:   "",
 			Email:  "",
-			Role:   "parent",
+			Role:   models.RoleParent,
 			Images: []string{},
 		}
 	}
@@ -148,19 +155,22 @@ func (s *BlobStorageService) UploadImage(ctx context.Context, fileName string, c
 	// Get a reference to a blob
 	blobURL := s.containerURL.NewBlockBlobURL(blobName)
 
-	// Upload the blob
-	uploadOptions := azblob.UploadToBlockBlobOptions{
-		BlockSize:   4 * 1024 * 1024,
-		Parallelism: 16,
+	// Stream the blob in blocks so memory per upload stays at BufferSize x MaxBuffers whatever the file size
+	uploadOptions := azblob.UploadStreamToBlockBlobOptions{
+		BufferSize: 1024 * 1024,
+		MaxBuffers: 2,
 		BlobHTTPHeaders: azblob.BlobHTTPHeaders{
 			ContentType: contentType,
 		},
 		Metadata: azblob.Metadata{
-			"id": userID,
+			"id":        userID,
+			"classroom": classroomID,
+			"sha256":    hash,
 		},
 	}
 
-	_, err = azblob.UploadBufferToBlockBlob(ctx, data, blobURL, uploadOptions)
+	counter := &countingReader{reader: data}
+	_, err = azblob.UploadStreamToBlockBlob(ctx, counter, blobURL, uploadOptions)
 	if err != nil {
 		return nil, fmt.Errorf("BlobRepo.UploadImage: Failed to upload blob %w", err)
 	}
@@ -176,53 +186,130 @@ func (s *BlobStorageService) UploadImage(ctx context.Context, fileName string, c
 		Name:        fileName,
 		URL:         blobURLString.String(),
 		ContentType: contentType,
-		Size:        int64(len(data)),
+		Size:        counter.count,
 		UploadedAt:  now,
+		Classroom:   classroomID,
+		Hash:        hash,
 	}
 
 	return image, nil
 }
 
-func (s *BlobStorageService) GetImage(ctx context.Context, userID, fileName string) ([]byte, string, error) {
-	// Construct the blob name from the image ID and file name
+// OpenBlob opens a blob for streaming, only its properties are fetched until the content is read
+func (s *BlobStorageService) OpenBlob(ctx context.Context, blobName string) (*models.ImageStream, error) {
+	blobURL := s.containerURL.NewBlockBlobURL(blobName)
+	props, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{}, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("BlobRepo.OpenBlob: Failed to get properties for %s: %w", blobName, err)
+	}
+	return &models.ImageStream{
+		Content:      &blobReader{ctx: ctx, blobURL: blobURL, etag: props.ETag(), size: props.ContentLength()},
+		ContentType:  props.ContentType(),
+		Size:         props.ContentLength(),
+		ETag:         string(props.ETag()),
+		LastModified: props.LastModified(),
+	}, nil
+}
+
+// SignBlobURL returns a read-only SAS URL for a blob that stops working at expiry
+func (s *BlobStorageService) SignBlobURL(blobName string, expiry time.Time) (string, error) {
+	if s.emulator {
+		return "", services.ErrSignedURLUnavailable
+	}
+	sas, err := azblob.BlobSASSignatureValues{
+		Protocol: azblob.SASProtocolHTTPS,
+		// Backdated slightly so a viewer whose clock runs behind Azure's can still use it
+		StartTime:     time.Now().UTC().Add(-5 * time.Minute),
+		ExpiryTime:    expiry.UTC(),
+		ContainerName: s.containerName,
+		BlobName:      blobName,
+		Permissions:   azblob.BlobSASPermissions{Read: true}.String(),
+	}.NewSASQueryParameters(s.credential)
+	if err != nil {
+		return "", fmt.Errorf("BlobRepo.SignBlobURL: Failed to sign %s: %w", blobName, err)
+	}
+	parts := azblob.NewBlobURLParts(s.containerURL.NewBlockBlobURL(blobName).URL())
+	parts.SAS = sas
+	signedURL := parts.URL()
+	return signedURL.String(), nil
+}
+
+// GetImageMetadata returns the metadata stored on an image blob, such as its uploader and classroom
+func (s *BlobStorageService) GetImageMetadata(ctx context.Context, userID, fileName string) (map[string]string, error) {
 	blobName := fmt.Sprintf("%s/%s", userID, fileName)
+	blobURL := s.containerURL.NewBlockBlobURL(blobName)
 
-	// Get a reference to the blob
+	props, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{}, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("BlobRepo.GetImageMetadata: Failed to get properties for %s: %w", blobName, err)
+	}
+	return props.NewMetadata(), nil
+}
+
+// UploadBlob stores a file under an explicit blob name without tying it to a user's image list
+func (s *BlobStorageService) UploadBlob(ctx context.Context, blobName string, contentType string, data []byte, metadata map[string]string) (string, error) {
 	blobURL := s.containerURL.NewBlockBlobURL(blobName)
+	uploadOptions := azblob.UploadToBlockBlobOptions{
+		BlockSize:   4 * 1024 * 1024,
+		Parallelism: 16,
+		BlobHTTPHeaders: azblob.BlobHTTPHeaders{
+			ContentType: contentType,
+		},
+		Metadata: metadata,
+	}
+	if _, err := azblob.UploadBufferToBlockBlob(ctx, data, blobURL, uploadOptions); err != nil {
+		return "", fmt.Errorf("BlobRepo.UploadBlob: Failed to upload blob %s: %w", blobName, err)
+	}
+	blobURLString := blobURL.URL()
+	return blobURLString.String(), nil
+}
 
-	// Download the blob
+// GetBlob downloads a file stored with UploadBlob
+func (s *BlobStorageService) GetBlob(ctx context.Context, blobName string) ([]byte, string, error) {
+	blobURL := s.containerURL.NewBlockBlobURL(blobName)
 	downloadResponse, err := blobURL.Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false, azblob.ClientProvidedKeyOptions{})
 	if err != nil {
-		return nil, "", err
+		return nil, "", fmt.Errorf("BlobRepo.GetBlob: Failed to download blob %s: %w", blobName, err)
 	}
-
-	// Read the blob content
 	bodyStream := downloadResponse.Body(azblob.RetryReaderOptions{})
 	defer bodyStream.Close()
 
-	// Read the entire blob into a buffer
 	buffer := new(bytes.Buffer)
-	_, err = io.Copy(buffer, bodyStream)
-	if err != nil {
-		return nil, "", err
+	if _, err := io.Copy(buffer, bodyStream); err != nil {
+		return nil, "", fmt.Errorf("BlobRepo.GetBlob: Failed to read blob %s: %w", blobName, err)
 	}
+	return buffer.Bytes(), downloadResponse.ContentType(), nil
+}
 
-	// Get content type
-	contentType := downloadResponse.ContentType()
-
-	return buffer.Bytes(), contentType, nil
+// DeleteBlob removes a file stored with UploadBlob
+func (s *BlobStorageService) DeleteBlob(ctx context.Context, blobName string) error {
+	blobURL := s.containerURL.NewBlockBlobURL(blobName)
+	if _, err := blobURL.Delete(ctx, azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{}); err != nil {
+		return fmt.Errorf("BlobRepo.DeleteBlob: Failed to delete blob %s: %w", blobName, err)
+	}
+	return nil
 }
 
-func (s *BlobStorageService) GetAllImages(ctx context.Context) ([]string, error) {
+// GetAllImages lists the names of uploaded image blobs starting with prefix, such as "{userID}/", or every user's when prefix is empty
+func (s *BlobStorageService) GetAllImages(ctx context.Context, prefix string) ([]string, error) {
 	var imgNames []string
 
 	for marker := (azblob.Marker{}); marker.NotDone(); {
-		listBlob, err := s.containerURL.ListBlobsFlatSegment(context.Background(), marker, azblob.ListBlobsSegmentOptions{})
+		listBlob, err := s.containerURL.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{Prefix: prefix})
 		if err != nil {
 			return nil, fmt.Errorf("BlobRepo.GetAllImages: Failed to list blobs: %w", err)
 		}
 
 		for _, blob := range listBlob.Segment.BlobItems {
+			// Pickup photos, health documents and album photos are only served through their own endpoints
+			if strings.HasPrefix(blob.Name, services.PICKUPPHOTOPREFIX) || strings.HasPrefix(blob.Name, services.HEALTHDOCUMENTPREFIX) ||
+				strings.HasPrefix(blob.Name, services.ALBUMPHOTOPREFIX) {
+				continue
+			}
+			// Resized variants are served through their original's URL
+			if strings.Contains(blob.Name, "/"+services.IMAGEVARIANTDIR+"/") {
+				continue
+			}
 			imgNames = append(imgNames, blob.Name)
 		}
 		marker = listBlob.NextMarker
@@ -230,6 +317,88 @@ func (s *BlobStorageService) GetAllImages(ctx context.Context) ([]string, error)
 	return imgNames, nil
 }
 
+// ListImages returns the images a user has uploaded with their stored hashes and variants, every user's images
+// when userID is empty
+func (s *BlobStorageService) ListImages(ctx context.Context, userID string) ([]models.Image, error) {
+	prefix := ""
+	if userID != "" {
+		prefix = userID + "/"
+	}
+	images := []models.Image{}
+	variants := map[string][]models.ImageSize{}
+
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		options := azblob.ListBlobsSegmentOptions{Prefix: prefix, Details: azblob.BlobListingDetails{Metadata: true}}
+		listBlob, err := s.containerURL.ListBlobsFlatSegment(ctx, marker, options)
+		if err != nil {
+			return nil, fmt.Errorf("BlobRepo.ListImages: Failed to list blobs: %w", err)
+		}
+		marker = listBlob.NextMarker
+
+		for _, blob := range listBlob.Segment.BlobItems {
+			if strings.HasPrefix(blob.Name, services.PICKUPPHOTOPREFIX) || strings.HasPrefix(blob.Name, services.HEALTHDOCUMENTPREFIX) ||
+				strings.HasPrefix(blob.Name, services.ALBUMPHOTOPREFIX) {
+				continue
+			}
+			ownerID, fileName, ok := strings.Cut(blob.Name, "/")
+			if !ok {
+				continue
+			}
+			// Variants are named {userID}/variants/{size}/{fileName}
+			if variantPath, isVariant := strings.CutPrefix(fileName, services.IMAGEVARIANTDIR+"/"); isVariant {
+				if size, variantName, ok := strings.Cut(variantPath, "/"); ok {
+					key := ownerID + "/" + variantName
+					variants[key] = append(variants[key], models.ImageSize(size))
+				}
+				continue
+			}
+
+			image := models.Image{
+				ID:        ownerID,
+				Name:      fileName,
+				Classroom: blob.Metadata["classroom"],
+				Hash:      blob.Metadata["sha256"],
+			}
+			if blob.Properties.ContentType != nil {
+				image.ContentType = *blob.Properties.ContentType
+			}
+			if blob.Properties.ContentLength != nil {
+				image.Size = *blob.Properties.ContentLength
+			}
+			uploadedAt := blob.Properties.LastModified
+			if blob.Properties.CreationTime != nil {
+				uploadedAt = *blob.Properties.CreationTime
+			}
+			image.UploadedAt = uploadedAt.UTC().Format(time.RFC3339)
+			images = append(images, image)
+		}
+	}
+
+	for i := range images {
+		images[i].Variants = variants[images[i].ID+"/"+images[i].Name]
+	}
+	return images, nil
+}
+
+// SetImageHash records the SHA-256 of an image uploaded before hashes were stored, keeping its other metadata
+func (s *BlobStorageService) SetImageHash(ctx context.Context, userID, fileName, hash string) error {
+	blobName := fmt.Sprintf("%s/%s", userID, fileName)
+	blobURL := s.containerURL.NewBlockBlobURL(blobName)
+
+	props, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{}, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		return fmt.Errorf("BlobRepo.SetImageHash: Failed to get properties for %s: %w", blobName, err)
+	}
+	metadata := props.NewMetadata()
+	metadata["sha256"] = hash
+	// Pinned to the ETag read above so a concurrent change is not overwritten
+	conditions := azblob.BlobAccessConditions{ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfMatch: props.ETag()}}
+	if _, err := blobURL.SetMetadata(ctx, metadata, conditions, azblob.ClientProvidedKeyOptions{}); err != nil {
+		return fmt.Errorf("BlobRepo.SetImageHash: Failed to set metadata for %s: %w", blobName, err)
+	}
+	return nil
+}
+
 func removeImage(images []string, fileName string) []string {
 	var removed []string
 	removed = append(removed, "")
@@ -270,7 +439,16 @@ func (s *BlobStorageService) DeleteImage(ctx context.Context, userID, fileName s
 	blobURL := s.containerURL.NewBlockBlobURL(blobName)
 	// Delete the blob
 	_, err = blobURL.Delete(ctx, azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{})
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Variants may not exist for images uploaded before they were generated
+	for _, variant := range models.ImageVariants {
+		variantURL := s.containerURL.NewBlockBlobURL(services.ImageVariantBlobName(userID, fileName, variant.Size))
+		_, _ = variantURL.Delete(ctx, azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{})
+	}
+	return nil
 }
 
 func (s *BlobStorageService) DeleteAllImages(userID string) error {
@@ -292,3 +470,75 @@ func (s *BlobStorageService) DeleteAllImages(userID string) error {
 	}
 	return nil
 }
+
+// countingReader records how many bytes of an upload have been streamed
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count += int64(n)
+	return n, err
+}
+
+// blobReader reads a blob from its current offset on demand, seeking drops the open download so the next read
+// starts a new ranged one. Every download is pinned to the ETag seen when the blob was opened
+type blobReader struct {
+	ctx     context.Context
+	blobURL azblob.BlockBlobURL
+	etag    azblob.ETag
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+func (b *blobReader) Read(p []byte) (int, error) {
+	if b.offset >= b.size {
+		return 0, io.EOF
+	}
+	if b.body == nil {
+		conditions := azblob.BlobAccessConditions{ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfMatch: b.etag}}
+		resp, err := b.blobURL.Download(b.ctx, b.offset, azblob.CountToEnd, conditions, false, azblob.ClientProvidedKeyOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("BlobRepo.blobReader: Failed to download from offset %d: %w", b.offset, err)
+		}
+		b.body = resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: 3})
+	}
+	n, err := b.body.Read(p)
+	b.offset += int64(n)
+	return n, err
+}
+
+func (b *blobReader) Seek(offset int64, whence int) (int64, error) {
+	target := offset
+	switch whence {
+	case io.SeekCurrent:
+		target += b.offset
+	case io.SeekEnd:
+		target += b.size
+	case io.SeekStart:
+	default:
+		return 0, errors.New("BlobRepo.blobReader: Invalid whence")
+	}
+	if target < 0 {
+		return 0, errors.New("BlobRepo.blobReader: Negative position")
+	}
+	if target != b.offset {
+		if err := b.Close(); err != nil {
+			return 0, err
+		}
+		b.offset = target
+	}
+	return target, nil
+}
+
+func (b *blobReader) Close() error {
+	if b.body == nil {
+		return nil
+	}
+	err := b.body.Close()
+	b.body = nil
+	return err
+}
@@ -0,0 +1,300 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// Partition key shared by all child rows, guardian links are partitioned by child ID with the guardian's user ID as RowKey
+const ChildPartitionKey = "Children"
+
+// ChildRepository handles access to the children and guardian link tables
+type ChildRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewChildRepo creates and returns a new ChildRepository object
+func NewChildRepo(cfg config.AzTableConfig) (services.ChildRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ChildRepository.NewChildRepo: %w", err)
+	}
+	return &ChildRepository{serviceClient: *client}, nil
+}
+
+// GetChild retrieves a single child record
+func (repo *ChildRepository) GetChild(tableName string, id string) (models.Child, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	resp, err := tableClient.GetEntity(context.Background(), ChildPartitionKey, id, nil)
+	if err != nil {
+		return models.Child{}, fmt.Errorf("ChildRepository.GetChild: Failed to retrieve entity from %s: %w", tableName, err)
+	}
+
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(resp.Value, &myEntity); err != nil {
+		return models.Child{}, fmt.Errorf("ChildRepository.GetChild: Failed to deserialize entity: %w", err)
+	}
+	return childFromEntity(myEntity)
+}
+
+// GetAllChildren returns every child record
+func (repo *ChildRepository) GetAllChildren(tableName string) ([]models.Child, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	filter := fmt.Sprintf("PartitionKey eq '%s'", ChildPartitionKey)
+	options := &aztables.ListEntitiesOptions{
+		Filter: &filter,
+	}
+	children := []models.Child{}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return children, nil
+			}
+			return nil, fmt.Errorf("ChildRepository.GetAllChildren: Failed to acquire next page: %w", err)
+		}
+
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("ChildRepository.GetAllChildren: Failed to unmarshal entity: %w", err)
+			}
+			child, err := childFromEntity(myEntity)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		}
+	}
+	return children, nil
+}
+
+// GetChildrenByClassroom returns the children enrolled in a classroom
+func (repo *ChildRepository) GetChildrenByClassroom(tableName string, classroomID string) ([]models.Child, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	filter := fmt.Sprintf("PartitionKey eq '%s' and Classroom eq '%s'", ChildPartitionKey, classroomID)
+	options := &aztables.ListEntitiesOptions{
+		Filter: &filter,
+	}
+	children := []models.Child{}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return children, nil
+			}
+			return nil, fmt.Errorf("ChildRepository.GetChildrenByClassroom: Failed to acquire next page: %w", err)
+		}
+
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("ChildRepository.GetChildrenByClassroom: Failed to unmarshal entity: %w", err)
+			}
+			child, err := childFromEntity(myEntity)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		}
+	}
+	return children, nil
+}
+
+// CreateChild adds a child record, creating the table if it doesn't exist
+func (repo *ChildRepository) CreateChild(tableName string, child models.Child) error {
+	serializedEntity, err := childToEntity(child)
+	if err != nil {
+		return fmt.Errorf("ChildRepository.CreateChild: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return fmt.Errorf("ChildRepository.CreateChild: Failed to add entity to table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// UpdateChild applies partial updates to a child record
+func (repo *ChildRepository) UpdateChild(tableName string, newChildData models.Child) (models.Child, error) {
+	child, err := repo.GetChild(tableName, newChildData.ID)
+	if err != nil {
+		return models.Child{}, fmt.Errorf("ChildRepository.UpdateChild: Failed to retrieve child ID %s from %s: %w", newChildData.ID, tableName, err)
+	}
+	if err := child.Update(newChildData); err != nil {
+		return models.Child{}, fmt.Errorf("ChildRepository.UpdateChild: Failed to update child ID %s's fields: %w", child.ID, err)
+	}
+
+	serializedEntity, err := childToEntity(child)
+	if err != nil {
+		return models.Child{}, fmt.Errorf("ChildRepository.UpdateChild: %w", err)
+	}
+	tableClient := repo.serviceClient.NewClient(tableName)
+	_, err = tableClient.UpdateEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return models.Child{}, fmt.Errorf("ChildRepository.UpdateChild: Failed to update entity in %s: %w", tableName, err)
+	}
+	return child, nil
+}
+
+// DeleteChild removes a child record
+func (repo *ChildRepository) DeleteChild(tableName string, id string) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	options := &aztables.DeleteEntityOptions{
+		IfMatch: to.Ptr(azcore.ETagAny),
+	}
+	_, err := tableClient.DeleteEntity(context.Background(), ChildPartitionKey, id, options)
+	if err != nil {
+		return fmt.Errorf("ChildRepository.DeleteChild: Failed to delete entity with ID %s from %s: %w", id, tableName, err)
+	}
+	return nil
+}
+
+// UpsertGuardian creates or updates the link between a guardian and a child
+func (repo *ChildRepository) UpsertGuardian(tableName string, link models.GuardianLink) error {
+	linkEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: link.ChildID,
+			RowKey:       link.UserID,
+		},
+		Properties: map[string]any{
+			"Relationship": string(link.Relationship),
+		},
+	}
+	serializedEntity, err := json.Marshal(linkEntity)
+	if err != nil {
+		return fmt.Errorf("ChildRepository.UpsertGuardian: Failed to serialize link: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return fmt.Errorf("ChildRepository.UpsertGuardian: Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// RemoveGuardian deletes the link between a guardian and a child
+func (repo *ChildRepository) RemoveGuardian(tableName string, childID string, userID string) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	options := &aztables.DeleteEntityOptions{
+		IfMatch: to.Ptr(azcore.ETagAny),
+	}
+	_, err := tableClient.DeleteEntity(context.Background(), childID, userID, options)
+	if err != nil {
+		return fmt.Errorf("ChildRepository.RemoveGuardian: Failed to delete link %s/%s from %s: %w", childID, userID, tableName, err)
+	}
+	return nil
+}
+
+// GetGuardianLinks returns guardian links matching an OData filter, or every link when filter is empty
+func (repo *ChildRepository) GetGuardianLinks(tableName string, filter string) ([]models.GuardianLink, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	var options *aztables.ListEntitiesOptions
+	if filter != "" {
+		options = &aztables.ListEntitiesOptions{Filter: &filter}
+	}
+	links := []models.GuardianLink{}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return links, nil
+			}
+			return nil, fmt.Errorf("ChildRepository.GetGuardianLinks: Failed to acquire next page: %w", err)
+		}
+
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("ChildRepository.GetGuardianLinks: Failed to unmarshal entity: %w", err)
+			}
+			relationship, _ := myEntity.Properties["Relationship"].(string)
+			links = append(links, models.GuardianLink{
+				ChildID:      myEntity.PartitionKey,
+				UserID:       myEntity.RowKey,
+				Relationship: models.Relationship(relationship),
+			})
+		}
+	}
+	return links, nil
+}
+
+// Helper - serialize a Child into a table entity
+func childToEntity(child models.Child) ([]byte, error) {
+	if child.Allergies == nil {
+		child.Allergies = []models.Allergy{}
+	}
+	allergiesStr, err := json.Marshal(child.Allergies)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize allergies: %w", err)
+	}
+
+	childEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: ChildPartitionKey,
+			RowKey:       child.ID,
+		},
+		Properties: map[string]any{
+			"Name":         child.Name,
+			"Birthdate":    child.Birthdate,
+			"Classroom":    child.Classroom,
+			"Allergies":    string(allergiesStr),
+			"Notes":        child.Notes,
+			"MediaConsent": string(child.MediaConsent),
+
+			"MediaConsentBy": child.MediaConsentBy,
+			"MediaConsentAt": formatOptionalTime(child.MediaConsentAt),
+		},
+	}
+	serializedEntity, err := json.Marshal(childEntity)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to serialize child entity: %w", err)
+	}
+	return serializedEntity, nil
+}
+
+// Helper - map a table entity onto a Child
+func childFromEntity(myEntity aztables.EDMEntity) (models.Child, error) {
+	child := models.Child{ID: myEntity.RowKey}
+	child.Name, _ = myEntity.Properties["Name"].(string)
+	child.Birthdate, _ = myEntity.Properties["Birthdate"].(string)
+	child.Classroom, _ = myEntity.Properties["Classroom"].(string)
+	child.Notes, _ = myEntity.Properties["Notes"].(string)
+	// Children recorded before consent was tracked are shared with nobody until a guardian chooses
+	child.MediaConsent = models.MediaConsentNone
+	if consent, ok := myEntity.Properties["MediaConsent"].(string); ok && consent != "" {
+		child.MediaConsent = models.MediaConsent(consent)
+	}
+	child.MediaConsentBy, _ = myEntity.Properties["MediaConsentBy"].(string)
+	child.MediaConsentAt = parseOptionalTime(myEntity.Properties, "MediaConsentAt")
+
+	if allergiesStr, ok := myEntity.Properties["Allergies"].(string); ok && allergiesStr != "" {
+		if err := json.Unmarshal([]byte(allergiesStr), &child.Allergies); err != nil {
+			return models.Child{}, fmt.Errorf("ChildRepository: Failed to parse allergies for child %s", child.ID)
+		}
+	}
+	return child, nil
+}
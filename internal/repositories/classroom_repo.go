@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// Partition key shared by all classroom rows
+const ClassroomPartitionKey = "Classrooms"
+
+// ClassroomRepository handles access to the classrooms table
+type ClassroomRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewClassroomRepo creates and returns a new ClassroomRepository object
+func NewClassroomRepo(cfg config.AzTableConfig) (services.ClassroomRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ClassroomRepository.NewClassroomRepo: %w", err)
+	}
+	return &ClassroomRepository{serviceClient: *client}, nil
+}
+
+// GetClassroom retrieves a single classroom
+func (repo *ClassroomRepository) GetClassroom(tableName string, id string) (models.Classroom, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	resp, err := tableClient.GetEntity(context.Background(), ClassroomPartitionKey, id, nil)
+	if err != nil {
+		return models.Classroom{}, fmt.Errorf("ClassroomRepository.GetClassroom: Failed to retrieve entity from %s: %w", tableName, err)
+	}
+
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(resp.Value, &myEntity); err != nil {
+		return models.Classroom{}, fmt.Errorf("ClassroomRepository.GetClassroom: Failed to deserialize entity: %w", err)
+	}
+	return classroomFromEntity(myEntity), nil
+}
+
+// GetAllClassrooms returns every classroom
+func (repo *ClassroomRepository) GetAllClassrooms(tableName string) ([]models.Classroom, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	filter := fmt.Sprintf("PartitionKey eq '%s'", ClassroomPartitionKey)
+	options := &aztables.ListEntitiesOptions{
+		Filter: &filter,
+	}
+	classrooms := []models.Classroom{}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return classrooms, nil
+			}
+			return nil, fmt.Errorf("ClassroomRepository.GetAllClassrooms: Failed to acquire next page: %w", err)
+		}
+
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("ClassroomRepository.GetAllClassrooms: Failed to unmarshal entity: %w", err)
+			}
+			classrooms = append(classrooms, classroomFromEntity(myEntity))
+		}
+	}
+	return classrooms, nil
+}
+
+// CreateClassroom adds a classroom, creating the table if it doesn't exist
+func (repo *ClassroomRepository) CreateClassroom(tableName string, classroom models.Classroom) error {
+	serializedEntity, err := json.Marshal(classroomToEntity(classroom))
+	if err != nil {
+		return fmt.Errorf("ClassroomRepository.CreateClassroom: Failed to serialize classroom: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return fmt.Errorf("ClassroomRepository.CreateClassroom: Failed to add entity to table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// UpdateClassroom applies partial updates to a classroom
+func (repo *ClassroomRepository) UpdateClassroom(tableName string, newData models.Classroom) (models.Classroom, error) {
+	classroom, err := repo.GetClassroom(tableName, newData.ID)
+	if err != nil {
+		return models.Classroom{}, fmt.Errorf("ClassroomRepository.UpdateClassroom: Failed to retrieve classroom ID %s from %s: %w", newData.ID, tableName, err)
+	}
+	if err := classroom.Update(newData); err != nil {
+		return models.Classroom{}, fmt.Errorf("ClassroomRepository.UpdateClassroom: Failed to update classroom ID %s's fields: %w", classroom.ID, err)
+	}
+
+	serializedEntity, err := json.Marshal(classroomToEntity(classroom))
+	if err != nil {
+		return models.Classroom{}, fmt.Errorf("ClassroomRepository.UpdateClassroom: Failed to serialize classroom: %w", err)
+	}
+	tableClient := repo.serviceClient.NewClient(tableName)
+	_, err = tableClient.UpdateEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return models.Classroom{}, fmt.Errorf("ClassroomRepository.UpdateClassroom: Failed to update entity in %s: %w", tableName, err)
+	}
+	return classroom, nil
+}
+
+// DeleteClassroom removes a classroom
+func (repo *ClassroomRepository) DeleteClassroom(tableName string, id string) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	options := &aztables.DeleteEntityOptions{
+		IfMatch: to.Ptr(azcore.ETagAny),
+	}
+	_, err := tableClient.DeleteEntity(context.Background(), ClassroomPartitionKey, id, options)
+	if err != nil {
+		return fmt.Errorf("ClassroomRepository.DeleteClassroom: Failed to delete entity with ID %s from %s: %w", id, tableName, err)
+	}
+	return nil
+}
+
+// Helper - build the table entity for a Classroom
+func classroomToEntity(classroom models.Classroom) aztables.EDMEntity {
+	ratioRules := ""
+	if len(classroom.RatioRules) > 0 {
+		if data, err := json.Marshal(classroom.RatioRules); err == nil {
+			ratioRules = string(data)
+		}
+	}
+	return aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: ClassroomPartitionKey,
+			RowKey:       classroom.ID,
+		},
+		Properties: map[string]any{
+			"Name":     classroom.Name,
+			"AgeGroup": classroom.AgeGroup,
+			"Capacity": int32(classroom.Capacity),
+
+			"RatioRules": ratioRules,
+		},
+	}
+}
+
+// Helper - map a table entity onto a Classroom
+func classroomFromEntity(myEntity aztables.EDMEntity) models.Classroom {
+	classroom := models.Classroom{ID: myEntity.RowKey}
+	classroom.Name, _ = myEntity.Properties["Name"].(string)
+	classroom.AgeGroup, _ = myEntity.Properties["AgeGroup"].(string)
+	if capacity, ok := myEntity.Properties["Capacity"].(int32); ok {
+		classroom.Capacity = int(capacity)
+	}
+	if ratioRules, ok := myEntity.Properties["RatioRules"].(string); ok && ratioRules != "" {
+		_ = json.Unmarshal([]byte(ratioRules), &classroom.RatioRules)
+	}
+	return classroom
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// DailyEntryRepository stores daily report entries partitioned by date
+type DailyEntryRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewDailyEntryRepo creates and returns a new DailyEntryRepository object
+func NewDailyEntryRepo(cfg config.AzTableConfig) (services.DailyEntryRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("DailyEntryRepository.NewDailyEntryRepo: %w", err)
+	}
+	return &DailyEntryRepository{serviceClient: *client}, nil
+}
+
+// CreateEntry adds an entry, creating the table if it doesn't exist
+func (repo *DailyEntryRepository) CreateEntry(tableName string, entry models.DailyEntry) error {
+	endAt := ""
+	if !entry.EndAt.IsZero() {
+		endAt = entry.EndAt.UTC().Format(time.RFC3339)
+	}
+	entryEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: entry.Date,
+			RowKey:       entry.ID,
+		},
+		Properties: map[string]any{
+			"ChildID":    entry.ChildID,
+			"Type":       string(entry.Type),
+			"StartAt":    entry.StartAt.UTC().Format(time.RFC3339),
+			"EndAt":      endAt,
+			"Meal":       entry.Meal,
+			"Amount":     entry.Amount,
+			"Kind":       entry.Kind,
+			"Mood":       entry.Mood,
+			"Notes":      entry.Notes,
+			"ImageOwner": entry.ImageOwner,
+			"ImageName":  entry.ImageName,
+			"StaffID":    entry.StaffID,
+		},
+	}
+	serializedEntity, err := json.Marshal(entryEntity)
+	if err != nil {
+		return fmt.Errorf("DailyEntryRepository.CreateEntry: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return fmt.Errorf("DailyEntryRepository.CreateEntry: Failed to add entity to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// GetEntries returns the entries for a date in time order, optionally narrowed by an OData filter.
+// An empty date lists the entries of every date
+func (repo *DailyEntryRepository) GetEntries(tableName string, date string, filter string) ([]models.DailyEntry, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	query := filter
+	if date != "" {
+		query = fmt.Sprintf("PartitionKey eq '%s'", date)
+		if filter != "" {
+			query = fmt.Sprintf("%s and %s", query, filter)
+		}
+	}
+	options := &aztables.ListEntitiesOptions{}
+	if query != "" {
+		options.Filter = &query
+	}
+	entries := []models.DailyEntry{}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return entries, nil
+			}
+			return nil, fmt.Errorf("DailyEntryRepository.GetEntries: Failed to acquire next page: %w", err)
+		}
+
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("DailyEntryRepository.GetEntries: Failed to unmarshal entity: %w", err)
+			}
+			entry := models.DailyEntry{ID: myEntity.RowKey, Date: myEntity.PartitionKey}
+			entryType, _ := myEntity.Properties["Type"].(string)
+			entry.Type = models.DailyEntryType(entryType)
+			entry.ChildID, _ = myEntity.Properties["ChildID"].(string)
+			entry.Meal, _ = myEntity.Properties["Meal"].(string)
+			entry.Amount, _ = myEntity.Properties["Amount"].(string)
+			entry.Kind, _ = myEntity.Properties["Kind"].(string)
+			entry.Mood, _ = myEntity.Properties["Mood"].(string)
+			entry.Notes, _ = myEntity.Properties["Notes"].(string)
+			entry.ImageOwner, _ = myEntity.Properties["ImageOwner"].(string)
+			entry.ImageName, _ = myEntity.Properties["ImageName"].(string)
+			entry.StaffID, _ = myEntity.Properties["StaffID"].(string)
+			if startAt, ok := myEntity.Properties["StartAt"].(string); ok {
+				entry.StartAt, _ = time.Parse(time.RFC3339, startAt)
+			}
+			if endAt, ok := myEntity.Properties["EndAt"].(string); ok && endAt != "" {
+				entry.EndAt, _ = time.Parse(time.RFC3339, endAt)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].StartAt.Before(entries[j].StartAt)
+	})
+	return entries, nil
+}
+
+// DeleteEntry removes an entry
+func (repo *DailyEntryRepository) DeleteEntry(tableName string, date string, id string) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	options := &aztables.DeleteEntityOptions{
+		IfMatch: to.Ptr(azcore.ETagAny),
+	}
+	_, err := tableClient.DeleteEntity(context.Background(), date, id, options)
+	if err != nil {
+		return fmt.Errorf("DailyEntryRepository.DeleteEntry: Failed to delete entry %s from %s: %w", id, tableName, err)
+	}
+	return nil
+}
@@ -127,6 +127,9 @@ func (repo *EventRepository) GetEvent(tableName string, id string) (models.Event
 		color = col
 	}
 
+	classroom, _ := myEntity.Properties["Classroom"].(string)
+	invitedClassrooms, _ := myEntity.Properties["InvitedClassrooms"].(string)
+
 	event := models.Event{
 		ID:          myEntity.RowKey,
 		EventName:   myEntity.Properties["EventName"].(string),
@@ -136,8 +139,11 @@ func (repo *EventRepository) GetEvent(tableName string, id string) (models.Event
 		Location:    location,
 		Description: description,
 		Color:       color,
+		Classroom:   classroom,
 		Creator:     creator,
 		Invitees:    invitees_list,
+
+		InvitedClassrooms: splitCSV(invitedClassrooms),
 	}
 
 	return event, nil
@@ -160,9 +166,7 @@ func (repo *EventRepository) GetAllEvents(tableName string) ([]models.EventEntit
 		response, err := pager.NextPage(context.Background())
 		if err != nil {
 			// If table doesn't exist, return empty array instead of error
-			// Azure returns "TableNotFound" error code when table doesn't exist
-			errMsg := err.Error()
-			if strings.Contains(errMsg, "TableNotFound") {
+			if isTableNotFound(err) {
 				return []models.EventEntity{}, nil
 			}
 			return nil, fmt.Errorf("EventRepo.GetAllEvents: Failed to acquire next page: %w", err)
@@ -206,8 +210,11 @@ func (repo *EventRepository) CreateEvent(tableName string, event models.Event) e
 			"Location":    event.Location,
 			"Description": event.Description,
 			"Color":       event.Color,
+			"Classroom":   event.Classroom,
 			"Creator":     event.Creator.ID,
 			"Invitees":    ids_string,
+
+			"InvitedClassrooms": strings.Join(event.InvitedClassrooms, ","),
 		},
 	}
 
@@ -265,8 +272,11 @@ func (repo *EventRepository) UpdateEvent(tableName string, newEventData models.E
 			"Location":    event.Location,
 			"Description": event.Description,
 			"Color":       event.Color,
+			"Classroom":   event.Classroom,
 			"Creator":     event.Creator.ID,
 			"Invitees":    ids_string,
+
+			"InvitedClassrooms": strings.Join(event.InvitedClassrooms, ","),
 		},
 	}
 
@@ -379,3 +389,15 @@ func (repo *EventRepository) stripInvitee(csv, toRemove string) string {
 	}
 	return strings.Join(filtered, ",")
 }
+
+// Helper - split a CSV property into its trimmed, non-empty values
+func splitCSV(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// HealthRecordRepository stores immunization and physical exam records partitioned by child ID
+type HealthRecordRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewHealthRecordRepo creates and returns a new HealthRecordRepository object
+func NewHealthRecordRepo(cfg config.AzTableConfig) (services.HealthRecordRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("HealthRecordRepository.NewHealthRecordRepo: %w", err)
+	}
+	return &HealthRecordRepository{serviceClient: *client}, nil
+}
+
+// GetRecord retrieves a single health record of a child
+func (repo *HealthRecordRepository) GetRecord(tableName string, childID string, recordID string) (models.HealthRecord, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	resp, err := tableClient.GetEntity(context.Background(), childID, recordID, nil)
+	if err != nil {
+		return models.HealthRecord{}, fmt.Errorf("HealthRecordRepository.GetRecord: Failed to retrieve record %s for child %s from %s: %w", recordID, childID, tableName, err)
+	}
+
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(resp.Value, &myEntity); err != nil {
+		return models.HealthRecord{}, fmt.Errorf("HealthRecordRepository.GetRecord: Failed to deserialize entity: %w", err)
+	}
+	return healthRecordFromEntity(myEntity), nil
+}
+
+// GetRecords returns the health records matching an OData filter, most recent first
+func (repo *HealthRecordRepository) GetRecords(tableName string, filter string) ([]models.HealthRecord, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	options := &aztables.ListEntitiesOptions{}
+	if filter != "" {
+		options.Filter = &filter
+	}
+
+	records := []models.HealthRecord{}
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return records, nil
+			}
+			return nil, fmt.Errorf("HealthRecordRepository.GetRecords: Failed to acquire next page: %w", err)
+		}
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("HealthRecordRepository.GetRecords: Failed to unmarshal entity: %w", err)
+			}
+			records = append(records, healthRecordFromEntity(myEntity))
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Date > records[j].Date
+	})
+	return records, nil
+}
+
+// UpsertRecord adds or replaces a health record, creating the table if it doesn't exist
+func (repo *HealthRecordRepository) UpsertRecord(tableName string, record models.HealthRecord) error {
+	recordEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: record.ChildID,
+			RowKey:       record.ID,
+		},
+		Properties: map[string]any{
+			"Type":         string(record.Type),
+			"Vaccine":      record.Vaccine,
+			"Dose":         int32(record.Dose),
+			"Date":         record.Date,
+			"ExpiresOn":    record.ExpiresOn,
+			"DocumentBlob": record.DocumentBlob,
+			"DocumentType": record.DocumentType,
+			"UploadedBy":   record.UploadedBy,
+			"UploadedAt":   record.UploadedAt.UTC().Format(time.RFC3339),
+		},
+	}
+	serializedEntity, err := json.Marshal(recordEntity)
+	if err != nil {
+		return fmt.Errorf("HealthRecordRepository.UpsertRecord: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{UpdateMode: aztables.UpdateModeReplace})
+	if err != nil {
+		return fmt.Errorf("HealthRecordRepository.UpsertRecord: Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// DeleteRecord removes a health record
+func (repo *HealthRecordRepository) DeleteRecord(tableName string, childID string, recordID string) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	options := &aztables.DeleteEntityOptions{
+		IfMatch: to.Ptr(azcore.ETagAny),
+	}
+	_, err := tableClient.DeleteEntity(context.Background(), childID, recordID, options)
+	if err != nil {
+		return fmt.Errorf("HealthRecordRepository.DeleteRecord: Failed to delete record %s for child %s from %s: %w", recordID, childID, tableName, err)
+	}
+	return nil
+}
+
+// Helper - convert a table entity back into a health record
+func healthRecordFromEntity(myEntity aztables.EDMEntity) models.HealthRecord {
+	record := models.HealthRecord{ID: myEntity.RowKey, ChildID: myEntity.PartitionKey}
+	recordType, _ := myEntity.Properties["Type"].(string)
+	record.Type = models.HealthRecordType(recordType)
+	record.Vaccine, _ = myEntity.Properties["Vaccine"].(string)
+	if dose, ok := myEntity.Properties["Dose"].(int32); ok {
+		record.Dose = int(dose)
+	}
+	record.Date, _ = myEntity.Properties["Date"].(string)
+	record.ExpiresOn, _ = myEntity.Properties["ExpiresOn"].(string)
+	record.DocumentBlob, _ = myEntity.Properties["DocumentBlob"].(string)
+	record.DocumentType, _ = myEntity.Properties["DocumentType"].(string)
+	record.UploadedBy, _ = myEntity.Properties["UploadedBy"].(string)
+	if uploadedAt, ok := myEntity.Properties["UploadedAt"].(string); ok {
+		record.UploadedAt, _ = time.Parse(time.RFC3339, uploadedAt)
+	}
+	return record
+}
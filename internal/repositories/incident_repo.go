@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// Partition key shared by all incident report rows
+const IncidentPartitionKey = "Incidents"
+
+// IncidentRepository handles access to the incident reports table
+type IncidentRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewIncidentRepo creates and returns a new IncidentRepository object
+func NewIncidentRepo(cfg config.AzTableConfig) (services.IncidentRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("IncidentRepository.NewIncidentRepo: %w", err)
+	}
+	return &IncidentRepository{serviceClient: *client}, nil
+}
+
+// GetIncident retrieves a single incident report
+func (repo *IncidentRepository) GetIncident(tableName string, id string) (models.Incident, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	resp, err := tableClient.GetEntity(context.Background(), IncidentPartitionKey, id, nil)
+	if err != nil {
+		return models.Incident{}, fmt.Errorf("IncidentRepository.GetIncident: Failed to retrieve entity from %s: %w", tableName, err)
+	}
+
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(resp.Value, &myEntity); err != nil {
+		return models.Incident{}, fmt.Errorf("IncidentRepository.GetIncident: Failed to deserialize entity: %w", err)
+	}
+	return incidentFromEntity(myEntity), nil
+}
+
+// GetIncidents returns incident reports matching an optional OData filter, most recent first
+func (repo *IncidentRepository) GetIncidents(tableName string, filter string) ([]models.Incident, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	query := fmt.Sprintf("PartitionKey eq '%s'", IncidentPartitionKey)
+	if filter != "" {
+		query = fmt.Sprintf("%s and %s", query, filter)
+	}
+	options := &aztables.ListEntitiesOptions{
+		Filter: &query,
+	}
+	incidents := []models.Incident{}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return incidents, nil
+			}
+			return nil, fmt.Errorf("IncidentRepository.GetIncidents: Failed to acquire next page: %w", err)
+		}
+
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("IncidentRepository.GetIncidents: Failed to unmarshal entity: %w", err)
+			}
+			incidents = append(incidents, incidentFromEntity(myEntity))
+		}
+	}
+	sort.Slice(incidents, func(i, j int) bool {
+		return incidents[i].OccurredAt.After(incidents[j].OccurredAt)
+	})
+	return incidents, nil
+}
+
+// UpsertIncident creates or replaces an incident report, creating the table if it doesn't exist
+func (repo *IncidentRepository) UpsertIncident(tableName string, incident models.Incident) error {
+	serializedEntity, err := json.Marshal(incidentToEntity(incident))
+	if err != nil {
+		return fmt.Errorf("IncidentRepository.UpsertIncident: Failed to serialize incident: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{UpdateMode: aztables.UpdateModeReplace})
+	if err != nil {
+		return fmt.Errorf("IncidentRepository.UpsertIncident: Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// Helper - format an optional timestamp, leaving zero times empty
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Helper - parse an optional timestamp written by formatOptionalTime
+func parseOptionalTime(properties map[string]any, key string) time.Time {
+	value, _ := properties[key].(string)
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+// Helper - build the table entity for an Incident
+func incidentToEntity(incident models.Incident) aztables.EDMEntity {
+	witnesses, _ := json.Marshal(incident.Witnesses)
+	return aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: IncidentPartitionKey,
+			RowKey:       incident.ID,
+		},
+		Properties: map[string]any{
+			"ChildID":        incident.ChildID,
+			"Classroom":      incident.Classroom,
+			"OccurredAt":     formatOptionalTime(incident.OccurredAt),
+			"Location":       incident.Location,
+			"Description":    incident.Description,
+			"FirstAid":       incident.FirstAid,
+			"Witnesses":      string(witnesses),
+			"AuthorID":       incident.AuthorID,
+			"Status":         string(incident.Status),
+			"CreatedAt":      formatOptionalTime(incident.CreatedAt),
+			"SubmittedAt":    formatOptionalTime(incident.SubmittedAt),
+			"AcknowledgedAt": formatOptionalTime(incident.AcknowledgedAt),
+			"AcknowledgedBy": incident.AcknowledgedBy,
+		},
+	}
+}
+
+// Helper - map a table entity onto an Incident
+func incidentFromEntity(myEntity aztables.EDMEntity) models.Incident {
+	incident := models.Incident{ID: myEntity.RowKey, Witnesses: []string{}}
+	incident.ChildID, _ = myEntity.Properties["ChildID"].(string)
+	incident.Classroom, _ = myEntity.Properties["Classroom"].(string)
+	incident.Location, _ = myEntity.Properties["Location"].(string)
+	incident.Description, _ = myEntity.Properties["Description"].(string)
+	incident.FirstAid, _ = myEntity.Properties["FirstAid"].(string)
+	incident.AuthorID, _ = myEntity.Properties["AuthorID"].(string)
+	incident.AcknowledgedBy, _ = myEntity.Properties["AcknowledgedBy"].(string)
+	status, _ := myEntity.Properties["Status"].(string)
+	incident.Status = models.IncidentStatus(status)
+	if witnesses, ok := myEntity.Properties["Witnesses"].(string); ok && witnesses != "" {
+		_ = json.Unmarshal([]byte(witnesses), &incident.Witnesses)
+	}
+	incident.OccurredAt = parseOptionalTime(myEntity.Properties, "OccurredAt")
+	incident.CreatedAt = parseOptionalTime(myEntity.Properties, "CreatedAt")
+	incident.SubmittedAt = parseOptionalTime(myEntity.Properties, "SubmittedAt")
+	incident.AcknowledgedAt = parseOptionalTime(myEntity.Properties, "AcknowledgedAt")
+	return incident
+}
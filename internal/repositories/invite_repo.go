@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// Partition key shared by all invite rows, RowKey is the lowercased email address
+const InvitePartitionKey = "Invites"
+
+// InviteRepository handles access to the invited users table
+type InviteRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewInviteRepo creates and returns a new InviteRepository object
+func NewInviteRepo(cfg config.AzTableConfig) (services.InviteRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("InviteRepository.NewInviteRepo: %w", err)
+	}
+	return &InviteRepository{serviceClient: *client}, nil
+}
+
+// GetInvite retrieves the invite for a single email address
+func (repo *InviteRepository) GetInvite(tableName string, email string) (models.Invite, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	resp, err := tableClient.GetEntity(context.Background(), InvitePartitionKey, strings.ToLower(email), nil)
+	if err != nil {
+		if isNotFound(err) {
+			return models.Invite{}, fmt.Errorf("InviteRepository.GetInvite: %s: %w", email, services.ErrInviteNotFound)
+		}
+		return models.Invite{}, fmt.Errorf("InviteRepository.GetInvite: Failed to retrieve entity from %s: %w", tableName, err)
+	}
+
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(resp.Value, &myEntity); err != nil {
+		return models.Invite{}, fmt.Errorf("InviteRepository.GetInvite: Failed to deserialize entity: %w", err)
+	}
+	myEntity.ETag = string(resp.ETag)
+	return inviteFromEntity(myEntity), nil
+}
+
+// GetAllInvites returns every invite in the table
+func (repo *InviteRepository) GetAllInvites(tableName string) ([]models.Invite, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	filter := fmt.Sprintf("PartitionKey eq '%s'", InvitePartitionKey)
+	options := &aztables.ListEntitiesOptions{
+		Filter: &filter,
+	}
+	var invites []models.Invite
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return []models.Invite{}, nil
+			}
+			return nil, fmt.Errorf("InviteRepository.GetAllInvites: Failed to acquire next page: %w", err)
+		}
+
+		for _, inviteEntity := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(inviteEntity, &myEntity); err != nil {
+				return nil, fmt.Errorf("InviteRepository.GetAllInvites: Failed to unmarshal entity: %w", err)
+			}
+			invites = append(invites, inviteFromEntity(myEntity))
+		}
+	}
+	return invites, nil
+}
+
+// UpsertInvite creates or replaces an invite, creating the table if it doesn't exist
+func (repo *InviteRepository) UpsertInvite(tableName string, invite models.Invite) error {
+	serializedEntity, err := json.Marshal(inviteToEntity(invite))
+	if err != nil {
+		return fmt.Errorf("InviteRepository.UpsertInvite: Failed to serialize invite: %w", err)
+	}
+
+	// Table may already exist, error is expected in that case
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{
+		UpdateMode: aztables.UpdateModeReplace,
+	})
+	if err != nil {
+		return fmt.Errorf("InviteRepository.UpsertInvite: Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// UpdateInvite replaces an invite only if it is unchanged since it was read, matching on invite.ETag, and returns its new ETag
+func (repo *InviteRepository) UpdateInvite(tableName string, invite models.Invite) (string, error) {
+	serializedEntity, err := json.Marshal(inviteToEntity(invite))
+	if err != nil {
+		return "", fmt.Errorf("InviteRepository.UpdateInvite: Failed to serialize invite: %w", err)
+	}
+
+	tableClient := repo.serviceClient.NewClient(tableName)
+	etag := azcore.ETag(invite.ETag)
+	response, err := tableClient.UpdateEntity(context.Background(), serializedEntity, &aztables.UpdateEntityOptions{
+		IfMatch:    &etag,
+		UpdateMode: aztables.UpdateModeReplace,
+	})
+	if isPreconditionFailed(err) {
+		return "", fmt.Errorf("InviteRepository.UpdateInvite: Invite for %s was changed by another request: %w", invite.Email, services.ErrInviteUsed)
+	}
+	if err != nil {
+		return "", fmt.Errorf("InviteRepository.UpdateInvite: Failed to update entity in %s: %w", tableName, err)
+	}
+	return string(response.ETag), nil
+}
+
+// Helper - build the table entity for an Invite
+func inviteToEntity(invite models.Invite) aztables.EDMEntity {
+	return aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: InvitePartitionKey,
+			RowKey:       strings.ToLower(invite.Email),
+		},
+		Properties: map[string]any{
+			"Email":     strings.ToLower(invite.Email),
+			"Role":      string(invite.Role),
+			"SignedUp":  invite.SignedUp,
+			"InvitedAt": invite.InvitedAt.UTC().Format(time.RFC3339),
+			"ChildIDs":  strings.Join(invite.ChildIDs, ","),
+		},
+	}
+}
+
+// DeleteInvite removes the invite for an email address
+func (repo *InviteRepository) DeleteInvite(tableName string, email string) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	options := &aztables.DeleteEntityOptions{
+		IfMatch: to.Ptr(azcore.ETagAny),
+	}
+	_, err := tableClient.DeleteEntity(context.Background(), InvitePartitionKey, strings.ToLower(email), options)
+	if err != nil {
+		return fmt.Errorf("InviteRepository.DeleteInvite: Failed to delete invite %s from %s: %w", email, tableName, err)
+	}
+	return nil
+}
+
+// Helper - map a table entity onto an Invite
+func inviteFromEntity(myEntity aztables.EDMEntity) models.Invite {
+	invite := models.Invite{Email: myEntity.RowKey}
+	if role, ok := myEntity.Properties["Role"].(string); ok {
+		invite.Role = models.NormalizeRole(role)
+	}
+	if signedUp, ok := myEntity.Properties["SignedUp"].(bool); ok {
+		invite.SignedUp = signedUp
+	}
+	if invitedAt, ok := myEntity.Properties["InvitedAt"].(string); ok {
+		invite.InvitedAt, _ = time.Parse(time.RFC3339, invitedAt)
+	}
+	childIDs, _ := myEntity.Properties["ChildIDs"].(string)
+	invite.ChildIDs = splitCSV(childIDs)
+	invite.ETag = myEntity.ETag
+	return invite
+}
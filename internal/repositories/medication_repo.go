@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// MedicationRepository stores medication authorizations and the dose log, both partitioned by child ID
+type MedicationRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewMedicationRepo creates and returns a new MedicationRepository object
+func NewMedicationRepo(cfg config.AzTableConfig) (services.MedicationRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("MedicationRepository.NewMedicationRepo: %w", err)
+	}
+	return &MedicationRepository{serviceClient: *client}, nil
+}
+
+// GetAuthorizations returns every medication authorization for a child, newest first
+func (repo *MedicationRepository) GetAuthorizations(tableName string, childID string) ([]models.MedicationAuthorization, error) {
+	authorizations := []models.MedicationAuthorization{}
+	err := repo.listPartition(tableName, childID, func(myEntity aztables.EDMEntity) {
+		authorization := models.MedicationAuthorization{ID: myEntity.RowKey, ChildID: myEntity.PartitionKey}
+		authorization.Drug, _ = myEntity.Properties["Drug"].(string)
+		authorization.Dose, _ = myEntity.Properties["Dose"].(string)
+		authorization.Instructions, _ = myEntity.Properties["Instructions"].(string)
+		times, _ := myEntity.Properties["Times"].(string)
+		authorization.Times = splitCSV(times)
+		if minInterval, ok := myEntity.Properties["MinIntervalMins"].(int32); ok {
+			authorization.MinIntervalMins = int(minInterval)
+		}
+		authorization.StartDate, _ = myEntity.Properties["StartDate"].(string)
+		authorization.EndDate, _ = myEntity.Properties["EndDate"].(string)
+		authorization.SignedBy, _ = myEntity.Properties["SignedBy"].(string)
+		authorization.Signature, _ = myEntity.Properties["Signature"].(string)
+		authorization.SignedAt = parseOptionalTime(myEntity.Properties, "SignedAt")
+		authorization.RevokedAt = parseOptionalTime(myEntity.Properties, "RevokedAt")
+		authorizations = append(authorizations, authorization)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("MedicationRepository.GetAuthorizations: %w", err)
+	}
+
+	sort.SliceStable(authorizations, func(i, j int) bool {
+		return authorizations[i].SignedAt.After(authorizations[j].SignedAt)
+	})
+	return authorizations, nil
+}
+
+// UpsertAuthorization adds or replaces a medication authorization, creating the table if it doesn't exist
+func (repo *MedicationRepository) UpsertAuthorization(tableName string, authorization models.MedicationAuthorization) error {
+	authorizationEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: authorization.ChildID,
+			RowKey:       authorization.ID,
+		},
+		Properties: map[string]any{
+			"Drug":            authorization.Drug,
+			"Dose":            authorization.Dose,
+			"Instructions":    authorization.Instructions,
+			"Times":           strings.Join(authorization.Times, ","),
+			"MinIntervalMins": int32(authorization.MinIntervalMins),
+			"StartDate":       authorization.StartDate,
+			"EndDate":         authorization.EndDate,
+			"SignedBy":        authorization.SignedBy,
+			"Signature":       authorization.Signature,
+			"SignedAt":        formatOptionalTime(authorization.SignedAt),
+			"RevokedAt":       formatOptionalTime(authorization.RevokedAt),
+		},
+	}
+	serializedEntity, err := json.Marshal(authorizationEntity)
+	if err != nil {
+		return fmt.Errorf("MedicationRepository.UpsertAuthorization: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{UpdateMode: aztables.UpdateModeReplace})
+	if err != nil {
+		return fmt.Errorf("MedicationRepository.UpsertAuthorization: Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// AddDose appends an administered dose to a child's log, creating the table if it doesn't exist
+func (repo *MedicationRepository) AddDose(tableName string, dose models.MedicationDose) error {
+	if dose.Warnings == nil {
+		dose.Warnings = []string{}
+	}
+	warningsStr, err := json.Marshal(dose.Warnings)
+	if err != nil {
+		return fmt.Errorf("MedicationRepository.AddDose: Failed to serialize warnings: %w", err)
+	}
+
+	doseEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: dose.ChildID,
+			RowKey:       dose.ID,
+		},
+		Properties: map[string]any{
+			"AuthorizationID": dose.AuthorizationID,
+			"Drug":            dose.Drug,
+			"Dose":            dose.Dose,
+			"AdministeredAt":  dose.AdministeredAt.UTC().Format(time.RFC3339),
+			"StaffID":         dose.StaffID,
+			"Notes":           dose.Notes,
+			"Warnings":        string(warningsStr),
+		},
+	}
+	serializedEntity, err := json.Marshal(doseEntity)
+	if err != nil {
+		return fmt.Errorf("MedicationRepository.AddDose: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return fmt.Errorf("MedicationRepository.AddDose: Failed to add entity to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// GetDoses returns a child's dose log, most recent first
+func (repo *MedicationRepository) GetDoses(tableName string, childID string) ([]models.MedicationDose, error) {
+	doses := []models.MedicationDose{}
+	err := repo.listPartition(tableName, childID, func(myEntity aztables.EDMEntity) {
+		dose := models.MedicationDose{ID: myEntity.RowKey, ChildID: myEntity.PartitionKey, Warnings: []string{}}
+		dose.AuthorizationID, _ = myEntity.Properties["AuthorizationID"].(string)
+		dose.Drug, _ = myEntity.Properties["Drug"].(string)
+		dose.Dose, _ = myEntity.Properties["Dose"].(string)
+		dose.AdministeredAt = parseOptionalTime(myEntity.Properties, "AdministeredAt")
+		dose.StaffID, _ = myEntity.Properties["StaffID"].(string)
+		dose.Notes, _ = myEntity.Properties["Notes"].(string)
+		if warningsStr, ok := myEntity.Properties["Warnings"].(string); ok && warningsStr != "" {
+			_ = json.Unmarshal([]byte(warningsStr), &dose.Warnings)
+		}
+		doses = append(doses, dose)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("MedicationRepository.GetDoses: %w", err)
+	}
+
+	sort.SliceStable(doses, func(i, j int) bool {
+		return doses[i].AdministeredAt.After(doses[j].AdministeredAt)
+	})
+	return doses, nil
+}
+
+// Helper - page through one partition, treating a missing table as empty
+func (repo *MedicationRepository) listPartition(tableName string, partitionKey string, handle func(aztables.EDMEntity)) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	filter := fmt.Sprintf("PartitionKey eq '%s'", partitionKey)
+	options := &aztables.ListEntitiesOptions{
+		Filter: &filter,
+	}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("Failed to acquire next page: %w", err)
+		}
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return fmt.Errorf("Failed to unmarshal entity: %w", err)
+			}
+			handle(myEntity)
+		}
+	}
+	return nil
+}
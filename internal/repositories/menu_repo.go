@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// Partition key shared by all menu rows, RowKey is the Monday the menu's week starts on
+const MenuPartitionKey = "Menus"
+
+// MenuRepository stores published weekly menus
+type MenuRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewMenuRepo creates and returns a new MenuRepository object
+func NewMenuRepo(cfg config.AzTableConfig) (services.MenuRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("MenuRepository.NewMenuRepo: %w", err)
+	}
+	return &MenuRepository{serviceClient: *client}, nil
+}
+
+// GetMenu retrieves the menu for the week starting on the given Monday
+func (repo *MenuRepository) GetMenu(tableName string, week string) (models.Menu, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	resp, err := tableClient.GetEntity(context.Background(), MenuPartitionKey, week, nil)
+	if err != nil {
+		return models.Menu{}, fmt.Errorf("MenuRepository.GetMenu: Failed to retrieve menu for week %s from %s: %w", week, tableName, err)
+	}
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(resp.Value, &myEntity); err != nil {
+		return models.Menu{}, fmt.Errorf("MenuRepository.GetMenu: Failed to deserialize entity: %w", err)
+	}
+
+	menu := models.Menu{Week: myEntity.RowKey, Items: []models.MenuItem{}}
+	if items, ok := myEntity.Properties["Items"].(string); ok && items != "" {
+		if err := json.Unmarshal([]byte(items), &menu.Items); err != nil {
+			return models.Menu{}, fmt.Errorf("MenuRepository.GetMenu: Failed to deserialize menu items: %w", err)
+		}
+	}
+	menu.PublishedBy, _ = myEntity.Properties["PublishedBy"].(string)
+	if publishedAt, ok := myEntity.Properties["PublishedAt"].(string); ok {
+		menu.PublishedAt, _ = time.Parse(time.RFC3339, publishedAt)
+	}
+	return menu, nil
+}
+
+// UpsertMenu creates or replaces a week's menu, creating the table if it doesn't exist
+func (repo *MenuRepository) UpsertMenu(tableName string, menu models.Menu) error {
+	items, err := json.Marshal(menu.Items)
+	if err != nil {
+		return fmt.Errorf("MenuRepository.UpsertMenu: Failed to serialize menu items: %w", err)
+	}
+	menuEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: MenuPartitionKey,
+			RowKey:       menu.Week,
+		},
+		Properties: map[string]any{
+			"Items":       string(items),
+			"PublishedBy": menu.PublishedBy,
+			"PublishedAt": menu.PublishedAt.UTC().Format(time.RFC3339),
+		},
+	}
+	serializedEntity, err := json.Marshal(menuEntity)
+	if err != nil {
+		return fmt.Errorf("MenuRepository.UpsertMenu: Failed to serialize entity: %w", err)
+	}
+
+	// Table may already exist, error is expected in that case
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{
+		UpdateMode: aztables.UpdateModeReplace,
+	})
+	if err != nil {
+		return fmt.Errorf("MenuRepository.UpsertMenu: Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// Partition key shared by all thread rows; messages and read receipts are partitioned by thread ID
+const ThreadPartitionKey = "Threads"
+
+// How many times an unread counter update is retried when other messages change the receipt first
+const maxCounterAttempts = 5
+
+// MessageRepository stores message threads, their messages and read receipts
+type MessageRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewMessageRepo creates and returns a new MessageRepository object
+func NewMessageRepo(cfg config.AzTableConfig) (services.MessageRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("MessageRepository.NewMessageRepo: %w", err)
+	}
+	return &MessageRepository{serviceClient: *client}, nil
+}
+
+// GetThread retrieves a single thread
+func (repo *MessageRepository) GetThread(tableName string, id string) (models.Thread, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	resp, err := tableClient.GetEntity(context.Background(), ThreadPartitionKey, id, nil)
+	if err != nil {
+		return models.Thread{}, fmt.Errorf("MessageRepository.GetThread: Failed to retrieve thread %s from %s: %w", id, tableName, err)
+	}
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(resp.Value, &myEntity); err != nil {
+		return models.Thread{}, fmt.Errorf("MessageRepository.GetThread: Failed to deserialize entity: %w", err)
+	}
+	return threadFromEntity(myEntity), nil
+}
+
+// GetThreads returns threads matching an OData filter, most recently active first
+func (repo *MessageRepository) GetThreads(tableName string, filter string) ([]models.Thread, error) {
+	query := fmt.Sprintf("PartitionKey eq '%s'", ThreadPartitionKey)
+	if filter != "" {
+		query = fmt.Sprintf("%s and %s", query, filter)
+	}
+	threads := []models.Thread{}
+	err := repo.listEntities(tableName, query, func(myEntity aztables.EDMEntity) {
+		threads = append(threads, threadFromEntity(myEntity))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("MessageRepository.GetThreads: %w", err)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].LastMessageAt.After(threads[j].LastMessageAt)
+	})
+	return threads, nil
+}
+
+// UpsertThread creates or replaces a thread, creating the table if it doesn't exist
+func (repo *MessageRepository) UpsertThread(tableName string, thread models.Thread) error {
+	threadEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: ThreadPartitionKey,
+			RowKey:       thread.ID,
+		},
+		Properties: map[string]any{
+			"Kind":           string(thread.Kind),
+			"Subject":        thread.Subject,
+			"Classroom":      thread.Classroom,
+			"ParticipantIDs": strings.Join(thread.ParticipantIDs, ","),
+			"CreatedBy":      thread.CreatedBy,
+			"CreatedAt":      thread.CreatedAt.UTC().Format(time.RFC3339Nano),
+			"LastMessageAt":  thread.LastMessageAt.UTC().Format(time.RFC3339Nano),
+		},
+	}
+	if err := repo.upsertEntity(tableName, threadEntity); err != nil {
+		return fmt.Errorf("MessageRepository.UpsertThread: %w", err)
+	}
+	return nil
+}
+
+// AddMessage stores a new message in its thread's partition
+func (repo *MessageRepository) AddMessage(tableName string, message models.Message) error {
+	attachments, err := json.Marshal(message.Attachments)
+	if err != nil {
+		return fmt.Errorf("MessageRepository.AddMessage: Failed to serialize attachments: %w", err)
+	}
+	messageEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: message.ThreadID,
+			RowKey:       message.ID,
+		},
+		Properties: map[string]any{
+			"SenderID":    message.SenderID,
+			"Body":        message.Body,
+			"Attachments": string(attachments),
+			"SentAt":      message.SentAt.UTC().Format(time.RFC3339Nano),
+		},
+	}
+	serializedEntity, err := json.Marshal(messageEntity)
+	if err != nil {
+		return fmt.Errorf("MessageRepository.AddMessage: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return fmt.Errorf("MessageRepository.AddMessage: Failed to add entity to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// GetMessages returns a thread's messages oldest first
+func (repo *MessageRepository) GetMessages(tableName string, threadID string) ([]models.Message, error) {
+	messages := []models.Message{}
+	err := repo.listEntities(tableName, fmt.Sprintf("PartitionKey eq '%s'", threadID), func(myEntity aztables.EDMEntity) {
+		message := models.Message{ID: myEntity.RowKey, ThreadID: myEntity.PartitionKey, Attachments: []models.MessageAttachment{}}
+		message.SenderID, _ = myEntity.Properties["SenderID"].(string)
+		message.Body, _ = myEntity.Properties["Body"].(string)
+		if attachments, ok := myEntity.Properties["Attachments"].(string); ok && attachments != "" {
+			_ = json.Unmarshal([]byte(attachments), &message.Attachments)
+		}
+		if sentAt, ok := myEntity.Properties["SentAt"].(string); ok {
+			message.SentAt, _ = time.Parse(time.RFC3339Nano, sentAt)
+		}
+		messages = append(messages, message)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("MessageRepository.GetMessages: %w", err)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].SentAt.Before(messages[j].SentAt)
+	})
+	return messages, nil
+}
+
+// UpsertReceipt records how far a user has read in a thread
+func (repo *MessageRepository) UpsertReceipt(tableName string, receipt models.ReadReceipt) error {
+	receiptEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: receipt.ThreadID,
+			RowKey:       receipt.UserID,
+		},
+		Properties: receiptProperties(receipt),
+	}
+	if err := repo.upsertEntity(tableName, receiptEntity); err != nil {
+		return fmt.Errorf("MessageRepository.UpsertReceipt: %w", err)
+	}
+	return nil
+}
+
+// IncrementUnread adds one to a member's unread counter for a thread, creating their receipt if they have none.
+// Updates are conditional on the receipt's ETag and retried, so messages sent at the same time are all counted
+func (repo *MessageRepository) IncrementUnread(tableName string, threadID string, userID string) error {
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	for attempt := 0; attempt < maxCounterAttempts; attempt++ {
+		resp, err := tableClient.GetEntity(context.Background(), threadID, userID, nil)
+		if isNotFound(err) {
+			receiptEntity := aztables.EDMEntity{
+				Entity:     aztables.Entity{PartitionKey: threadID, RowKey: userID},
+				Properties: receiptProperties(models.ReadReceipt{ThreadID: threadID, UserID: userID, Unread: 1}),
+			}
+			serializedEntity, err := json.Marshal(receiptEntity)
+			if err != nil {
+				return fmt.Errorf("MessageRepository.IncrementUnread: Failed to serialize entity: %w", err)
+			}
+			_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+			if isConflict(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("MessageRepository.IncrementUnread: Failed to add receipt to %s: %w", tableName, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("MessageRepository.IncrementUnread: Failed to retrieve receipt from %s: %w", tableName, err)
+		}
+
+		var myEntity aztables.EDMEntity
+		if err := json.Unmarshal(resp.Value, &myEntity); err != nil {
+			return fmt.Errorf("MessageRepository.IncrementUnread: Failed to deserialize entity: %w", err)
+		}
+		receipt := receiptFromEntity(myEntity)
+		receipt.Unread++
+		myEntity.Properties = receiptProperties(receipt)
+		serializedEntity, err := json.Marshal(myEntity)
+		if err != nil {
+			return fmt.Errorf("MessageRepository.IncrementUnread: Failed to serialize entity: %w", err)
+		}
+		_, err = tableClient.UpdateEntity(context.Background(), serializedEntity, &aztables.UpdateEntityOptions{
+			IfMatch:    &resp.ETag,
+			UpdateMode: aztables.UpdateModeReplace,
+		})
+		if isPreconditionFailed(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("MessageRepository.IncrementUnread: Failed to update receipt in %s: %w", tableName, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("MessageRepository.IncrementUnread: Gave up on receipt %s/%s after %d concurrent updates", threadID, userID, maxCounterAttempts)
+}
+
+// GetReceipts returns read receipts matching an OData filter, such as one thread's partition or one user's row key
+func (repo *MessageRepository) GetReceipts(tableName string, filter string) ([]models.ReadReceipt, error) {
+	receipts := []models.ReadReceipt{}
+	err := repo.listEntities(tableName, filter, func(myEntity aztables.EDMEntity) {
+		receipts = append(receipts, receiptFromEntity(myEntity))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("MessageRepository.GetReceipts: %w", err)
+	}
+	return receipts, nil
+}
+
+// Helper - replace an entity, creating the table if it doesn't exist
+func (repo *MessageRepository) upsertEntity(tableName string, entity aztables.EDMEntity) error {
+	serializedEntity, err := json.Marshal(entity)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize entity: %w", err)
+	}
+
+	// Table may already exist, error is expected in that case
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{
+		UpdateMode: aztables.UpdateModeReplace,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// Helper - page through the entities matching a filter, treating a missing table as empty
+func (repo *MessageRepository) listEntities(tableName string, filter string, each func(aztables.EDMEntity)) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	options := &aztables.ListEntitiesOptions{}
+	if filter != "" {
+		options.Filter = &filter
+	}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("Failed to acquire next page: %w", err)
+		}
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return fmt.Errorf("Failed to unmarshal entity: %w", err)
+			}
+			each(myEntity)
+		}
+	}
+	return nil
+}
+
+// Helper - the stored properties of a read receipt
+func receiptProperties(receipt models.ReadReceipt) map[string]any {
+	lastReadAt := ""
+	if !receipt.LastReadAt.IsZero() {
+		lastReadAt = receipt.LastReadAt.UTC().Format(time.RFC3339Nano)
+	}
+	return map[string]any{
+		"LastReadAt": lastReadAt,
+		"Unread":     int32(receipt.Unread),
+	}
+}
+
+// Helper - map a table entity onto a ReadReceipt
+func receiptFromEntity(myEntity aztables.EDMEntity) models.ReadReceipt {
+	receipt := models.ReadReceipt{ThreadID: myEntity.PartitionKey, UserID: myEntity.RowKey}
+	if lastReadAt, ok := myEntity.Properties["LastReadAt"].(string); ok {
+		receipt.LastReadAt, _ = time.Parse(time.RFC3339Nano, lastReadAt)
+	}
+	if unread, ok := myEntity.Properties["Unread"].(int32); ok {
+		receipt.Unread = int(unread)
+	}
+	return receipt
+}
+
+// Helper - map a table entity onto a Thread
+func threadFromEntity(myEntity aztables.EDMEntity) models.Thread {
+	thread := models.Thread{ID: myEntity.RowKey}
+	kind, _ := myEntity.Properties["Kind"].(string)
+	thread.Kind = models.ThreadKind(kind)
+	thread.Subject, _ = myEntity.Properties["Subject"].(string)
+	thread.Classroom, _ = myEntity.Properties["Classroom"].(string)
+	participantIDs, _ := myEntity.Properties["ParticipantIDs"].(string)
+	thread.ParticipantIDs = splitCSV(participantIDs)
+	thread.CreatedBy, _ = myEntity.Properties["CreatedBy"].(string)
+	if createdAt, ok := myEntity.Properties["CreatedAt"].(string); ok {
+		thread.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	}
+	if lastMessageAt, ok := myEntity.Properties["LastMessageAt"].(string); ok {
+		thread.LastMessageAt, _ = time.Parse(time.RFC3339Nano, lastMessageAt)
+	}
+	return thread
+}
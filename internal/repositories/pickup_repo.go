@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// PickupRepository stores authorized pickup lists and the pickup audit trail, both partitioned by child ID
+type PickupRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewPickupRepo creates and returns a new PickupRepository object
+func NewPickupRepo(cfg config.AzTableConfig) (services.PickupRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("PickupRepository.NewPickupRepo: %w", err)
+	}
+	return &PickupRepository{serviceClient: *client}, nil
+}
+
+// GetPickups returns a child's authorized pickup list
+func (repo *PickupRepository) GetPickups(tableName string, childID string) ([]models.AuthorizedPickup, error) {
+	pickups := []models.AuthorizedPickup{}
+	err := repo.listPartition(tableName, childID, func(myEntity aztables.EDMEntity) {
+		pickup := models.AuthorizedPickup{ID: myEntity.RowKey, ChildID: myEntity.PartitionKey}
+		pickup.Name, _ = myEntity.Properties["Name"].(string)
+		pickup.Relationship, _ = myEntity.Properties["Relationship"].(string)
+		pickup.Phone, _ = myEntity.Properties["Phone"].(string)
+		pickup.PhotoBlob, _ = myEntity.Properties["PhotoBlob"].(string)
+		pickup.AddedBy, _ = myEntity.Properties["AddedBy"].(string)
+		if addedAt, ok := myEntity.Properties["AddedAt"].(string); ok {
+			pickup.AddedAt, _ = time.Parse(time.RFC3339, addedAt)
+		}
+		pickups = append(pickups, pickup)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PickupRepository.GetPickups: %w", err)
+	}
+	return pickups, nil
+}
+
+// UpsertPickup adds or replaces an authorized pickup, creating the table if it doesn't exist
+func (repo *PickupRepository) UpsertPickup(tableName string, pickup models.AuthorizedPickup) error {
+	pickupEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: pickup.ChildID,
+			RowKey:       pickup.ID,
+		},
+		Properties: map[string]any{
+			"Name":         pickup.Name,
+			"Relationship": pickup.Relationship,
+			"Phone":        pickup.Phone,
+			"PhotoBlob":    pickup.PhotoBlob,
+			"AddedBy":      pickup.AddedBy,
+			"AddedAt":      pickup.AddedAt.UTC().Format(time.RFC3339),
+		},
+	}
+	serializedEntity, err := json.Marshal(pickupEntity)
+	if err != nil {
+		return fmt.Errorf("PickupRepository.UpsertPickup: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{UpdateMode: aztables.UpdateModeReplace})
+	if err != nil {
+		return fmt.Errorf("PickupRepository.UpsertPickup: Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// DeletePickup removes an authorized pickup
+func (repo *PickupRepository) DeletePickup(tableName string, childID string, pickupID string) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	options := &aztables.DeleteEntityOptions{
+		IfMatch: to.Ptr(azcore.ETagAny),
+	}
+	_, err := tableClient.DeleteEntity(context.Background(), childID, pickupID, options)
+	if err != nil {
+		return fmt.Errorf("PickupRepository.DeletePickup: Failed to delete pickup %s for child %s from %s: %w", pickupID, childID, tableName, err)
+	}
+	return nil
+}
+
+// AddAudit appends a checkout attempt to the audit trail, creating the table if it doesn't exist
+func (repo *PickupRepository) AddAudit(tableName string, audit models.PickupAudit) error {
+	auditEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: audit.ChildID,
+			RowKey:       audit.At.UTC().Format(time.RFC3339Nano),
+		},
+		Properties: map[string]any{
+			"AttendanceID":   audit.AttendanceID,
+			"PickedUpBy":     audit.PickedUpBy,
+			"PickupID":       audit.PickupID,
+			"Authorized":     audit.Authorized,
+			"Allowed":        audit.Allowed,
+			"OverrideReason": audit.OverrideReason,
+			"StaffID":        audit.StaffID,
+		},
+	}
+	serializedEntity, err := json.Marshal(auditEntity)
+	if err != nil {
+		return fmt.Errorf("PickupRepository.AddAudit: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return fmt.Errorf("PickupRepository.AddAudit: Failed to add entity to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// GetAudits returns every recorded checkout attempt for a child, oldest first
+func (repo *PickupRepository) GetAudits(tableName string, childID string) ([]models.PickupAudit, error) {
+	audits := []models.PickupAudit{}
+	err := repo.listPartition(tableName, childID, func(myEntity aztables.EDMEntity) {
+		audit := models.PickupAudit{ChildID: myEntity.PartitionKey}
+		audit.At, _ = time.Parse(time.RFC3339Nano, myEntity.RowKey)
+		audit.AttendanceID, _ = myEntity.Properties["AttendanceID"].(string)
+		audit.PickedUpBy, _ = myEntity.Properties["PickedUpBy"].(string)
+		audit.PickupID, _ = myEntity.Properties["PickupID"].(string)
+		audit.Authorized, _ = myEntity.Properties["Authorized"].(bool)
+		audit.Allowed, _ = myEntity.Properties["Allowed"].(bool)
+		audit.OverrideReason, _ = myEntity.Properties["OverrideReason"].(string)
+		audit.StaffID, _ = myEntity.Properties["StaffID"].(string)
+		audits = append(audits, audit)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PickupRepository.GetAudits: %w", err)
+	}
+	return audits, nil
+}
+
+// Helper - page through one partition, treating a missing table as empty
+func (repo *PickupRepository) listPartition(tableName string, partitionKey string, handle func(aztables.EDMEntity)) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	filter := fmt.Sprintf("PartitionKey eq '%s'", partitionKey)
+	options := &aztables.ListEntitiesOptions{
+		Filter: &filter,
+	}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("Failed to acquire next page: %w", err)
+		}
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return fmt.Errorf("Failed to unmarshal entity: %w", err)
+			}
+			handle(myEntity)
+		}
+	}
+	return nil
+}
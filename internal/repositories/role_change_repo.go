@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// RoleChangeRepository stores the role change audit trail, partitioned by user ID
+type RoleChangeRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewRoleChangeRepo creates and returns a new RoleChangeRepository object
+func NewRoleChangeRepo(cfg config.AzTableConfig) (services.RoleChangeRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("RoleChangeRepository.NewRoleChangeRepo: %w", err)
+	}
+	return &RoleChangeRepository{serviceClient: *client}, nil
+}
+
+// AddRoleChange appends an entry to the audit table, creating the table if it doesn't exist
+func (repo *RoleChangeRepository) AddRoleChange(tableName string, change models.RoleChange) error {
+	changeEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: change.UserID,
+			RowKey:       change.ChangedAt.UTC().Format(time.RFC3339Nano),
+		},
+		Properties: map[string]any{
+			"OldRole":   change.OldRole,
+			"NewRole":   change.NewRole,
+			"ChangedBy": change.ChangedBy,
+		},
+	}
+
+	serializedEntity, err := json.Marshal(changeEntity)
+	if err != nil {
+		return fmt.Errorf("RoleChangeRepository.AddRoleChange: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return fmt.Errorf("RoleChangeRepository.AddRoleChange: Failed to add entity to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// GetRoleChanges returns every recorded role change for a user, oldest first
+func (repo *RoleChangeRepository) GetRoleChanges(tableName string, userID string) ([]models.RoleChange, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	filter := fmt.Sprintf("PartitionKey eq '%s'", userID)
+	options := &aztables.ListEntitiesOptions{
+		Filter: &filter,
+	}
+	changes := []models.RoleChange{}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return changes, nil
+			}
+			return nil, fmt.Errorf("RoleChangeRepository.GetRoleChanges: Failed to acquire next page: %w", err)
+		}
+
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("RoleChangeRepository.GetRoleChanges: Failed to unmarshal entity: %w", err)
+			}
+			change := models.RoleChange{UserID: myEntity.PartitionKey}
+			change.ChangedAt, _ = time.Parse(time.RFC3339Nano, myEntity.RowKey)
+			oldRole, _ := myEntity.Properties["OldRole"].(string)
+			newRole, _ := myEntity.Properties["NewRole"].(string)
+			change.OldRole = models.Role(oldRole)
+			change.NewRole = models.Role(newRole)
+			change.ChangedBy, _ = myEntity.Properties["ChangedBy"].(string)
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// ShiftRepository stores staff shifts partitioned by date, one row per shift
+type ShiftRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewShiftRepo creates and returns a new ShiftRepository object
+func NewShiftRepo(cfg config.AzTableConfig) (services.ShiftRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ShiftRepository.NewShiftRepo: %w", err)
+	}
+	return &ShiftRepository{serviceClient: *client}, nil
+}
+
+// CreateShift adds a clock-in, creating the table if it doesn't exist
+func (repo *ShiftRepository) CreateShift(tableName string, shift models.Shift) error {
+	serializedEntity, err := json.Marshal(shiftToEntity(shift))
+	if err != nil {
+		return fmt.Errorf("ShiftRepository.CreateShift: Failed to serialize shift: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if isConflict(err) {
+		return fmt.Errorf("ShiftRepository.CreateShift: Shift %s is already recorded: %w", shift.ID, services.ErrAlreadyClockedIn)
+	}
+	if err != nil {
+		return fmt.Errorf("ShiftRepository.CreateShift: Failed to add entity to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// UpdateShift replaces a stored shift, used to record a clock-out
+func (repo *ShiftRepository) UpdateShift(tableName string, shift models.Shift) error {
+	serializedEntity, err := json.Marshal(shiftToEntity(shift))
+	if err != nil {
+		return fmt.Errorf("ShiftRepository.UpdateShift: Failed to serialize shift: %w", err)
+	}
+	tableClient := repo.serviceClient.NewClient(tableName)
+	_, err = tableClient.UpdateEntity(context.Background(), serializedEntity, nil)
+	if err != nil {
+		return fmt.Errorf("ShiftRepository.UpdateShift: Failed to update entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// GetShifts returns the shifts for a date ordered by clock-in time, optionally narrowed by an OData filter
+func (repo *ShiftRepository) GetShifts(tableName string, date string, filter string) ([]models.Shift, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	query := fmt.Sprintf("PartitionKey eq '%s'", date)
+	if filter != "" {
+		query = fmt.Sprintf("%s and %s", query, filter)
+	}
+	options := &aztables.ListEntitiesOptions{
+		Filter: &query,
+	}
+	shifts := []models.Shift{}
+
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return shifts, nil
+			}
+			return nil, fmt.Errorf("ShiftRepository.GetShifts: Failed to acquire next page: %w", err)
+		}
+
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("ShiftRepository.GetShifts: Failed to unmarshal entity: %w", err)
+			}
+			shift := models.Shift{ID: myEntity.RowKey, Date: myEntity.PartitionKey}
+			shift.StaffID, _ = myEntity.Properties["StaffID"].(string)
+			shift.Classroom, _ = myEntity.Properties["Classroom"].(string)
+			if clockInAt, ok := myEntity.Properties["ClockInAt"].(string); ok {
+				shift.ClockInAt, _ = time.Parse(time.RFC3339, clockInAt)
+			}
+			if clockOutAt, ok := myEntity.Properties["ClockOutAt"].(string); ok && clockOutAt != "" {
+				shift.ClockOutAt, _ = time.Parse(time.RFC3339, clockOutAt)
+			}
+			shifts = append(shifts, shift)
+		}
+	}
+	sort.Slice(shifts, func(i, j int) bool {
+		return shifts[i].ClockInAt.Before(shifts[j].ClockInAt)
+	})
+	return shifts, nil
+}
+
+// Helper - build the table entity for a Shift
+func shiftToEntity(shift models.Shift) aztables.EDMEntity {
+	clockOutAt := ""
+	if !shift.IsOpen() {
+		clockOutAt = shift.ClockOutAt.UTC().Format(time.RFC3339)
+	}
+	return aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: shift.Date,
+			RowKey:       shift.ID,
+		},
+		Properties: map[string]any{
+			"StaffID":    shift.StaffID,
+			"Classroom":  shift.Classroom,
+			"ClockInAt":  shift.ClockInAt.UTC().Format(time.RFC3339),
+			"ClockOutAt": clockOutAt,
+		},
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"littleeinsteinchildcare/backend/internal/services"
 	"log"
 	"os"
-	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
@@ -79,7 +78,7 @@ func (repo *UserRepository) GetUser(tableName string, id string) (models.User, e
 		ID:    myEntity.RowKey,
 		Name:  myEntity.Properties["Username"].(string),
 		Email: myEntity.Properties["Email"].(string),
-		Role:  myEntity.Properties["Role"].(string),
+		Role:  models.NormalizeRole(myEntity.Properties["Role"].(string)),
 	}
 
 	if entityImages, ok := myEntity.Properties["Images"]; ok {
@@ -91,6 +90,11 @@ func (repo *UserRepository) GetUser(tableName string, id string) (models.User, e
 			user.Images = images
 		}
 	}
+	if classroomsString, ok := myEntity.Properties["Classrooms"].(string); ok && classroomsString != "" {
+		if err := json.Unmarshal([]byte(classroomsString), &user.Classrooms); err != nil {
+			return models.User{}, fmt.Errorf("UserRepository.GetUser: Failed to parse Classroom IDs")
+		}
+	}
 	return user, nil
 }
 
@@ -109,9 +113,7 @@ func (repo *UserRepository) GetAllUsers(tableName string) ([]models.User, error)
 		response, err := pager.NextPage(ctx)
 		if err != nil {
 			// If table doesn't exist, return empty array instead of error
-			// Azure returns "TableNotFound" error code when table doesn't exist
-			errMsg := err.Error()
-			if strings.Contains(errMsg, "TableNotFound") {
+			if isTableNotFound(err) {
 				return []models.User{}, nil
 			}
 			return []models.User{}, fmt.Errorf("UserRepository.GetAllUsers: Failed to acquire next page: %w", err)
@@ -128,7 +130,7 @@ func (repo *UserRepository) GetAllUsers(tableName string) ([]models.User, error)
 				ID:    myEntity.RowKey,
 				Name:  myEntity.Properties["Username"].(string),
 				Email: myEntity.Properties["Email"].(string),
-				Role:  myEntity.Properties["Role"].(string),
+				Role:  models.NormalizeRole(myEntity.Properties["Role"].(string)),
 			}
 
 			if entityImages, ok := myEntity.Properties["Images"]; ok {
@@ -140,6 +142,11 @@ func (repo *UserRepository) GetAllUsers(tableName string) ([]models.User, error)
 					user.Images = images
 				}
 			}
+			if classroomsString, ok := myEntity.Properties["Classrooms"].(string); ok && classroomsString != "" {
+				if err := json.Unmarshal([]byte(classroomsString), &user.Classrooms); err != nil {
+					return []models.User{}, fmt.Errorf("UserRepository.GetAllUsers: Failed to parse Classroom IDs")
+				}
+			}
 
 			users = append(users, user)
 		}
@@ -168,7 +175,7 @@ func (repo *UserRepository) CreateUser(tableName string, user models.User) error
 		Properties: map[string]any{
 			"Username": user.Name,
 			"Email":    user.Email,
-			"Role":     user.Role,
+			"Role":     string(user.Role),
 			"Images":   imagesStr,
 		},
 	}
@@ -250,11 +257,11 @@ func (repo *UserRepository) UpdateUser(tableName string, newUserData models.User
 		Properties: map[string]any{
 			"Username": user.Name,
 			"Email":    user.Email,
-			"Role":     user.Role,
 			"Images":   imagesStr,
 		},
 	}
 
+	// Role is left out so the merge keeps the stored value, UpdateRole is the only writer
 	serializedEntity, err := json.Marshal(userEntity)
 	if err != nil {
 		return models.User{}, fmt.Errorf("UserRepository.UpdateUser: Failed to serialize user data: %w", err)
@@ -267,6 +274,43 @@ func (repo *UserRepository) UpdateUser(tableName string, newUserData models.User
 	return user, nil
 }
 
+// UpdateRole merges a new Role and classroom assignment into an existing user entity, leaving the other properties untouched
+func (repo *UserRepository) UpdateRole(tableName string, id string, role models.Role, classrooms []string) error {
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	if classrooms == nil {
+		classrooms = []string{}
+	}
+	classroomsStr, err := json.Marshal(classrooms)
+	if err != nil {
+		return fmt.Errorf("UserRepository.UpdateRole: Failed to serialize classrooms: %w", err)
+	}
+
+	userEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: PartitionKey,
+			RowKey:       id,
+		},
+		Properties: map[string]any{
+			"Role":       string(role),
+			"Classrooms": string(classroomsStr),
+		},
+	}
+
+	serializedEntity, err := json.Marshal(userEntity)
+	if err != nil {
+		return fmt.Errorf("UserRepository.UpdateRole: Failed to serialize user data: %w", err)
+	}
+	_, err = tableClient.UpdateEntity(context.Background(), serializedEntity, &aztables.UpdateEntityOptions{
+		IfMatch:    to.Ptr(azcore.ETagAny),
+		UpdateMode: aztables.UpdateModeMerge,
+	})
+	if err != nil {
+		return fmt.Errorf("UserRepository.UpdateRole: Failed to update role for user ID %s in %s: %w", id, tableName, err)
+	}
+	return nil
+}
+
 func (repo *UserRepository) DeleteUser(tableName string, id string) error {
 	ctx := context.Background()
 	tableClient := repo.serviceClient.NewClient(tableName)
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/config"
+	"littleeinsteinchildcare/backend/internal/models"
+	"littleeinsteinchildcare/backend/internal/services"
+	"sort"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
+)
+
+// ApplicationPartitionKey is the single partition enrollment applications are stored under
+const ApplicationPartitionKey = "Applications"
+
+// rankSlotPartitionPrefix starts the partition holding the reserved ranks of an age group, e.g. RankSlots-infant
+const rankSlotPartitionPrefix = "RankSlots-"
+
+// WaitlistRepository stores enrollment applications
+type WaitlistRepository struct {
+	serviceClient aztables.ServiceClient
+}
+
+// NewWaitlistRepo creates and returns a new WaitlistRepository object
+func NewWaitlistRepo(cfg config.AzTableConfig) (services.WaitlistRepo, error) {
+	client, err := newServiceClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("WaitlistRepository.NewWaitlistRepo: %w", err)
+	}
+	return &WaitlistRepository{serviceClient: *client}, nil
+}
+
+// GetApplication returns a single application
+func (repo *WaitlistRepository) GetApplication(tableName string, id string) (models.Application, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	response, err := tableClient.GetEntity(context.Background(), ApplicationPartitionKey, id, nil)
+	if err != nil {
+		return models.Application{}, fmt.Errorf("WaitlistRepository.GetApplication: Failed to get application %s: %w", id, err)
+	}
+	var myEntity aztables.EDMEntity
+	if err := json.Unmarshal(response.Value, &myEntity); err != nil {
+		return models.Application{}, fmt.Errorf("WaitlistRepository.GetApplication: Failed to unmarshal entity: %w", err)
+	}
+	return applicationFromEntity(myEntity), nil
+}
+
+// GetApplications returns the applications matching an extra OData filter, in waitlist order within each age group
+func (repo *WaitlistRepository) GetApplications(tableName string, filter string) ([]models.Application, error) {
+	tableClient := repo.serviceClient.NewClient(tableName)
+	query := fmt.Sprintf("PartitionKey eq '%s'", ApplicationPartitionKey)
+	if filter != "" {
+		query = fmt.Sprintf("%s and %s", query, filter)
+	}
+	options := &aztables.ListEntitiesOptions{
+		Filter: &query,
+	}
+
+	applications := []models.Application{}
+	pager := tableClient.NewListEntitiesPager(options)
+	for pager.More() {
+		response, err := pager.NextPage(context.Background())
+		if err != nil {
+			if isTableNotFound(err) {
+				return applications, nil
+			}
+			return nil, fmt.Errorf("WaitlistRepository.GetApplications: Failed to acquire next page: %w", err)
+		}
+		for _, tableData := range response.Entities {
+			var myEntity aztables.EDMEntity
+			if err := json.Unmarshal(tableData, &myEntity); err != nil {
+				return nil, fmt.Errorf("WaitlistRepository.GetApplications: Failed to unmarshal entity: %w", err)
+			}
+			applications = append(applications, applicationFromEntity(myEntity))
+		}
+	}
+
+	sort.SliceStable(applications, func(i, j int) bool {
+		a, b := applications[i], applications[j]
+		if a.AgeGroup != b.AgeGroup {
+			return a.AgeGroup < b.AgeGroup
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		return a.SubmittedAt.Before(b.SubmittedAt)
+	})
+	return applications, nil
+}
+
+// ReserveRank claims a rank in an age group's waitlist for an application. The slot row is only added if no
+// application holds the rank yet, otherwise services.ErrRankTaken is returned
+func (repo *WaitlistRepository) ReserveRank(tableName string, ageGroup string, rank int, applicationID string) error {
+	slotEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: rankSlotPartitionPrefix + ageGroup,
+			RowKey:       fmt.Sprintf("%010d", rank),
+		},
+		Properties: map[string]any{
+			"ApplicationID": applicationID,
+		},
+	}
+	serializedEntity, err := json.Marshal(slotEntity)
+	if err != nil {
+		return fmt.Errorf("WaitlistRepository.ReserveRank: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.AddEntity(context.Background(), serializedEntity, nil)
+	if isConflict(err) {
+		return fmt.Errorf("WaitlistRepository.ReserveRank: Rank %d in %s: %w", rank, ageGroup, services.ErrRankTaken)
+	}
+	if err != nil {
+		return fmt.Errorf("WaitlistRepository.ReserveRank: Failed to add entity to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// UpsertApplication adds or replaces an application, creating the table if it doesn't exist
+func (repo *WaitlistRepository) UpsertApplication(tableName string, application models.Application) error {
+	applicationEntity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: ApplicationPartitionKey,
+			RowKey:       application.ID,
+		},
+		Properties: map[string]any{
+			"ParentName":     application.ParentName,
+			"Email":          application.Email,
+			"Phone":          application.Phone,
+			"ChildName":      application.ChildName,
+			"ChildBirthdate": application.ChildBirthdate,
+			"DesiredStart":   application.DesiredStart,
+			"AgeGroup":       application.AgeGroup,
+			"Notes":          application.Notes,
+			"Status":         string(application.Status),
+			"Rank":           int32(application.Rank),
+			"SubmittedAt":    formatOptionalTime(application.SubmittedAt),
+			"UpdatedAt":      formatOptionalTime(application.UpdatedAt),
+			"ChildID":        application.ChildID,
+		},
+	}
+	serializedEntity, err := json.Marshal(applicationEntity)
+	if err != nil {
+		return fmt.Errorf("WaitlistRepository.UpsertApplication: Failed to serialize entity: %w", err)
+	}
+
+	_, _ = repo.serviceClient.CreateTable(context.Background(), tableName, nil)
+	tableClient := repo.serviceClient.NewClient(tableName)
+
+	_, err = tableClient.UpsertEntity(context.Background(), serializedEntity, &aztables.UpsertEntityOptions{UpdateMode: aztables.UpdateModeReplace})
+	if err != nil {
+		return fmt.Errorf("WaitlistRepository.UpsertApplication: Failed to upsert entity in %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// Helper - map a table entity onto an Application
+func applicationFromEntity(myEntity aztables.EDMEntity) models.Application {
+	application := models.Application{ID: myEntity.RowKey}
+	application.ParentName, _ = myEntity.Properties["ParentName"].(string)
+	application.Email, _ = myEntity.Properties["Email"].(string)
+	application.Phone, _ = myEntity.Properties["Phone"].(string)
+	application.ChildName, _ = myEntity.Properties["ChildName"].(string)
+	application.ChildBirthdate, _ = myEntity.Properties["ChildBirthdate"].(string)
+	application.DesiredStart, _ = myEntity.Properties["DesiredStart"].(string)
+	application.AgeGroup, _ = myEntity.Properties["AgeGroup"].(string)
+	application.Notes, _ = myEntity.Properties["Notes"].(string)
+	status, _ := myEntity.Properties["Status"].(string)
+	application.Status = models.ApplicationStatus(status)
+	if rank, ok := myEntity.Properties["Rank"].(int32); ok {
+		application.Rank = int(rank)
+	}
+	application.SubmittedAt = parseOptionalTime(myEntity.Properties, "SubmittedAt")
+	application.UpdatedAt = parseOptionalTime(myEntity.Properties, "UpdatedAt")
+	application.ChildID, _ = myEntity.Properties["ChildID"].(string)
+	return application
+}
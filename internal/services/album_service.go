@@ -0,0 +1,348 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/imaging"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+)
+
+const ALBUMSTABLE = "AlbumsTable"
+const ALBUMPHOTOSTABLE = "AlbumPhotosTable"
+
+// ALBUMPHOTOPREFIX keeps album photos apart from user images so they are only served through their album
+const ALBUMPHOTOPREFIX = "albums/"
+
+// AlbumRepo interface methods implemented in repositories package
+type AlbumRepo interface {
+	GetAlbum(tableName string, id string) (models.Album, error)
+	GetAlbums(tableName string) ([]models.Album, error)
+	UpsertAlbum(tableName string, album models.Album) error
+	GetPhoto(tableName string, albumID string, id string) (models.AlbumPhoto, error)
+	GetPhotos(tableName string, filter string) ([]models.AlbumPhoto, error)
+	UpsertPhoto(tableName string, photo models.AlbumPhoto) error
+	DeletePhoto(tableName string, albumID string, id string) error
+}
+
+// AlbumService lets staff share event and classroom photos, each family only seeing photos of their own children
+// or photos shared with the whole classroom
+type AlbumService struct {
+	repo      AlbumRepo
+	blobRepo  BlobRepo
+	childRepo ChildRepo
+	userRepo  UserRepo
+	eventRepo EventRepo
+}
+
+// NewAlbumService constructs and returns an AlbumService object
+func NewAlbumService(r AlbumRepo, b BlobRepo, c ChildRepo, u UserRepo, e EventRepo) *AlbumService {
+	return &AlbumService{repo: r, blobRepo: b, childRepo: c, userRepo: u, eventRepo: e}
+}
+
+// photoViewer is the acting user with the children and classrooms they are a guardian in
+type photoViewer struct {
+	actor      models.User
+	children   map[string]bool
+	classrooms map[string]bool
+}
+
+// CreateAlbum starts an album for a classroom, or for an event in which case the event's classroom is used.
+// Staff with the manage photos permission for the classroom only
+func (s *AlbumService) CreateAlbum(actorID string, album models.Album) (models.Album, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Album{}, err
+	}
+	if album.EventID != "" {
+		event, err := s.eventRepo.GetEvent(EVENTSTABLE, album.EventID)
+		if err != nil {
+			return models.Album{}, fmt.Errorf("AlbumService.CreateAlbum: Event %s not found: %w", album.EventID, err)
+		}
+		album.Classroom = event.Classroom
+	}
+	if err := authorizeClassroom(actor, models.PermissionManagePhotos, album.Classroom); err != nil {
+		return models.Album{}, err
+	}
+	if err := s.repo.UpsertAlbum(ALBUMSTABLE, album); err != nil {
+		return models.Album{}, err
+	}
+	return album, nil
+}
+
+// GetAlbums returns the albums the acting user can see at least one photo in, with how many they can see
+func (s *AlbumService) GetAlbums(actorID string) ([]models.Album, error) {
+	viewer, err := s.getViewer(actorID)
+	if err != nil {
+		return nil, err
+	}
+	albums, err := s.repo.GetAlbums(ALBUMSTABLE)
+	if err != nil {
+		return nil, err
+	}
+	children, err := s.childrenByID()
+	if err != nil {
+		return nil, err
+	}
+	visible := []models.Album{}
+	for _, album := range albums {
+		photos, err := s.visiblePhotos(viewer, album, children)
+		if err != nil {
+			return nil, err
+		}
+		album.PhotoCount = len(photos)
+		if album.PhotoCount > 0 || canManageAlbum(viewer.actor, album) {
+			visible = append(visible, album)
+		}
+	}
+	return visible, nil
+}
+
+// GetPhotos returns the photos in an album the acting user can see
+func (s *AlbumService) GetPhotos(actorID string, albumID string) ([]models.AlbumPhoto, error) {
+	viewer, err := s.getViewer(actorID)
+	if err != nil {
+		return nil, err
+	}
+	album, err := s.repo.GetAlbum(ALBUMSTABLE, albumID)
+	if err != nil {
+		return nil, err
+	}
+	children, err := s.childrenByID()
+	if err != nil {
+		return nil, err
+	}
+	return s.visiblePhotos(viewer, album, children)
+}
+
+// GetPhotoImage opens a photo the acting user can see for streaming
+func (s *AlbumService) GetPhotoImage(ctx context.Context, actorID string, albumID string, photoID string) (*models.ImageStream, error) {
+	viewer, err := s.getViewer(actorID)
+	if err != nil {
+		return nil, err
+	}
+	album, err := s.repo.GetAlbum(ALBUMSTABLE, albumID)
+	if err != nil {
+		return nil, err
+	}
+	photo, err := s.repo.GetPhoto(ALBUMPHOTOSTABLE, albumID, photoID)
+	if err != nil {
+		return nil, err
+	}
+	children, err := s.childrenByID()
+	if err != nil {
+		return nil, err
+	}
+	if !canViewPhoto(viewer, album, photo, children) {
+		return nil, fmt.Errorf("%w: photo %s is not shared with %s", ErrForbidden, photoID, actorID)
+	}
+	return s.blobRepo.OpenBlob(ctx, photo.BlobName)
+}
+
+// AddPhoto uploads a photo to an album tagged with the children in it, staff managing the album only
+func (s *AlbumService) AddPhoto(ctx context.Context, actorID string, photo models.AlbumPhoto, data []byte) (models.AlbumPhoto, error) {
+	album, err := s.authorizeAlbum(actorID, photo.AlbumID)
+	if err != nil {
+		return models.AlbumPhoto{}, err
+	}
+	if err := s.validateTags(album, photo.ChildIDs); err != nil {
+		return models.AlbumPhoto{}, err
+	}
+
+	// Album photos are shared with other families, so location and camera metadata is stripped first
+	var scrubbed bytes.Buffer
+	if _, err := imaging.Sanitize(&scrubbed, bytes.NewReader(data), nil); err != nil {
+		return models.AlbumPhoto{}, fmt.Errorf("AlbumService.AddPhoto: Failed to strip photo metadata: %w", err)
+	}
+
+	photo.BlobName = fmt.Sprintf("%s%s/%s", ALBUMPHOTOPREFIX, album.ID, photo.ID)
+	metadata := map[string]string{"id": actorID, "album": album.ID, "classroom": album.Classroom}
+	if _, err := s.blobRepo.UploadBlob(ctx, photo.BlobName, photo.ContentType, scrubbed.Bytes(), metadata); err != nil {
+		return models.AlbumPhoto{}, err
+	}
+	if err := s.repo.UpsertPhoto(ALBUMPHOTOSTABLE, photo); err != nil {
+		return models.AlbumPhoto{}, err
+	}
+	return photo, nil
+}
+
+// TagPhoto replaces the children tagged in a photo and its visibility, staff managing the album only
+func (s *AlbumService) TagPhoto(actorID string, albumID string, photoID string, childIDs []string, visibility models.PhotoVisibility) (models.AlbumPhoto, error) {
+	album, err := s.authorizeAlbum(actorID, albumID)
+	if err != nil {
+		return models.AlbumPhoto{}, err
+	}
+	photo, err := s.repo.GetPhoto(ALBUMPHOTOSTABLE, albumID, photoID)
+	if err != nil {
+		return models.AlbumPhoto{}, err
+	}
+	tagged := models.NewAlbumPhoto(photo.ID, photo.AlbumID, photo.ContentType, photo.Caption, childIDs, visibility, photo.UploadedBy, photo.UploadedAt)
+	if err := s.validateTags(album, tagged.ChildIDs); err != nil {
+		return models.AlbumPhoto{}, err
+	}
+	photo.ChildIDs = tagged.ChildIDs
+	photo.Visibility = visibility
+	if err := s.repo.UpsertPhoto(ALBUMPHOTOSTABLE, photo); err != nil {
+		return models.AlbumPhoto{}, err
+	}
+	return photo, nil
+}
+
+// DeletePhoto removes a photo and its image, staff managing the album only
+func (s *AlbumService) DeletePhoto(ctx context.Context, actorID string, albumID string, photoID string) error {
+	if _, err := s.authorizeAlbum(actorID, albumID); err != nil {
+		return err
+	}
+	photo, err := s.repo.GetPhoto(ALBUMPHOTOSTABLE, albumID, photoID)
+	if err != nil {
+		return err
+	}
+	if err := s.blobRepo.DeleteBlob(ctx, photo.BlobName); err != nil {
+		log.Printf("AlbumService.DeletePhoto: Failed to delete image for photo %s: %v", photoID, err)
+	}
+	return s.repo.DeletePhoto(ALBUMPHOTOSTABLE, albumID, photoID)
+}
+
+// GetPhotoReviews lists every tagged child whose media consent does not cover how their photo is shared
+func (s *AlbumService) GetPhotoReviews() ([]models.PhotoReview, error) {
+	albums, err := s.repo.GetAlbums(ALBUMSTABLE)
+	if err != nil {
+		return nil, err
+	}
+	children, err := s.childrenByID()
+	if err != nil {
+		return nil, err
+	}
+	reviews := []models.PhotoReview{}
+	for _, album := range albums {
+		photos, err := s.repo.GetPhotos(ALBUMPHOTOSTABLE, fmt.Sprintf("PartitionKey eq '%s'", album.ID))
+		if err != nil {
+			return nil, err
+		}
+		for _, photo := range photos {
+			reviews = append(reviews, models.ReviewPhoto(album, photo, children)...)
+		}
+	}
+	return reviews, nil
+}
+
+// visiblePhotos returns the photos in an album the viewer can see
+func (s *AlbumService) visiblePhotos(viewer photoViewer, album models.Album, children map[string]models.Child) ([]models.AlbumPhoto, error) {
+	photos, err := s.repo.GetPhotos(ALBUMPHOTOSTABLE, fmt.Sprintf("PartitionKey eq '%s'", album.ID))
+	if err != nil {
+		return nil, err
+	}
+	visible := []models.AlbumPhoto{}
+	for _, photo := range photos {
+		if canViewPhoto(viewer, album, photo, children) {
+			visible = append(visible, photo)
+		}
+	}
+	return visible, nil
+}
+
+// validateTags checks every tagged child exists and, for classroom albums, is in the album's classroom
+func (s *AlbumService) validateTags(album models.Album, childIDs []string) error {
+	for _, childID := range childIDs {
+		child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+		if err != nil {
+			return fmt.Errorf("AlbumService: Tagged child %s not found: %w", childID, err)
+		}
+		if album.Classroom != "" && child.Classroom != album.Classroom {
+			return fmt.Errorf("AlbumService: Child %s is not in classroom %s", childID, album.Classroom)
+		}
+	}
+	return nil
+}
+
+// authorizeAlbum loads an album and checks the acting user may add, tag and remove its photos
+func (s *AlbumService) authorizeAlbum(actorID string, albumID string) (models.Album, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Album{}, err
+	}
+	album, err := s.repo.GetAlbum(ALBUMSTABLE, albumID)
+	if err != nil {
+		return models.Album{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManagePhotos, album.Classroom); err != nil {
+		return models.Album{}, err
+	}
+	return album, nil
+}
+
+// getViewer loads the acting user with the children they are a guardian of and those children's classrooms
+func (s *AlbumService) getViewer(actorID string) (photoViewer, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return photoViewer{}, err
+	}
+	viewer := photoViewer{actor: actor, children: make(map[string]bool), classrooms: make(map[string]bool)}
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("RowKey eq '%s'", actorID))
+	if err != nil {
+		return photoViewer{}, err
+	}
+	for _, link := range links {
+		child, err := s.childRepo.GetChild(CHILDRENTABLE, link.ChildID)
+		if err != nil {
+			// Link left behind by a deleted child
+			continue
+		}
+		viewer.children[child.ID] = true
+		viewer.classrooms[child.Classroom] = true
+	}
+	return viewer, nil
+}
+
+// childrenByID loads every child, used to check the media consent of tagged children
+func (s *AlbumService) childrenByID() (map[string]models.Child, error) {
+	children, err := s.childRepo.GetAllChildren(CHILDRENTABLE)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Child, len(children))
+	for _, child := range children {
+		byID[child.ID] = child
+	}
+	return byID, nil
+}
+
+func (s *AlbumService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("AlbumService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
+
+// Helper - staff with the manage photos permission for the album's classroom
+func canManageAlbum(actor models.User, album models.Album) bool {
+	return authorizeClassroom(actor, models.PermissionManagePhotos, album.Classroom) == nil
+}
+
+// Helper - staff see every photo in albums they manage and in center wide albums. Guardians see photos their
+// children are tagged in, and classroom shared photos when they have a child in the classroom, but never a photo
+// of another family's child whose media consent does not reach that far
+func canViewPhoto(viewer photoViewer, album models.Album, photo models.AlbumPhoto, children map[string]models.Child) bool {
+	if canManageAlbum(viewer.actor, album) || (viewer.actor.Role.IsStaff() && album.Classroom == "") {
+		return true
+	}
+	for _, childID := range photo.ChildIDs {
+		if !viewer.children[childID] && !children[childID].MediaConsent.Allows(album.SharingLevel()) {
+			return false
+		}
+	}
+	for _, childID := range photo.ChildIDs {
+		if viewer.children[childID] {
+			return true
+		}
+	}
+	if photo.Visibility != models.PhotoClassroom {
+		return false
+	}
+	if album.Classroom == "" {
+		return len(viewer.children) > 0
+	}
+	return viewer.classrooms[album.Classroom]
+}
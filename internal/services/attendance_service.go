@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"time"
+)
+
+const ATTENDANCETABLE = "AttendanceTable"
+
+// ErrAlreadyCheckedIn is returned when a child with an open visit is checked in again
+var ErrAlreadyCheckedIn = errors.New("child is already checked in")
+
+// ErrNotCheckedIn is returned when a child who isn't present is checked out
+var ErrNotCheckedIn = errors.New("child is not checked in")
+
+// AttendanceRepo interface methods implemented in repositories package
+type AttendanceRepo interface {
+	CreateRecord(tableName string, record models.AttendanceRecord) error
+	UpdateRecord(tableName string, record models.AttendanceRecord) error
+	GetRecords(tableName string, date string, filter string) ([]models.AttendanceRecord, error)
+}
+
+// PickupVerifier checks the adult collecting a child and records the attempt
+type PickupVerifier interface {
+	AuthorizeCheckout(actorID string, attendanceID string, childID string, pickedUpBy string, pickupID string, overrideReason string) (string, error)
+}
+
+// AttendanceService records drop-off and pickup for children, staff covering the child's classroom only
+type AttendanceService struct {
+	repo      AttendanceRepo
+	childRepo ChildRepo
+	userRepo  UserRepo
+	pickups   PickupVerifier
+}
+
+// NewAttendanceService constructs and returns an AttendanceService object
+func NewAttendanceService(r AttendanceRepo, c ChildRepo, u UserRepo, p PickupVerifier) *AttendanceService {
+	return &AttendanceService{repo: r, childRepo: c, userRepo: u, pickups: p}
+}
+
+// CheckIn starts a visit for a child, rejecting the check-in if the child is already present
+func (s *AttendanceService) CheckIn(actorID string, childID string, droppedOffBy string) (models.AttendanceRecord, error) {
+	child, err := s.authorizeChild(actorID, childID)
+	if err != nil {
+		return models.AttendanceRecord{}, err
+	}
+	now := models.CenterNow()
+	visits, err := s.getVisits(now.Format(models.AttendanceDateFormat), childID)
+	if err != nil {
+		return models.AttendanceRecord{}, err
+	}
+	if len(visits) > 0 && !visits[len(visits)-1].IsCheckedOut() {
+		return models.AttendanceRecord{}, fmt.Errorf("AttendanceService.CheckIn: %s at %s: %w", childID, visits[len(visits)-1].CheckInAt.In(models.CenterLocation()).Format(time.Kitchen), ErrAlreadyCheckedIn)
+	}
+
+	// Visit numbers make the row key unique per child and day, so a concurrent duplicate check-in conflicts in storage
+	id := fmt.Sprintf("%s-%d", childID, len(visits)+1)
+	record, err := models.NewAttendanceRecord(id, child, droppedOffBy, actorID, now)
+	if err != nil {
+		return models.AttendanceRecord{}, err
+	}
+	if err := s.repo.CreateRecord(ATTENDANCETABLE, *record); err != nil {
+		return models.AttendanceRecord{}, err
+	}
+	return *record, nil
+}
+
+// CheckOut closes the child's open visit for today once the adult collecting them is verified against the pickup list.
+// pickupID optionally names the guardian or authorized pickup staff looked up, overrideReason lets an admin release to an unlisted adult
+func (s *AttendanceService) CheckOut(actorID string, childID string, pickedUpBy string, pickupID string, overrideReason string) (models.AttendanceRecord, error) {
+	if pickedUpBy == "" && pickupID == "" {
+		return models.AttendanceRecord{}, errors.New("the adult picking up is required")
+	}
+	if _, err := s.authorizeChild(actorID, childID); err != nil {
+		return models.AttendanceRecord{}, err
+	}
+	now := models.CenterNow()
+	visits, err := s.getVisits(now.Format(models.AttendanceDateFormat), childID)
+	if err != nil {
+		return models.AttendanceRecord{}, err
+	}
+	if len(visits) == 0 || visits[len(visits)-1].IsCheckedOut() {
+		return models.AttendanceRecord{}, fmt.Errorf("AttendanceService.CheckOut: %s: %w", childID, ErrNotCheckedIn)
+	}
+
+	record := visits[len(visits)-1]
+	pickedUpBy, err = s.pickups.AuthorizeCheckout(actorID, record.ID, childID, pickedUpBy, pickupID, overrideReason)
+	if err != nil {
+		return models.AttendanceRecord{}, err
+	}
+	record.PickedUpBy = pickedUpBy
+	record.CheckOutAt = now
+	record.CheckOutStaffID = actorID
+	if err := s.repo.UpdateRecord(ATTENDANCETABLE, record); err != nil {
+		return models.AttendanceRecord{}, err
+	}
+	return record, nil
+}
+
+// GetDayRoster lists every child enrolled in a classroom with their visits on the given date
+func (s *AttendanceService) GetDayRoster(actorID string, classroomID string, date string) (models.AttendanceRoster, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.AttendanceRoster{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageAttendance, classroomID); err != nil {
+		return models.AttendanceRoster{}, err
+	}
+	if date == "" {
+		date = models.CenterNow().Format(models.AttendanceDateFormat)
+	}
+	if _, err := time.Parse(models.AttendanceDateFormat, date); err != nil {
+		return models.AttendanceRoster{}, errors.New("date must be in YYYY-MM-DD format")
+	}
+
+	children, err := s.childRepo.GetChildrenByClassroom(CHILDRENTABLE, classroomID)
+	if err != nil {
+		return models.AttendanceRoster{}, err
+	}
+	records, err := s.repo.GetRecords(ATTENDANCETABLE, date, fmt.Sprintf("Classroom eq '%s'", classroomID))
+	if err != nil {
+		return models.AttendanceRoster{}, err
+	}
+	visitsByChild := make(map[string][]models.AttendanceRecord)
+	for _, record := range records {
+		visitsByChild[record.ChildID] = append(visitsByChild[record.ChildID], record)
+	}
+
+	roster := models.AttendanceRoster{Classroom: classroomID, Date: date, Children: []models.AttendanceRosterEntry{}}
+	for _, child := range children {
+		visits := visitsByChild[child.ID]
+		if visits == nil {
+			visits = []models.AttendanceRecord{}
+		}
+		present := len(visits) > 0 && !visits[len(visits)-1].IsCheckedOut()
+		if present {
+			roster.PresentCount++
+		}
+		roster.Children = append(roster.Children, models.AttendanceRosterEntry{Child: child, Present: present, Visits: visits})
+	}
+	return roster, nil
+}
+
+// getVisits returns a child's visits on a date, oldest first
+func (s *AttendanceService) getVisits(date string, childID string) ([]models.AttendanceRecord, error) {
+	return s.repo.GetRecords(ATTENDANCETABLE, date, fmt.Sprintf("ChildID eq '%s'", childID))
+}
+
+// authorizeChild loads a child and checks the acting user may record attendance for its classroom
+func (s *AttendanceService) authorizeChild(actorID string, childID string) (models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageAttendance, child.Classroom); err != nil {
+		return models.Child{}, err
+	}
+	return child, nil
+}
+
+func (s *AttendanceService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("AttendanceService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"sort"
+	"time"
+)
+
+const TUITIONPLANSTABLE = "TuitionPlansTable"
+const INVOICESTABLE = "InvoicesTable"
+
+// ErrInvoiceExists is returned when an invoice ID is already taken
+var ErrInvoiceExists = errors.New("invoice already exists")
+
+// ErrInvoiceState is returned when an invoice is paid or voided after it has left the open state
+var ErrInvoiceState = errors.New("invoice is not open")
+
+// BillingRepo interface methods implemented in repositories package
+type BillingRepo interface {
+	GetPlan(tableName string, childID string) (models.TuitionPlan, error)
+	GetAllPlans(tableName string) ([]models.TuitionPlan, error)
+	UpsertPlan(tableName string, plan models.TuitionPlan) error
+	CreateInvoice(tableName string, invoice models.Invoice) error
+	UpdateInvoice(tableName string, invoice models.Invoice) error
+	GetInvoice(tableName string, id string) (models.Invoice, error)
+	GetInvoices(tableName string, filter string) ([]models.Invoice, error)
+}
+
+// BillingService manages tuition plans and the monthly invoices sent to each family
+type BillingService struct {
+	repo           BillingRepo
+	childRepo      ChildRepo
+	userRepo       UserRepo
+	attendanceRepo AttendanceRepo
+	emailService   EmailService
+	payments       PaymentProvider
+	latePickup     models.LatePickupPolicy
+	dueDays        int
+}
+
+// NewBillingService constructs and returns a BillingService object
+func NewBillingService(r BillingRepo, c ChildRepo, u UserRepo, a AttendanceRepo, e EmailService, p PaymentProvider, latePickup models.LatePickupPolicy, dueDays int) *BillingService {
+	return &BillingService{repo: r, childRepo: c, userRepo: u, attendanceRepo: a, emailService: e, payments: p, latePickup: latePickup, dueDays: dueDays}
+}
+
+// GetPlan returns a child's tuition plan to billing staff and the child's guardians
+func (s *BillingService) GetPlan(actorID string, childID string) (models.TuitionPlan, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.TuitionPlan{}, err
+	}
+	if !actor.Role.Can(models.PermissionManageBilling) && !s.isGuardian(actor.ID, childID) {
+		return models.TuitionPlan{}, fmt.Errorf("%w: %s may not view the tuition plan for child %s", ErrForbidden, actor.ID, childID)
+	}
+	return s.repo.GetPlan(TUITIONPLANSTABLE, childID)
+}
+
+// SetPlan creates or replaces a child's tuition plan, billing staff only. The family must be one of the child's guardians
+func (s *BillingService) SetPlan(actorID string, plan models.TuitionPlan) (models.TuitionPlan, error) {
+	if err := s.authorizeBilling(actorID); err != nil {
+		return models.TuitionPlan{}, err
+	}
+	if _, err := s.childRepo.GetChild(CHILDRENTABLE, plan.ChildID); err != nil {
+		return models.TuitionPlan{}, err
+	}
+	if !s.isGuardian(plan.FamilyID, plan.ChildID) {
+		return models.TuitionPlan{}, fmt.Errorf("BillingService.SetPlan: User %s is not a guardian of child %s", plan.FamilyID, plan.ChildID)
+	}
+	if err := s.repo.UpsertPlan(TUITIONPLANSTABLE, plan); err != nil {
+		return models.TuitionPlan{}, err
+	}
+	return plan, nil
+}
+
+// GenerateInvoices issues and emails an invoice for a YYYY-MM month to every family with an active plan, billing staff only.
+// Families that already have an open or paid invoice for the month are skipped, so it is safe to run again
+func (s *BillingService) GenerateInvoices(actorID string, month string) ([]models.Invoice, error) {
+	if err := s.authorizeBilling(actorID); err != nil {
+		return nil, err
+	}
+	start, err := time.ParseInLocation(models.BillingMonthFormat, month, models.CenterLocation())
+	if err != nil {
+		return nil, fmt.Errorf("BillingService.GenerateInvoices: month must use the YYYY-MM format: %w", err)
+	}
+
+	plans, err := s.repo.GetAllPlans(TUITIONPLANSTABLE)
+	if err != nil {
+		return nil, err
+	}
+	families := make(map[string][]models.TuitionPlan)
+	for _, plan := range plans {
+		families[plan.FamilyID] = append(families[plan.FamilyID], plan)
+	}
+	latePickups, err := s.latePickupsForMonth(start)
+	if err != nil {
+		return nil, err
+	}
+
+	familyIDs := make([]string, 0, len(families))
+	for familyID := range families {
+		familyIDs = append(familyIDs, familyID)
+	}
+	sort.Strings(familyIDs)
+
+	created := []models.Invoice{}
+	for _, familyID := range familyIDs {
+		existing, err := s.repo.GetInvoices(INVOICESTABLE, fmt.Sprintf("FamilyID eq '%s' and Month eq '%s'", familyID, month))
+		if err != nil {
+			return created, err
+		}
+		if hasActiveInvoice(existing) {
+			continue
+		}
+
+		lineItems, err := s.buildLineItems(families[familyID], start, latePickups)
+		if err != nil {
+			return created, err
+		}
+		if len(lineItems) == 0 {
+			continue
+		}
+		id := fmt.Sprintf("%s-%s-%d", familyID, month, len(existing)+1)
+		invoice := models.NewInvoice(id, familyID, month, lineItems, time.Now(), s.dueDays)
+		if err := s.repo.CreateInvoice(INVOICESTABLE, *invoice); err != nil {
+			if errors.Is(err, ErrInvoiceExists) {
+				continue
+			}
+			return created, err
+		}
+		s.emailInvoice(*invoice, fmt.Sprintf("Little Einstein invoice for %s", start.Format("January 2006")))
+		created = append(created, *invoice)
+	}
+	return created, nil
+}
+
+// GetInvoices returns every invoice to billing staff and a family's own invoices to everyone else, optionally filtered by status
+func (s *BillingService) GetInvoices(actorID string, status string) ([]models.Invoice, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+	filter := ""
+	if !actor.Role.Can(models.PermissionManageBilling) {
+		filter = fmt.Sprintf("FamilyID eq '%s'", actor.ID)
+	}
+	if status != "" {
+		if filter != "" {
+			filter += " and "
+		}
+		filter += fmt.Sprintf("Status eq '%s'", status)
+	}
+	return s.repo.GetInvoices(INVOICESTABLE, filter)
+}
+
+// GetInvoice returns an invoice to its family and billing staff
+func (s *BillingService) GetInvoice(actorID string, id string) (models.Invoice, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	invoice, err := s.repo.GetInvoice(INVOICESTABLE, id)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	if invoice.FamilyID != actor.ID && !actor.Role.Can(models.PermissionManageBilling) {
+		return models.Invoice{}, fmt.Errorf("%w: %s may not view invoice %s", ErrForbidden, actor.ID, id)
+	}
+	return invoice, nil
+}
+
+// RenderInvoice returns an invoice as a standalone HTML page
+func (s *BillingService) RenderInvoice(actorID string, id string) (string, error) {
+	invoice, err := s.GetInvoice(actorID, id)
+	if err != nil {
+		return "", err
+	}
+	return s.renderInvoice(invoice)
+}
+
+// PayInvoice charges the family through the payment provider and marks the invoice paid, the invoice's family only
+func (s *BillingService) PayInvoice(ctx context.Context, actorID string, id string, paymentToken string) (models.Invoice, error) {
+	invoice, err := s.repo.GetInvoice(INVOICESTABLE, id)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	if invoice.FamilyID != actorID {
+		return models.Invoice{}, fmt.Errorf("%w: only the invoiced family may pay invoice %s", ErrForbidden, id)
+	}
+	if invoice.Status != models.InvoiceOpen {
+		return models.Invoice{}, fmt.Errorf("BillingService.PayInvoice: Invoice %s is %s: %w", id, invoice.Status, ErrInvoiceState)
+	}
+
+	// The invoice ID is the idempotency key, so concurrent or retried payments charge the family once
+	reference, err := s.payments.Charge(ctx, invoice, paymentToken, invoice.ID)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	if err := invoice.MarkPaid(reference, time.Now()); err != nil {
+		return models.Invoice{}, fmt.Errorf("BillingService.PayInvoice: %v: %w", err, ErrInvoiceState)
+	}
+	// Conditional on the version read above, so a concurrent payment or void is not overwritten
+	err = s.repo.UpdateInvoice(INVOICESTABLE, invoice)
+	if errors.Is(err, ErrInvoiceState) {
+		return s.settleChangedInvoice(ctx, id, reference, err)
+	}
+	if err != nil {
+		log.Printf("BillingService.PayInvoice: Charged %s for invoice %s but failed to record payment: %v", reference, invoice.ID, err)
+		return models.Invoice{}, fmt.Errorf("BillingService.PayInvoice: Charged %s but failed to record payment: %w", reference, err)
+	}
+	s.emailInvoice(invoice, fmt.Sprintf("Payment received for invoice %s", invoice.ID))
+	return invoice, nil
+}
+
+// settleChangedInvoice handles an invoice that changed between being charged and recorded as paid. A concurrent
+// payment with the same idempotency key already recorded this charge, anything else (such as a void) gets it refunded
+func (s *BillingService) settleChangedInvoice(ctx context.Context, id string, reference string, cause error) (models.Invoice, error) {
+	current, err := s.repo.GetInvoice(INVOICESTABLE, id)
+	if err == nil && current.Status == models.InvoicePaid && current.PaymentRef == reference {
+		return current, nil
+	}
+	if err := s.payments.Refund(ctx, reference); err != nil {
+		log.Printf("BillingService.PayInvoice: Charged %s for invoice %s, which changed before it was recorded, and the refund failed: %v", reference, id, err)
+		return models.Invoice{}, fmt.Errorf("BillingService.PayInvoice: Charged %s but invoice %s changed and the refund failed: %v: %w", reference, id, err, cause)
+	}
+	log.Printf("BillingService.PayInvoice: Refunded %s, invoice %s changed before the payment was recorded", reference, id)
+	return models.Invoice{}, fmt.Errorf("BillingService.PayInvoice: Charge %s refunded: %w", reference, cause)
+}
+
+// VoidInvoice cancels an open invoice, billing staff only
+func (s *BillingService) VoidInvoice(actorID string, id string) (models.Invoice, error) {
+	if err := s.authorizeBilling(actorID); err != nil {
+		return models.Invoice{}, err
+	}
+	invoice, err := s.repo.GetInvoice(INVOICESTABLE, id)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	if err := invoice.Void(time.Now()); err != nil {
+		return models.Invoice{}, fmt.Errorf("BillingService.VoidInvoice: %v: %w", err, ErrInvoiceState)
+	}
+	if err := s.repo.UpdateInvoice(INVOICESTABLE, invoice); err != nil {
+		return models.Invoice{}, err
+	}
+	return invoice, nil
+}
+
+// buildLineItems charges each child's tuition, credits sibling discounts and adds late pickup fees
+func (s *BillingService) buildLineItems(plans []models.TuitionPlan, month time.Time, latePickups map[string][]models.AttendanceRecord) ([]models.InvoiceLineItem, error) {
+	type charge struct {
+		plan    models.TuitionPlan
+		name    string
+		amount  int64
+		periods int
+	}
+	charges := []charge{}
+	for _, plan := range plans {
+		amount, periods := plan.ChargeForMonth(month)
+		if amount == 0 {
+			continue
+		}
+		child, err := s.childRepo.GetChild(CHILDRENTABLE, plan.ChildID)
+		if err != nil {
+			return nil, err
+		}
+		charges = append(charges, charge{plan: plan, name: child.Name, amount: amount, periods: periods})
+	}
+	// The most expensive child pays full tuition, siblings get their plan's discount
+	sort.SliceStable(charges, func(i, j int) bool {
+		return charges[i].amount > charges[j].amount
+	})
+
+	lineItems := []models.InvoiceLineItem{}
+	for i, c := range charges {
+		description := fmt.Sprintf("Monthly tuition for %s", c.name)
+		if c.plan.Frequency == models.BillingWeekly {
+			description = fmt.Sprintf("Weekly tuition for %s, %d weeks at %s", c.name, c.periods, models.FormatCents(c.plan.RateCents))
+		}
+		lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemTuition, ChildID: c.plan.ChildID, Description: description, AmountCents: c.amount})
+
+		if i > 0 && c.plan.SiblingDiscountPercent > 0 {
+			discount := c.amount * int64(c.plan.SiblingDiscountPercent) / 100
+			lineItems = append(lineItems, models.InvoiceLineItem{
+				Kind:        models.LineItemSiblingDiscount,
+				ChildID:     c.plan.ChildID,
+				Description: fmt.Sprintf("Sibling discount for %s (%d%%)", c.name, c.plan.SiblingDiscountPercent),
+				AmountCents: -discount,
+			})
+		}
+
+		for _, record := range latePickups[c.plan.ChildID] {
+			minutes := s.latePickup.LateMinutes(record.CheckOutAt)
+			lineItems = append(lineItems, models.InvoiceLineItem{
+				Kind:        models.LineItemLatePickup,
+				ChildID:     c.plan.ChildID,
+				Description: fmt.Sprintf("Late pickup for %s on %s, %d minutes", c.name, record.Date, minutes),
+				AmountCents: int64(minutes) * s.latePickup.FeeCentsPerMinute,
+			})
+		}
+	}
+	return lineItems, nil
+}
+
+// latePickupsForMonth returns each child's visits in the month that were collected after closing and the grace period
+func (s *BillingService) latePickupsForMonth(month time.Time) (map[string][]models.AttendanceRecord, error) {
+	late := make(map[string][]models.AttendanceRecord)
+	if s.latePickup.FeeCentsPerMinute <= 0 {
+		return late, nil
+	}
+	for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+		records, err := s.attendanceRepo.GetRecords(ATTENDANCETABLE, day.Format(models.AttendanceDateFormat), "")
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if record.IsCheckedOut() && s.latePickup.LateMinutes(record.CheckOutAt) > 0 {
+				late[record.ChildID] = append(late[record.ChildID], record)
+			}
+		}
+	}
+	return late, nil
+}
+
+// emailInvoice sends the rendered invoice to the family, failures are logged so billing still succeeds
+func (s *BillingService) emailInvoice(invoice models.Invoice, subject string) {
+	family, err := s.userRepo.GetUser(USERSTABLE, invoice.FamilyID)
+	if err != nil || family.Email == "" {
+		log.Printf("BillingService.emailInvoice: No email address for family %s: %v", invoice.FamilyID, err)
+		return
+	}
+	htmlContent, err := s.renderInvoice(invoice)
+	if err != nil {
+		log.Printf("BillingService.emailInvoice: %v", err)
+		return
+	}
+	plainTextContent := fmt.Sprintf("Invoice %s for %s: %s, status %s, due %s. View it in the Little Einstein app.",
+		invoice.ID, invoice.Month, models.FormatCents(invoice.TotalCents), invoice.Status, invoice.DueDate)
+	if err := s.emailService.SendEmail(family.Email, subject, plainTextContent, htmlContent); err != nil {
+		log.Printf("BillingService.emailInvoice: Failed to email %s: %v", family.Email, err)
+	}
+}
+
+// renderInvoice fills the invoice template with the family's name
+func (s *BillingService) renderInvoice(invoice models.Invoice) (string, error) {
+	familyName := invoice.FamilyID
+	if family, err := s.userRepo.GetUser(USERSTABLE, invoice.FamilyID); err == nil && family.Name != "" {
+		familyName = family.Name
+	}
+	var buffer bytes.Buffer
+	data := struct {
+		models.Invoice
+		FamilyName string
+	}{invoice, familyName}
+	if err := invoiceTemplate.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("BillingService: Failed to render invoice %s: %w", invoice.ID, err)
+	}
+	return buffer.String(), nil
+}
+
+func (s *BillingService) authorizeBilling(actorID string) error {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.Role.Can(models.PermissionManageBilling) {
+		return fmt.Errorf("%w: %s may not %s", ErrForbidden, actor.Role, models.PermissionManageBilling)
+	}
+	return nil
+}
+
+func (s *BillingService) isGuardian(userID string, childID string) bool {
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s' and RowKey eq '%s'", childID, userID))
+	return err == nil && len(links) > 0
+}
+
+func (s *BillingService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("BillingService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
+
+// Helper - whether a family already has an invoice for the month that was not voided
+func hasActiveInvoice(invoices []models.Invoice) bool {
+	for _, invoice := range invoices {
+		if invoice.Status != models.InvoiceVoid {
+			return true
+		}
+	}
+	return false
+}
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": models.FormatCents,
+	"date":  func(t time.Time) string { return t.In(models.CenterLocation()).Format("January 2, 2006") },
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Invoice {{.ID}}</title>
+<style>body{font-family:sans-serif;max-width:720px;margin:2em auto}table{width:100%;border-collapse:collapse}td,th{padding:6px;border-bottom:1px solid #ddd;text-align:left}td.amount,th.amount{text-align:right}.status{text-transform:uppercase;font-weight:bold}</style>
+</head><body>
+<h1>Little Einstein Childcare</h1>
+<h2>Invoice {{.ID}}</h2>
+<p>Billed to: {{.FamilyName}}<br>Month: {{.Month}}<br>Issued: {{date .IssuedAt}}<br>Due: {{.DueDate}}</p>
+<p class="status">{{.Status}}{{if eq .Status "paid"}} on {{date .PaidAt}}, reference {{.PaymentRef}}{{end}}</p>
+<table>
+<tr><th>Description</th><th class="amount">Amount</th></tr>
+{{range .LineItems}}<tr><td>{{.Description}}</td><td class="amount">{{money .AmountCents}}</td></tr>
+{{end}}<tr><th>Total</th><th class="amount">{{money .TotalCents}}</th></tr>
+</table>
+</body></html>`))
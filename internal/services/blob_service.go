@@ -2,53 +2,333 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"littleeinsteinchildcare/backend/internal/imaging"
 	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"net/url"
+	"sort"
+	"time"
 )
 
+// ErrSignedURLUnavailable is returned by BlobRepo.SignBlobURL when storage cannot issue URLs browsers can load
+var ErrSignedURLUnavailable = errors.New("signed URLs are not available for this storage account")
+
+// IMAGEVARIANTDIR is the folder under each user's images that holds resized variants
+const IMAGEVARIANTDIR = "variants"
+
+// ImageVariantBlobName returns where a resized variant of a user's image is stored, e.g. {userID}/variants/thumb/{fileName}
+func ImageVariantBlobName(userID string, fileName string, size models.ImageSize) string {
+	return fmt.Sprintf("%s/%s/%s/%s", userID, IMAGEVARIANTDIR, size, fileName)
+}
+
 type BlobRepo interface {
-	UploadImage(ctx context.Context, fileName string, contentType string, data []byte, userID string) (*models.Image, error)
-	GetImage(ctx context.Context, userID, fileName string) ([]byte, string, error)
-	GetAllImages(ctx context.Context) ([]string, error)
+	UploadImage(ctx context.Context, fileName string, contentType string, data io.Reader, userID string, classroomID string, hash string) (*models.Image, error)
+	GetImageMetadata(ctx context.Context, userID, fileName string) (map[string]string, error)
+	GetAllImages(ctx context.Context, prefix string) ([]string, error)
 	DeleteImage(ctx context.Context, userID, fileName string) error
 	DeleteAllImages(userID string) error
+	UploadBlob(ctx context.Context, blobName string, contentType string, data []byte, metadata map[string]string) (string, error)
+	GetBlob(ctx context.Context, blobName string) ([]byte, string, error)
+	OpenBlob(ctx context.Context, blobName string) (*models.ImageStream, error)
+	SignBlobURL(blobName string, expiry time.Time) (string, error)
+	ListImages(ctx context.Context, userID string) ([]models.Image, error)
+	SetImageHash(ctx context.Context, userID, fileName, hash string) error
+	DeleteBlob(ctx context.Context, blobName string) error
 }
 
 type BlobService struct {
 	blobRepo BlobRepo
+	userRepo UserRepo
+	urlTTL   time.Duration
 }
 
-func NewBlobService(b BlobRepo) *BlobService {
-	return &BlobService{blobRepo: b}
+// NewBlobService creates a blob service whose signed image URLs stay valid for urlTTL
+func NewBlobService(b BlobRepo, u UserRepo, urlTTL time.Duration) *BlobService {
+	return &BlobService{blobRepo: b, userRepo: u, urlTTL: urlTTL}
 }
 
-func (s *BlobService) UploadImage(ctx context.Context, fileName string, contentType string, data []byte, userID string) (*models.Image, error) {
-	img, err := s.blobRepo.UploadImage(ctx, fileName, contentType, data, userID)
+// UploadImage streams an image for userID to storage, optionally shared with a classroom the uploader is allowed
+// to post photos to. Resized variants are built from upright, the pixels the handler already decoded in format, once
+// the original is stored. hash is the SHA-256 of the file as received, when the user already has an image with the
+// same hash that image is returned instead
+func (s *BlobService) UploadImage(ctx context.Context, fileName string, contentType string, data io.Reader, upright *image.RGBA, format string, userID string, classroomID string, hash string) (*models.Image, error) {
+	if classroomID != "" {
+		actor, err := s.userRepo.GetUser(USERSTABLE, userID)
+		if err != nil {
+			return &models.Image{}, fmt.Errorf("BlobService.UploadImage: Failed to load uploader %s: %w", userID, err)
+		}
+		if err := authorizeClassroom(actor, models.PermissionManagePhotos, classroomID); err != nil {
+			return &models.Image{}, err
+		}
+	}
+
+	if existing := s.findDuplicate(ctx, userID, classroomID, hash); existing != nil {
+		return existing, nil
+	}
+
+	img, err := s.blobRepo.UploadImage(ctx, fileName, contentType, data, userID, classroomID, hash)
 	if err != nil {
 		return &models.Image{}, err
 	}
+	// A failure here only costs the variants, the image is served at full size
+	variants, err := s.storeVariants(ctx, userID, fileName, upright, format)
+	if err != nil {
+		log.Printf("BlobService.UploadImage: Failed to store resized variants for %s/%s: %v", userID, fileName, err)
+	}
+	img.Variants = variants
+
+	s.attachSignedURL(img)
 	return img, nil
 }
 
-func (s *BlobService) GetImage(ctx context.Context, userID, fileName string) ([]byte, string, error) {
-	data, contentType, err := s.blobRepo.GetImage(ctx, userID, fileName)
+// findDuplicate returns the user's existing image with the same content hash and classroom, or nil when there is none.
+// Lookup failures are logged and treated as no match so the upload still goes through
+func (s *BlobService) findDuplicate(ctx context.Context, userID, classroomID, hash string) *models.Image {
+	if hash == "" {
+		return nil
+	}
+	images, err := s.blobRepo.ListImages(ctx, userID)
+	if err != nil {
+		log.Printf("BlobService.UploadImage: Skipping duplicate check for %s: %v", userID, err)
+		return nil
+	}
+	for _, image := range images {
+		// A copy shared with another classroom, or kept private, is not visible where this upload is headed
+		if image.Hash == hash && image.Classroom == classroomID {
+			image.Duplicate = true
+			s.attachSignedURL(&image)
+			return &image
+		}
+	}
+	return nil
+}
+
+// attachSignedURL replaces an image's private blob URL with a link the uploader's browser can load
+func (s *BlobService) attachSignedURL(img *models.Image) {
+	signed := s.signImageURL(img.ID, img.Name, models.ImageSizeOriginal)
+	img.URL = signed.URL
+	if signed.ExpiresAt != nil {
+		img.URLExpires = signed.ExpiresAt.Format(time.RFC3339)
+	}
+}
+
+// FindDuplicates groups each user's images that share a content hash and classroom, oldest first. Images stored before hashes
+// were recorded are hashed from their blob, and the hash is saved to their metadata when storeHashes is set
+func (s *BlobService) FindDuplicates(ctx context.Context, storeHashes bool) ([][]models.Image, error) {
+	images, err := s.blobRepo.ListImages(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("BlobService.FindDuplicates: %w", err)
+	}
+
+	groups := map[string][]models.Image{}
+	keys := []string{}
+	for _, image := range images {
+		if image.Hash == "" {
+			if image.Hash, err = s.hashImage(ctx, image.ID, image.Name); err != nil {
+				log.Printf("BlobService.FindDuplicates: Skipping %s/%s: %v", image.ID, image.Name, err)
+				continue
+			}
+			if storeHashes {
+				if err := s.blobRepo.SetImageHash(ctx, image.ID, image.Name, image.Hash); err != nil {
+					log.Printf("BlobService.FindDuplicates: Failed to store hash for %s/%s: %v", image.ID, image.Name, err)
+				}
+			}
+		}
+		// Duplicates are only merged within one user's images shared with the same classroom
+		key := image.ID + "/" + image.Classroom + "/" + image.Hash
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], image)
+	}
+
+	duplicates := [][]models.Image{}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].UploadedAt < group[j].UploadedAt })
+		duplicates = append(duplicates, group)
+	}
+	return duplicates, nil
+}
+
+// hashImage streams an image blob through SHA-256
+func (s *BlobService) hashImage(ctx context.Context, userID, fileName string) (string, error) {
+	stream, err := s.blobRepo.OpenBlob(ctx, fmt.Sprintf("%s/%s", userID, fileName))
+	if err != nil {
+		return "", err
+	}
+	defer stream.Content.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, stream.Content); err != nil {
+		return "", fmt.Errorf("Failed to read %s/%s: %w", userID, fileName, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// OpenImage opens one of ownerID's images at the requested size for streaming, falling back to the original when
+// the variant has not been generated. Staff may open classroom photos uploaded by others
+func (s *BlobService) OpenImage(ctx context.Context, actorID, ownerID, fileName string, size models.ImageSize) (*models.ImageStream, error) {
+	if err := s.authorizeImage(ctx, actorID, ownerID, fileName); err != nil {
+		return nil, err
+	}
+	if size != models.ImageSizeOriginal {
+		stream, err := s.blobRepo.OpenBlob(ctx, ImageVariantBlobName(ownerID, fileName, size))
+		if err == nil {
+			return stream, nil
+		}
+		log.Printf("BlobService.OpenImage: Serving original for missing %s variant of %s/%s", size, ownerID, fileName)
+	}
+	return s.blobRepo.OpenBlob(ctx, fmt.Sprintf("%s/%s", ownerID, fileName))
+}
+
+// SignImageURL mints a short-lived URL for one of ownerID's images that the acting user's browser can load directly
+func (s *BlobService) SignImageURL(ctx context.Context, actorID, ownerID, fileName string, size models.ImageSize) (*models.SignedImageURL, error) {
+	if err := s.authorizeImage(ctx, actorID, ownerID, fileName); err != nil {
+		return nil, err
+	}
+	if _, err := s.blobRepo.GetImageMetadata(ctx, ownerID, fileName); err != nil {
+		return nil, fmt.Errorf("BlobService.SignImageURL: Image %s/%s not found: %w", ownerID, fileName, err)
+	}
+	// Images uploaded before variants existed are signed at their original size
+	if size != models.ImageSizeOriginal {
+		stream, err := s.blobRepo.OpenBlob(ctx, ImageVariantBlobName(ownerID, fileName, size))
+		if err != nil {
+			size = models.ImageSizeOriginal
+		} else {
+			stream.Content.Close()
+		}
+	}
+	signed := s.signImageURL(ownerID, fileName, size)
+	return &signed, nil
+}
+
+// signImageURL signs an image's blob, falling back to the API proxy path when storage cannot sign (local emulator)
+func (s *BlobService) signImageURL(ownerID, fileName string, size models.ImageSize) models.SignedImageURL {
+	blobName := fmt.Sprintf("%s/%s", ownerID, fileName)
+	if size != models.ImageSizeOriginal {
+		blobName = ImageVariantBlobName(ownerID, fileName, size)
+	}
+	expiresAt := time.Now().Add(s.urlTTL)
+	signedURL, err := s.blobRepo.SignBlobURL(blobName, expiresAt)
+	if err == nil {
+		return models.SignedImageURL{URL: signedURL, ExpiresAt: &expiresAt}
+	}
+	if !errors.Is(err, ErrSignedURLUnavailable) {
+		log.Printf("BlobService.signImageURL: Falling back to proxy for %s: %v", blobName, err)
+	}
+
+	proxyURL := fmt.Sprintf("/api/image/%s/%s", url.PathEscape(ownerID), url.PathEscape(fileName))
+	if size != models.ImageSizeOriginal {
+		proxyURL += "?size=" + string(size)
+	}
+	return models.SignedImageURL{URL: proxyURL, Proxied: true}
+}
+
+// HasVariants reports whether every resized variant of an image has been stored, fetching only blob properties
+func (s *BlobService) HasVariants(ctx context.Context, userID, fileName string) bool {
+	for _, variant := range models.ImageVariants {
+		stream, err := s.blobRepo.OpenBlob(ctx, ImageVariantBlobName(userID, fileName, variant.Size))
+		if err != nil {
+			return false
+		}
+		stream.Content.Close()
+	}
+	return true
+}
+
+// GenerateVariants builds and stores the resized variants of an image that is already uploaded
+func (s *BlobService) GenerateVariants(ctx context.Context, userID, fileName string) ([]models.ImageSize, error) {
+	stream, err := s.blobRepo.OpenBlob(ctx, fmt.Sprintf("%s/%s", userID, fileName))
 	if err != nil {
-		return nil, "", err
+		return nil, fmt.Errorf("BlobService.GenerateVariants: Failed to open %s/%s: %w", userID, fileName, err)
 	}
-	return data, contentType, nil
+	defer stream.Content.Close()
+	// Unsanitized originals still rely on their EXIF orientation, which the variants don't carry
+	upright, format, err := imaging.DecodeUpright(stream.Content)
+	if err != nil {
+		return nil, fmt.Errorf("BlobService.GenerateVariants: %w", err)
+	}
+	return s.storeVariants(ctx, userID, fileName, upright, format)
 }
 
+// storeVariants resizes an image to each variant size and uploads the results next to the original
+func (s *BlobService) storeVariants(ctx context.Context, userID, fileName string, rgba *image.RGBA, format string) ([]models.ImageSize, error) {
+	stored := []models.ImageSize{}
+	for _, variant := range models.ImageVariants {
+		resized, contentType, err := imaging.Encode(imaging.Fit(rgba, variant.MaxDimension), format)
+		if err != nil {
+			return stored, err
+		}
+		metadata := map[string]string{"id": userID, "variant": string(variant.Size)}
+		if _, err := s.blobRepo.UploadBlob(ctx, ImageVariantBlobName(userID, fileName, variant.Size), contentType, resized, metadata); err != nil {
+			return stored, err
+		}
+		stored = append(stored, variant.Size)
+	}
+	return stored, nil
+}
+
+// GetAllImages lists every user's image blob names, for maintenance commands
 func (s *BlobService) GetAllImages(ctx context.Context) ([]string, error) {
-	imageData, err := s.blobRepo.GetAllImages(ctx)
+	imageData, err := s.blobRepo.GetAllImages(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 	return imageData, nil
 }
 
-func (s *BlobService) DeleteImage(ctx context.Context, userID, fileName string) error {
-	err := s.blobRepo.DeleteImage(ctx, userID, fileName)
+// GetVisibleImages lists the image blob names a user may see, their own uploads or every user's for admins
+func (s *BlobService) GetVisibleImages(ctx context.Context, actorID string) ([]string, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("BlobService: Failed to load acting user %s: %w", actorID, err)
+	}
+	prefix := actorID + "/"
+	if actor.Role == models.RoleAdmin {
+		prefix = ""
+	}
+	return s.blobRepo.GetAllImages(ctx, prefix)
+}
+
+// DeleteImage removes an image owned by ownerID; staff may remove other users' photos in classrooms they manage
+func (s *BlobService) DeleteImage(ctx context.Context, actorID, ownerID, fileName string) error {
+	if err := s.authorizeImage(ctx, actorID, ownerID, fileName); err != nil {
+		return err
+	}
+
+	err := s.blobRepo.DeleteImage(ctx, ownerID, fileName)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// authorizeImage allows owners to use their own images and staff to use photos shared with classrooms they manage
+func (s *BlobService) authorizeImage(ctx context.Context, actorID, ownerID, fileName string) error {
+	if actorID == ownerID {
+		return nil
+	}
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return fmt.Errorf("BlobService: Failed to load acting user %s: %w", actorID, err)
+	}
+	metadata, err := s.blobRepo.GetImageMetadata(ctx, ownerID, fileName)
+	if err != nil {
+		return err
+	}
+	classroomID := metadata["classroom"]
+	if classroomID == "" && actor.Role.IsClassroomScoped() {
+		return fmt.Errorf("%w: %s may only access classroom photos", ErrForbidden, actor.Role)
+	}
+	return authorizeClassroom(actor, models.PermissionManagePhotos, classroomID)
+}
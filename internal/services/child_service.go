@@ -0,0 +1,317 @@
+package services
+
+import (
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"time"
+)
+
+const CHILDRENTABLE = "ChildrenTable"
+const GUARDIANSTABLE = "GuardiansTable"
+
+// ChildRepo interface methods implemented in repositories package
+type ChildRepo interface {
+	GetChild(tableName string, id string) (models.Child, error)
+	GetAllChildren(tableName string) ([]models.Child, error)
+	GetChildrenByClassroom(tableName string, classroomID string) ([]models.Child, error)
+	CreateChild(tableName string, child models.Child) error
+	UpdateChild(tableName string, child models.Child) (models.Child, error)
+	DeleteChild(tableName string, id string) error
+	UpsertGuardian(tableName string, link models.GuardianLink) error
+	RemoveGuardian(tableName string, childID string, userID string) error
+	GetGuardianLinks(tableName string, filter string) ([]models.GuardianLink, error)
+}
+
+// ChildService handles child records and their guardian links, restricting access to guardians and staff
+type ChildService struct {
+	repo     ChildRepo
+	userRepo UserRepo
+}
+
+// NewChildService constructs and returns a ChildService object
+func NewChildService(r ChildRepo, u UserRepo) *ChildService {
+	return &ChildService{repo: r, userRepo: u}
+}
+
+// GetChildByID returns a child with guardians if the acting user is one of its guardians or staff covering its classroom
+func (s *ChildService) GetChildByID(actorID string, id string) (models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	child, err := s.getChild(id)
+	if err != nil {
+		return models.Child{}, err
+	}
+	if err := authorizeChildAccess(actor, child); err != nil {
+		return models.Child{}, err
+	}
+	return child, nil
+}
+
+// GetChildren returns the children visible to the acting user: all for directors and admins,
+// assigned classrooms for teachers and their own children for guardians
+func (s *ChildService) GetChildren(actorID string) ([]models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+	children, err := s.repo.GetAllChildren(CHILDRENTABLE)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.repo.GetGuardianLinks(GUARDIANSTABLE, "")
+	if err != nil {
+		return nil, err
+	}
+	linksByChild := make(map[string][]models.GuardianLink)
+	for _, link := range links {
+		linksByChild[link.ChildID] = append(linksByChild[link.ChildID], link)
+	}
+
+	visible := []models.Child{}
+	for _, child := range children {
+		child.Guardians = linksByChild[child.ID]
+		if authorizeChildAccess(actor, child) == nil {
+			visible = append(visible, child)
+		}
+	}
+	return visible, nil
+}
+
+// GetChildrenByGuardian returns the children linked to a user along with that user's relationship to each
+func (s *ChildService) GetChildrenByGuardian(userID string) ([]models.Child, error) {
+	links, err := s.repo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("RowKey eq '%s'", userID))
+	if err != nil {
+		return nil, err
+	}
+	children := make([]models.Child, 0, len(links))
+	for _, link := range links {
+		child, err := s.repo.GetChild(CHILDRENTABLE, link.ChildID)
+		if err != nil {
+			// Link left behind by a deleted child
+			continue
+		}
+		child.Guardians = []models.GuardianLink{link}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// GetChildrenByGuardians returns linked children for every guardian, keyed by user ID
+func (s *ChildService) GetChildrenByGuardians() (map[string][]models.Child, error) {
+	children, err := s.repo.GetAllChildren(CHILDRENTABLE)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.repo.GetGuardianLinks(GUARDIANSTABLE, "")
+	if err != nil {
+		return nil, err
+	}
+	childrenByID := make(map[string]models.Child, len(children))
+	for _, child := range children {
+		childrenByID[child.ID] = child
+	}
+
+	byGuardian := make(map[string][]models.Child)
+	for _, link := range links {
+		child, ok := childrenByID[link.ChildID]
+		if !ok {
+			continue
+		}
+		child.Guardians = []models.GuardianLink{link}
+		byGuardian[link.UserID] = append(byGuardian[link.UserID], child)
+	}
+	return byGuardian, nil
+}
+
+// VisibleLinkedChildren narrows each guardian's linked children to the ones the acting user may see. Users see all
+// of their own, anyone else's are checked child by child so teachers only see children in their assigned classrooms
+func (s *ChildService) VisibleLinkedChildren(actorID string, byGuardian map[string][]models.Child) (map[string][]models.Child, error) {
+	visible := make(map[string][]models.Child, len(byGuardian))
+	if own, ok := byGuardian[actorID]; ok {
+		visible[actorID] = own
+	}
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return visible, err
+	}
+	for guardianID, children := range byGuardian {
+		if guardianID == actorID {
+			continue
+		}
+		for _, child := range children {
+			if authorizeChildAccess(actor, child) == nil {
+				visible[guardianID] = append(visible[guardianID], child)
+			}
+		}
+	}
+	return visible, nil
+}
+
+// CreateChild stores a new child record with its initial guardians, staff only
+func (s *ChildService) CreateChild(actorID string, child models.Child) (models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, child.Classroom); err != nil {
+		return models.Child{}, err
+	}
+	if child.MediaConsent != models.MediaConsentNone {
+		if err := authorizeMediaConsent(actor, child); err != nil {
+			return models.Child{}, err
+		}
+		child.MediaConsentBy = actorID
+		child.MediaConsentAt = time.Now().UTC()
+	}
+	for _, link := range child.Guardians {
+		if _, err := s.userRepo.GetUser(USERSTABLE, link.UserID); err != nil {
+			return models.Child{}, fmt.Errorf("ChildService.CreateChild: Guardian %s not found: %w", link.UserID, err)
+		}
+	}
+
+	if err := s.repo.CreateChild(CHILDRENTABLE, child); err != nil {
+		return models.Child{}, err
+	}
+	for i := range child.Guardians {
+		child.Guardians[i].ChildID = child.ID
+		if err := s.repo.UpsertGuardian(GUARDIANSTABLE, child.Guardians[i]); err != nil {
+			return models.Child{}, err
+		}
+	}
+	return child, nil
+}
+
+// LinkInvitedGuardian links a newly signed up parent to the children named on their invite, skipping children since removed
+func (s *ChildService) LinkInvitedGuardian(userID string, childIDs []string) error {
+	for _, childID := range childIDs {
+		if _, err := s.repo.GetChild(CHILDRENTABLE, childID); err != nil {
+			continue
+		}
+		link := models.GuardianLink{ChildID: childID, UserID: userID, Relationship: models.RelationshipParent}
+		if err := s.repo.UpsertGuardian(GUARDIANSTABLE, link); err != nil {
+			return fmt.Errorf("ChildService.LinkInvitedGuardian: %w", err)
+		}
+	}
+	return nil
+}
+
+// UpdateChild applies partial updates, guardians and staff covering the child's classroom may edit
+func (s *ChildService) UpdateChild(actorID string, newData models.Child) (models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	child, err := s.getChild(newData.ID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	if err := authorizeChildAccess(actor, child); err != nil {
+		return models.Child{}, err
+	}
+	// Moving a child between classrooms is a staff decision
+	if newData.Classroom != "" && newData.Classroom != child.Classroom {
+		if err := authorizeClassroom(actor, models.PermissionManageChildren, newData.Classroom); err != nil {
+			return models.Child{}, err
+		}
+	}
+	// Consent is the family's choice, staff covering the classroom may not change it
+	if newData.MediaConsent != "" && newData.MediaConsent != child.MediaConsent {
+		if err := authorizeMediaConsent(actor, child); err != nil {
+			return models.Child{}, err
+		}
+		newData.MediaConsentBy = actorID
+		newData.MediaConsentAt = time.Now().UTC()
+	} else {
+		newData.MediaConsent = ""
+	}
+
+	updated, err := s.repo.UpdateChild(CHILDRENTABLE, newData)
+	if err != nil {
+		return models.Child{}, err
+	}
+	updated.Guardians = child.Guardians
+	return updated, nil
+}
+
+// DeleteChild removes a child and its guardian links, directors and admins only
+func (s *ChildService) DeleteChild(actorID string, id string) error {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.Role.Can(models.PermissionManageChildren) || actor.Role.IsClassroomScoped() {
+		return fmt.Errorf("%w: %s may not delete child records", ErrForbidden, actor.Role)
+	}
+	child, err := s.getChild(id)
+	if err != nil {
+		return err
+	}
+	for _, link := range child.Guardians {
+		if err := s.repo.RemoveGuardian(GUARDIANSTABLE, id, link.UserID); err != nil {
+			return err
+		}
+	}
+	return s.repo.DeleteChild(CHILDRENTABLE, id)
+}
+
+// AddGuardian links a user to a child, staff only
+func (s *ChildService) AddGuardian(actorID string, link models.GuardianLink) (models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	child, err := s.getChild(link.ChildID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, child.Classroom); err != nil {
+		return models.Child{}, err
+	}
+	if _, err := s.userRepo.GetUser(USERSTABLE, link.UserID); err != nil {
+		return models.Child{}, fmt.Errorf("ChildService.AddGuardian: Guardian %s not found: %w", link.UserID, err)
+	}
+	if err := s.repo.UpsertGuardian(GUARDIANSTABLE, link); err != nil {
+		return models.Child{}, err
+	}
+	return s.getChild(link.ChildID)
+}
+
+// RemoveGuardian unlinks a user from a child, staff only
+func (s *ChildService) RemoveGuardian(actorID string, childID string, userID string) error {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return err
+	}
+	child, err := s.getChild(childID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, child.Classroom); err != nil {
+		return err
+	}
+	return s.repo.RemoveGuardian(GUARDIANSTABLE, childID, userID)
+}
+
+// getChild loads a child with all of its guardian links
+func (s *ChildService) getChild(id string) (models.Child, error) {
+	child, err := s.repo.GetChild(CHILDRENTABLE, id)
+	if err != nil {
+		return models.Child{}, err
+	}
+	links, err := s.repo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", id))
+	if err != nil {
+		return models.Child{}, err
+	}
+	child.Guardians = links
+	return child, nil
+}
+
+func (s *ChildService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("ChildService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+)
+
+const CLASSROOMSTABLE = "ClassroomsTable"
+
+// ClassroomRepo interface methods implemented in repositories package
+type ClassroomRepo interface {
+	GetClassroom(tableName string, id string) (models.Classroom, error)
+	GetAllClassrooms(tableName string) ([]models.Classroom, error)
+	CreateClassroom(tableName string, classroom models.Classroom) error
+	UpdateClassroom(tableName string, classroom models.Classroom) (models.Classroom, error)
+	DeleteClassroom(tableName string, id string) error
+}
+
+// ClassroomService manages classrooms, their assigned teachers and enrolled children
+type ClassroomService struct {
+	repo      ClassroomRepo
+	userRepo  UserRepo
+	childRepo ChildRepo
+}
+
+// NewClassroomService constructs and returns a ClassroomService object
+func NewClassroomService(r ClassroomRepo, u UserRepo, c ChildRepo) *ClassroomService {
+	return &ClassroomService{repo: r, userRepo: u, childRepo: c}
+}
+
+// GetClassroomByID returns a single classroom
+func (s *ClassroomService) GetClassroomByID(id string) (models.Classroom, error) {
+	return s.repo.GetClassroom(CLASSROOMSTABLE, id)
+}
+
+// GetAllClassrooms returns every classroom
+func (s *ClassroomService) GetAllClassrooms() ([]models.Classroom, error) {
+	return s.repo.GetAllClassrooms(CLASSROOMSTABLE)
+}
+
+// CreateClassroom stores a new classroom, directors and admins only
+func (s *ClassroomService) CreateClassroom(actorID string, classroom models.Classroom) (models.Classroom, error) {
+	if err := s.authorizeManagement(actorID); err != nil {
+		return models.Classroom{}, err
+	}
+	if err := s.repo.CreateClassroom(CLASSROOMSTABLE, classroom); err != nil {
+		return models.Classroom{}, err
+	}
+	return classroom, nil
+}
+
+// UpdateClassroom applies partial updates to a classroom, directors and admins only
+func (s *ClassroomService) UpdateClassroom(actorID string, newData models.Classroom) (models.Classroom, error) {
+	if err := s.authorizeManagement(actorID); err != nil {
+		return models.Classroom{}, err
+	}
+	return s.repo.UpdateClassroom(CLASSROOMSTABLE, newData)
+}
+
+// DeleteClassroom removes a classroom once no children are enrolled in it, directors and admins only
+func (s *ClassroomService) DeleteClassroom(actorID string, id string) error {
+	if err := s.authorizeManagement(actorID); err != nil {
+		return err
+	}
+	children, err := s.childRepo.GetChildrenByClassroom(CHILDRENTABLE, id)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return fmt.Errorf("ClassroomService.DeleteClassroom: Classroom %s still has %d enrolled children", id, len(children))
+	}
+
+	teachers, err := s.getTeachers(id)
+	if err != nil {
+		return err
+	}
+	for _, teacher := range teachers {
+		if err := s.userRepo.UpdateRole(USERSTABLE, teacher.ID, teacher.Role, withoutClassroom(teacher.Classrooms, id)); err != nil {
+			return err
+		}
+	}
+	return s.repo.DeleteClassroom(CLASSROOMSTABLE, id)
+}
+
+// GetRoster returns a classroom with its teachers and enrolled children, staff covering the classroom only
+func (s *ClassroomService) GetRoster(actorID string, id string) (models.ClassroomRoster, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.ClassroomRoster{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, id); err != nil {
+		return models.ClassroomRoster{}, err
+	}
+	classroom, err := s.repo.GetClassroom(CLASSROOMSTABLE, id)
+	if err != nil {
+		return models.ClassroomRoster{}, err
+	}
+	teachers, err := s.getTeachers(id)
+	if err != nil {
+		return models.ClassroomRoster{}, err
+	}
+	children, err := s.childRepo.GetChildrenByClassroom(CHILDRENTABLE, id)
+	if err != nil {
+		return models.ClassroomRoster{}, err
+	}
+	return models.ClassroomRoster{Classroom: classroom, Teachers: teachers, Children: children}, nil
+}
+
+// AssignTeacher adds a classroom to a teacher's assignments, directors and admins only
+func (s *ClassroomService) AssignTeacher(actorID string, classroomID string, teacherID string) error {
+	if err := s.authorizeManagement(actorID); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetClassroom(CLASSROOMSTABLE, classroomID); err != nil {
+		return err
+	}
+	teacher, err := s.userRepo.GetUser(USERSTABLE, teacherID)
+	if err != nil {
+		return err
+	}
+	if teacher.Role != models.RoleTeacher {
+		return fmt.Errorf("ClassroomService.AssignTeacher: User %s has role %s, only teachers are assigned to classrooms", teacherID, teacher.Role)
+	}
+	if teacher.HasClassroom(classroomID) {
+		return nil
+	}
+	return s.userRepo.UpdateRole(USERSTABLE, teacher.ID, teacher.Role, append(teacher.Classrooms, classroomID))
+}
+
+// UnassignTeacher removes a classroom from a teacher's assignments, directors and admins only
+func (s *ClassroomService) UnassignTeacher(actorID string, classroomID string, teacherID string) error {
+	if err := s.authorizeManagement(actorID); err != nil {
+		return err
+	}
+	teacher, err := s.userRepo.GetUser(USERSTABLE, teacherID)
+	if err != nil {
+		return err
+	}
+	if !teacher.HasClassroom(classroomID) {
+		return nil
+	}
+	return s.userRepo.UpdateRole(USERSTABLE, teacher.ID, teacher.Role, withoutClassroom(teacher.Classrooms, classroomID))
+}
+
+// GuardianIDsForClassroom returns the user IDs of every guardian of a child enrolled in the classroom
+func (s *ClassroomService) GuardianIDsForClassroom(classroomID string) ([]string, error) {
+	children, err := s.childRepo.GetChildrenByClassroom(CHILDRENTABLE, classroomID)
+	if err != nil {
+		return nil, err
+	}
+	guardianIDs := []string{}
+	seen := make(map[string]bool)
+	for _, child := range children {
+		links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", child.ID))
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			if !seen[link.UserID] {
+				seen[link.UserID] = true
+				guardianIDs = append(guardianIDs, link.UserID)
+			}
+		}
+	}
+	return guardianIDs, nil
+}
+
+// getTeachers returns the teachers assigned to a classroom
+func (s *ClassroomService) getTeachers(classroomID string) ([]models.User, error) {
+	users, err := s.userRepo.GetAllUsers(USERSTABLE)
+	if err != nil {
+		return nil, err
+	}
+	teachers := []models.User{}
+	for _, user := range users {
+		if user.Role == models.RoleTeacher && user.HasClassroom(classroomID) {
+			teachers = append(teachers, user)
+		}
+	}
+	return teachers, nil
+}
+
+func (s *ClassroomService) authorizeManagement(actorID string) error {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.Role.Can(models.PermissionManageClassrooms) {
+		return fmt.Errorf("%w: %s may not %s", ErrForbidden, actor.Role, models.PermissionManageClassrooms)
+	}
+	return nil
+}
+
+func (s *ClassroomService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("ClassroomService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
+
+// Helper - copy of a classroom list with one classroom removed
+func withoutClassroom(classrooms []string, classroomID string) []string {
+	remaining := []string{}
+	for _, id := range classrooms {
+		if id != classroomID {
+			remaining = append(remaining, id)
+		}
+	}
+	return remaining
+}
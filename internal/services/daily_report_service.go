@@ -0,0 +1,265 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"strings"
+	"time"
+)
+
+const DAILYENTRIESTABLE = "DailyEntriesTable"
+
+// DailyEntryRepo interface methods implemented in repositories package
+type DailyEntryRepo interface {
+	CreateEntry(tableName string, entry models.DailyEntry) error
+	GetEntries(tableName string, date string, filter string) ([]models.DailyEntry, error)
+	DeleteEntry(tableName string, date string, id string) error
+}
+
+// DailyReportService lets teachers log a child's day and shares it with the child's guardians
+type DailyReportService struct {
+	repo         DailyEntryRepo
+	childRepo    ChildRepo
+	userRepo     UserRepo
+	blobRepo     BlobRepo
+	emailService EmailService
+}
+
+// NewDailyReportService constructs and returns a DailyReportService object
+func NewDailyReportService(r DailyEntryRepo, c ChildRepo, u UserRepo, b BlobRepo, e EmailService) *DailyReportService {
+	return &DailyReportService{repo: r, childRepo: c, userRepo: u, blobRepo: b, emailService: e}
+}
+
+// AddEntry logs an entry for a child, staff covering the child's classroom only.
+// Photo entries must reference an image the acting teacher has already uploaded
+func (s *DailyReportService) AddEntry(ctx context.Context, actorID string, entry models.DailyEntry) (models.DailyEntry, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.DailyEntry{}, err
+	}
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, entry.ChildID)
+	if err != nil {
+		return models.DailyEntry{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, child.Classroom); err != nil {
+		return models.DailyEntry{}, err
+	}
+	if err := entry.Validate(); err != nil {
+		return models.DailyEntry{}, err
+	}
+	if entry.Type == models.DailyEntryPhoto {
+		if entry.ImageOwner != actorID {
+			return models.DailyEntry{}, fmt.Errorf("%w: photos must be your own uploads", ErrForbidden)
+		}
+		if _, err := s.blobRepo.GetImageMetadata(ctx, entry.ImageOwner, entry.ImageName); err != nil {
+			return models.DailyEntry{}, fmt.Errorf("DailyReportService.AddEntry: Photo %s/%s not found: %w", entry.ImageOwner, entry.ImageName, err)
+		}
+	}
+
+	entry.Date = entry.StartAt.In(models.CenterLocation()).Format(models.AttendanceDateFormat)
+	entry.StaffID = actorID
+	if err := s.repo.CreateEntry(DAILYENTRIESTABLE, entry); err != nil {
+		return models.DailyEntry{}, err
+	}
+	return entry, nil
+}
+
+// DeleteEntry removes a mistaken entry, staff covering the child's classroom only
+func (s *DailyReportService) DeleteEntry(actorID string, childID string, date string, entryID string) error {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return err
+	}
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, child.Classroom); err != nil {
+		return err
+	}
+	if _, err := s.findEntry(childID, date, entryID); err != nil {
+		return err
+	}
+	return s.repo.DeleteEntry(DAILYENTRIESTABLE, date, entryID)
+}
+
+// GetDailyReport returns a child's report for a date, defaulting to today, to its guardians and staff covering its classroom
+func (s *DailyReportService) GetDailyReport(actorID string, childID string, date string) (models.DailyReport, error) {
+	child, err := s.authorizeViewer(actorID, childID)
+	if err != nil {
+		return models.DailyReport{}, err
+	}
+	if date == "" {
+		date = models.CenterNow().Format(models.AttendanceDateFormat)
+	}
+	if _, err := time.Parse(models.AttendanceDateFormat, date); err != nil {
+		return models.DailyReport{}, errors.New("date must be in YYYY-MM-DD format")
+	}
+	entries, err := s.repo.GetEntries(DAILYENTRIESTABLE, date, fmt.Sprintf("ChildID eq '%s'", childID))
+	if err != nil {
+		return models.DailyReport{}, err
+	}
+	return models.NewDailyReport(child, date, entries), nil
+}
+
+// GetEntryPhoto opens the image behind a photo entry to anyone allowed to read the child's report
+func (s *DailyReportService) GetEntryPhoto(ctx context.Context, actorID string, childID string, date string, entryID string) (*models.ImageStream, error) {
+	if _, err := s.authorizeViewer(actorID, childID); err != nil {
+		return nil, err
+	}
+	entry, err := s.findEntry(childID, date, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Type != models.DailyEntryPhoto {
+		return nil, fmt.Errorf("DailyReportService.GetEntryPhoto: Entry %s is not a photo", entryID)
+	}
+	return s.blobRepo.OpenBlob(ctx, fmt.Sprintf("%s/%s", entry.ImageOwner, entry.ImageName))
+}
+
+// SendDailyDigests emails each guardian the report of every child with entries on the date and returns how many emails were sent
+func (s *DailyReportService) SendDailyDigests(date string) (int, error) {
+	entries, err := s.repo.GetEntries(DAILYENTRIESTABLE, date, "")
+	if err != nil {
+		return 0, err
+	}
+	entriesByChild := make(map[string][]models.DailyEntry)
+	for _, entry := range entries {
+		entriesByChild[entry.ChildID] = append(entriesByChild[entry.ChildID], entry)
+	}
+
+	sent := 0
+	for childID, childEntries := range entriesByChild {
+		child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+		if err != nil {
+			log.Printf("DailyReportService.SendDailyDigests: Skipping child %s: %v", childID, err)
+			continue
+		}
+		report := models.NewDailyReport(child, date, childEntries)
+		htmlContent, err := renderDailyDigest(report)
+		if err != nil {
+			return sent, err
+		}
+		subject := fmt.Sprintf("%s's day at Little Einstein", child.Name)
+		plainTextContent := fmt.Sprintf("%s's daily report for %s is ready in the Little Einstein app.", child.Name, date)
+
+		links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", childID))
+		if err != nil {
+			return sent, err
+		}
+		for _, link := range links {
+			guardian, err := s.userRepo.GetUser(USERSTABLE, link.UserID)
+			if err != nil || guardian.Email == "" {
+				continue
+			}
+			if err := s.emailService.SendEmail(guardian.Email, subject, plainTextContent, htmlContent); err != nil {
+				log.Printf("DailyReportService.SendDailyDigests: Failed to email %s: %v", guardian.Email, err)
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// StartDailyDigest sends the digest every day at the given time after midnight in the center's time zone until ctx is cancelled
+func (s *DailyReportService) StartDailyDigest(ctx context.Context, at time.Duration) {
+	go func() {
+		for {
+			now := models.CenterNow()
+			midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			next := models.ClockTime(midnight, at)
+			if !next.After(now) {
+				next = models.ClockTime(midnight.AddDate(0, 0, 1), at)
+			}
+
+			select {
+			case <-ctx.Done():
+				log.Println("Daily digest stopped")
+				return
+			case <-time.After(time.Until(next)):
+				date := next.Format(models.AttendanceDateFormat)
+				sent, err := s.SendDailyDigests(date)
+				if err != nil {
+					log.Printf("Daily digest for %s failed after %d emails: %v", date, sent, err)
+					continue
+				}
+				log.Printf("Daily digest for %s sent %d emails", date, sent)
+			}
+		}
+	}()
+	log.Printf("Daily digest scheduled at %v after midnight", at)
+}
+
+// findEntry returns one of a child's entries on a date
+func (s *DailyReportService) findEntry(childID string, date string, entryID string) (models.DailyEntry, error) {
+	entries, err := s.repo.GetEntries(DAILYENTRIESTABLE, date, fmt.Sprintf("ChildID eq '%s'", childID))
+	if err != nil {
+		return models.DailyEntry{}, err
+	}
+	for _, entry := range entries {
+		if entry.ID == entryID {
+			return entry, nil
+		}
+	}
+	return models.DailyEntry{}, fmt.Errorf("DailyReportService: Entry %s not found for child %s on %s", entryID, childID, date)
+}
+
+// authorizeViewer loads a child with its guardians and allows those guardians and staff covering its classroom
+func (s *DailyReportService) authorizeViewer(actorID string, childID string) (models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", childID))
+	if err != nil {
+		return models.Child{}, err
+	}
+	child.Guardians = links
+	if err := authorizeChildAccess(actor, child); err != nil {
+		return models.Child{}, err
+	}
+	return child, nil
+}
+
+func (s *DailyReportService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("DailyReportService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
+
+var dailyDigestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"clock": func(t time.Time) string { return t.In(models.CenterLocation()).Format(time.Kitchen) },
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+}).Parse(`<h2>{{.ChildName}}'s day, {{.Date}}</h2>
+{{if .Meals}}<h3>Meals</h3><ul>{{range .Meals}}<li>{{clock .StartAt}} {{title .Meal}}{{if .Amount}}: ate {{.Amount}}{{end}}{{if .Notes}} ({{.Notes}}){{end}}</li>{{end}}</ul>{{end}}
+{{if .Naps}}<h3>Naps</h3><ul>{{range .Naps}}<li>{{clock .StartAt}}{{if not .EndAt.IsZero}} to {{clock .EndAt}}{{end}}{{if .Notes}} ({{.Notes}}){{end}}</li>{{end}}</ul><p>Total nap time: {{.TotalNapMinutes}} minutes</p>{{end}}
+{{if .Toileting}}<h3>Diapers and toileting</h3><ul>{{range .Toileting}}<li>{{clock .StartAt}} {{.Kind}}{{if .Notes}} ({{.Notes}}){{end}}</li>{{end}}</ul>{{end}}
+{{if .Moods}}<h3>Mood</h3><ul>{{range .Moods}}<li>{{clock .StartAt}} {{.Mood}}{{if .Notes}} ({{.Notes}}){{end}}</li>{{end}}</ul>{{end}}
+{{if .Activities}}<h3>Activities</h3><ul>{{range .Activities}}<li>{{clock .StartAt}} {{.Notes}}</li>{{end}}</ul>{{end}}
+{{if .Photos}}<p>{{len .Photos}} new photo(s) are waiting in the app.</p>{{end}}`))
+
+// Helper - render a daily report as the HTML body of the digest email
+func renderDailyDigest(report models.DailyReport) (string, error) {
+	var buffer bytes.Buffer
+	if err := dailyDigestTemplate.Execute(&buffer, report); err != nil {
+		return "", fmt.Errorf("DailyReportService: Failed to render digest: %w", err)
+	}
+	return buffer.String(), nil
+}
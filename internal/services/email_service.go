@@ -9,6 +9,7 @@ import (
 
 type EmailService interface {
 	SendInviteEmail(to string) error
+	SendEmail(to string, subject string, plainTextContent string, htmlContent string) error
 }
 
 type SendGridService struct {
@@ -22,12 +23,18 @@ func NewSendGridService(fromName, fromEmail, apiKey string) *SendGridService {
 }
 
 func (s *SendGridService) SendInviteEmail(to string) error {
-	from := mail.NewEmail(s.FromName, s.FromEmail)
-	recipient := mail.NewEmail("", to)
 	subject := "You're Invited!"
 	plainTextContent := "Your account is ready. Please click Sign in button and sign up with this email:"
 	htmlContent := `<p>Your account is ready. <a href="https://littleeinsteinchildcare.org/signup">Click to sign up</a></p>`
 
+	return s.SendEmail(to, subject, plainTextContent, htmlContent)
+}
+
+// SendEmail sends a single message from the center's address
+func (s *SendGridService) SendEmail(to string, subject string, plainTextContent string, htmlContent string) error {
+	from := mail.NewEmail(s.FromName, s.FromEmail)
+	recipient := mail.NewEmail("", to)
+
 	message := mail.NewSingleEmail(from, subject, recipient, plainTextContent, htmlContent)
 	client := sendgrid.NewSendClient(s.APIKey)
 
@@ -19,15 +19,21 @@ type EventRepo interface {
 	UpdateEvent(tableNAme string, model models.Event) (models.Event, error)
 }
 
+// ClassroomGuardians resolves the guardians of the children enrolled in a classroom
+type ClassroomGuardians interface {
+	GuardianIDsForClassroom(classroomID string) ([]string, error)
+}
+
 // EventService contains and handles a specific EventRepository object
 type EventService struct {
 	repo        EventRepo
 	userService UserService
+	classrooms  ClassroomGuardians
 }
 
 // NewEventService constructs and returns a EventService object
-func NewEventService(r EventRepo, us UserService) *EventService {
-	return &EventService{repo: r, userService: us}
+func NewEventService(r EventRepo, us UserService, c ClassroomGuardians) *EventService {
+	return &EventService{repo: r, userService: us, classrooms: c}
 }
 
 // GetEventByID handles calling the EventRepository GetEvent function and returns the result of a query by the EventRepository
@@ -37,6 +43,9 @@ func (s *EventService) GetEventByID(id string) (models.Event, error) {
 	if err != nil {
 		return models.Event{}, err
 	}
+	if err := s.expandClassroomInvitees(&event, s.userService.GetUserByID, s.classrooms.GuardianIDsForClassroom); err != nil {
+		return models.Event{}, err
+	}
 	return event, nil
 }
 
@@ -60,6 +69,20 @@ func (s *EventService) GetAllEvents() ([]models.Event, error) {
 		return u, nil
 	}
 
+	// Classroom rosters are resolved once per request rather than once per event inviting the classroom
+	cachedGuardians := make(map[string][]string)
+	getGuardians := func(classroomID string) ([]string, error) {
+		if ids, ok := cachedGuardians[classroomID]; ok {
+			return ids, nil
+		}
+		ids, err := s.classrooms.GuardianIDsForClassroom(classroomID)
+		if err != nil {
+			return nil, err
+		}
+		cachedGuardians[classroomID] = ids
+		return ids, nil
+	}
+
 	events := make([]models.Event, 0, len(eventRows))
 
 	// For each row returned by repo call, get full Event data (Creator as User and Invitees as User slice)
@@ -88,8 +111,7 @@ func (s *EventService) GetAllEvents() ([]models.Event, error) {
 			invitees = []models.User{} // Empty array for no invitees
 		}
 
-		// Build list of all events in table
-		events = append(events, models.Event{
+		event := models.Event{
 			ID:          r.RowKey,
 			EventName:   r.EventName,
 			Date:        r.Date,
@@ -98,9 +120,21 @@ func (s *EventService) GetAllEvents() ([]models.Event, error) {
 			Location:    r.Location,
 			Description: r.Description,
 			Color:       r.Color,
+			Classroom:   r.Classroom,
 			Creator:     creator,
 			Invitees:    invitees,
-		})
+		}
+		for _, id := range strings.Split(r.InvitedClassroomIDs, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				event.InvitedClassrooms = append(event.InvitedClassrooms, id)
+			}
+		}
+		if err := s.expandClassroomInvitees(&event, getUser, getGuardians); err != nil {
+			return nil, err
+		}
+
+		// Build list of all events in table
+		events = append(events, event)
 	}
 
 	return events, nil
@@ -133,29 +167,106 @@ func (s *EventService) GetEventsByUser(userId string) ([]models.Event, error) {
 	return userEvents, nil
 }
 
-// CreateEvent returns an error on a failed EventRepo call
-func (s *EventService) CreateEvent(event models.Event) error {
-	err := s.repo.CreateEvent(EVENTSTABLE, event)
+// CreateEvent checks the acting user may create the event and returns an error on a failed EventRepo call
+func (s *EventService) CreateEvent(actorID string, event models.Event) error {
+	actor, err := s.userService.GetUserByID(actorID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeEventManagement(actor, event); err != nil {
+		return err
+	}
+	if err := authorizeClassroomInvites(actor, event.InvitedClassrooms); err != nil {
+		return err
+	}
+
+	err = s.repo.CreateEvent(EVENTSTABLE, event)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// Update Event and handle errors from Event Repo
-func (s *EventService) UpdateEvent(event models.Event) (models.Event, error) {
-	event, err := s.repo.UpdateEvent(EVENTSTABLE, event)
+// Update Event and handle errors from Event Repo, the acting user must be allowed to manage both the current and the updated event
+func (s *EventService) UpdateEvent(actorID string, event models.Event) (models.Event, error) {
+	actor, err := s.userService.GetUserByID(actorID)
+	if err != nil {
+		return models.Event{}, err
+	}
+	existing, err := s.repo.GetEvent(EVENTSTABLE, event.ID)
+	if err != nil {
+		return models.Event{}, err
+	}
+	if err := authorizeEventManagement(actor, existing); err != nil {
+		return models.Event{}, err
+	}
+	if event.Classroom != "" && event.Classroom != existing.Classroom {
+		if err := authorizeClassroom(actor, models.PermissionManageEvents, event.Classroom); err != nil {
+			return models.Event{}, err
+		}
+	}
+	if err := authorizeClassroomInvites(actor, event.InvitedClassrooms); err != nil {
+		return models.Event{}, err
+	}
+
+	event, err = s.repo.UpdateEvent(EVENTSTABLE, event)
 	if err != nil {
 		return event, err
 	}
+	if err := s.expandClassroomInvitees(&event, s.userService.GetUserByID, s.classrooms.GuardianIDsForClassroom); err != nil {
+		return models.Event{}, err
+	}
 	return event, nil
 }
 
 // Remove an Event and handle errors from Event Repo
-func (s *EventService) DeleteEventByID(id string) error {
-	err := s.repo.DeleteEvent(EVENTSTABLE, id)
+func (s *EventService) DeleteEventByID(actorID string, id string) error {
+	actor, err := s.userService.GetUserByID(actorID)
+	if err != nil {
+		return err
+	}
+	existing, err := s.repo.GetEvent(EVENTSTABLE, id)
 	if err != nil {
 		return err
 	}
+	if err := authorizeEventManagement(actor, existing); err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteEvent(EVENTSTABLE, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// expandClassroomInvitees adds the guardians of every invited classroom to the event's invitees.
+// Guardians are resolved on each read so roster changes are reflected without rewriting the event
+func (s *EventService) expandClassroomInvitees(event *models.Event, getUser func(id string) (models.User, error), getGuardians func(classroomID string) ([]string, error)) error {
+	if len(event.InvitedClassrooms) == 0 {
+		return nil
+	}
+	invited := make(map[string]bool, len(event.Invitees))
+	for _, invitee := range event.Invitees {
+		invited[invitee.ID] = true
+	}
+	for _, classroomID := range event.InvitedClassrooms {
+		guardianIDs, err := getGuardians(classroomID)
+		if err != nil {
+			return fmt.Errorf("Failed to get guardians for classroom %s: %w", classroomID, err)
+		}
+		for _, id := range guardianIDs {
+			if invited[id] {
+				continue
+			}
+			u, err := getUser(id)
+			if err != nil {
+				// Guardian link left behind by a deleted account
+				continue
+			}
+			invited[id] = true
+			event.Invitees = append(event.Invitees, u)
+		}
+	}
 	return nil
 }
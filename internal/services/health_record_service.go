@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"strings"
+	"time"
+)
+
+const HEALTHRECORDSTABLE = "HealthRecordsTable"
+
+// HEALTHDOCUMENTPREFIX keeps scanned health documents apart from user images in the blob container
+const HEALTHDOCUMENTPREFIX = "health/"
+
+// HealthRecordRepo interface methods implemented in repositories package
+type HealthRecordRepo interface {
+	GetRecord(tableName string, childID string, recordID string) (models.HealthRecord, error)
+	GetRecords(tableName string, filter string) ([]models.HealthRecord, error)
+	UpsertRecord(tableName string, record models.HealthRecord) error
+	DeleteRecord(tableName string, childID string, recordID string) error
+}
+
+// HealthRecordService tracks each child's immunizations and physical exams against the center's required records
+type HealthRecordService struct {
+	repo         HealthRecordRepo
+	blobRepo     BlobRepo
+	childRepo    ChildRepo
+	userRepo     UserRepo
+	emailService EmailService
+	required     []string
+	warning      time.Duration
+}
+
+// NewHealthRecordService constructs and returns a HealthRecordService object
+func NewHealthRecordService(r HealthRecordRepo, b BlobRepo, c ChildRepo, u UserRepo, e EmailService, required []string, warning time.Duration) *HealthRecordService {
+	return &HealthRecordService{repo: r, blobRepo: b, childRepo: c, userRepo: u, emailService: e, required: required, warning: warning}
+}
+
+// AddRecord stores a health record with an optional scanned document, guardians and staff covering the child's classroom
+func (s *HealthRecordService) AddRecord(ctx context.Context, actorID string, record models.HealthRecord, document []byte, documentType string) (models.HealthRecord, error) {
+	if _, err := s.authorizeViewer(actorID, record.ChildID); err != nil {
+		return models.HealthRecord{}, err
+	}
+	if len(document) > 0 {
+		record.DocumentBlob = fmt.Sprintf("%s%s/%s", HEALTHDOCUMENTPREFIX, record.ChildID, record.ID)
+		record.DocumentType = documentType
+		metadata := map[string]string{"id": actorID, "child": record.ChildID}
+		if _, err := s.blobRepo.UploadBlob(ctx, record.DocumentBlob, documentType, document, metadata); err != nil {
+			return models.HealthRecord{}, err
+		}
+	}
+	if err := s.repo.UpsertRecord(HEALTHRECORDSTABLE, record); err != nil {
+		return models.HealthRecord{}, err
+	}
+	return record, nil
+}
+
+// GetRecords returns a child's health records to its guardians and staff covering its classroom
+func (s *HealthRecordService) GetRecords(actorID string, childID string) ([]models.HealthRecord, error) {
+	if _, err := s.authorizeViewer(actorID, childID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetRecords(HEALTHRECORDSTABLE, fmt.Sprintf("PartitionKey eq '%s'", childID))
+}
+
+// GetDocument returns the scanned document behind a health record
+func (s *HealthRecordService) GetDocument(ctx context.Context, actorID string, childID string, recordID string) ([]byte, string, error) {
+	if _, err := s.authorizeViewer(actorID, childID); err != nil {
+		return nil, "", err
+	}
+	record, err := s.repo.GetRecord(HEALTHRECORDSTABLE, childID, recordID)
+	if err != nil {
+		return nil, "", err
+	}
+	if record.DocumentBlob == "" {
+		return nil, "", fmt.Errorf("HealthRecordService.GetDocument: Record %s has no document", recordID)
+	}
+	return s.blobRepo.GetBlob(ctx, record.DocumentBlob)
+}
+
+// DeleteRecord removes a health record and its document, staff covering the child's classroom or the guardian who added it
+func (s *HealthRecordService) DeleteRecord(ctx context.Context, actorID string, childID string, recordID string) error {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return err
+	}
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+	if err != nil {
+		return err
+	}
+	record, err := s.repo.GetRecord(HEALTHRECORDSTABLE, childID, recordID)
+	if err != nil {
+		return err
+	}
+	if record.UploadedBy != actor.ID {
+		if err := authorizeClassroom(actor, models.PermissionManageChildren, child.Classroom); err != nil {
+			return err
+		}
+	}
+	if record.DocumentBlob != "" {
+		if err := s.blobRepo.DeleteBlob(ctx, record.DocumentBlob); err != nil {
+			log.Printf("HealthRecordService.DeleteRecord: Failed to delete document for record %s: %v", recordID, err)
+		}
+	}
+	return s.repo.DeleteRecord(HEALTHRECORDSTABLE, childID, recordID)
+}
+
+// GetComplianceReport lists children with missing, expired or soon to expire records, limited to classrooms the actor covers
+func (s *HealthRecordService) GetComplianceReport(actorID string) ([]models.ChildCompliance, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Role.Can(models.PermissionManageChildren) {
+		return nil, fmt.Errorf("%w: %s may not %s", ErrForbidden, actor.Role, models.PermissionManageChildren)
+	}
+
+	report, err := s.checkAll()
+	if err != nil {
+		return nil, err
+	}
+	visible := []models.ChildCompliance{}
+	for _, compliance := range report {
+		if authorizeClassroom(actor, models.PermissionManageChildren, compliance.Classroom) == nil {
+			visible = append(visible, compliance)
+		}
+	}
+	return visible, nil
+}
+
+// SendHealthReminders emails the guardians of every child with outstanding records, returning how many emails were sent
+func (s *HealthRecordService) SendHealthReminders() (int, error) {
+	report, err := s.checkAll()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, compliance := range report {
+		links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", compliance.ChildID))
+		if err != nil {
+			return sent, err
+		}
+		subject := fmt.Sprintf("Health records needed for %s", compliance.ChildName)
+		plainTextContent, htmlContent := renderHealthReminder(compliance)
+		for _, link := range links {
+			guardian, err := s.userRepo.GetUser(USERSTABLE, link.UserID)
+			if err != nil || guardian.Email == "" {
+				continue
+			}
+			if err := s.emailService.SendEmail(guardian.Email, subject, plainTextContent, htmlContent); err != nil {
+				log.Printf("HealthRecordService.SendHealthReminders: Failed to email %s: %v", guardian.Email, err)
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// healthReminderEpoch is the Monday reminder days are counted from, so a weekly interval always lands on a Monday
+var healthReminderEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// StartHealthReminders emails guardians about outstanding records at the given time after midnight in the center's time
+// zone, every interval rounded to whole days and counted from a fixed Monday, until the context is cancelled. The schedule
+// does not depend on when the server started, so redeploys neither skip nor repeat a send
+func (s *HealthRecordService) StartHealthReminders(ctx context.Context, interval time.Duration, at time.Duration) {
+	days := int(interval / (24 * time.Hour))
+	if days < 1 {
+		days = 1
+	}
+	go func() {
+		for {
+			next := nextHealthReminder(models.CenterNow(), days, at)
+			select {
+			case <-ctx.Done():
+				log.Println("Health record reminders stopped")
+				return
+			case <-time.After(time.Until(next)):
+				sent, err := s.SendHealthReminders()
+				if err != nil {
+					log.Printf("Health record reminders failed after %d emails: %v", sent, err)
+					continue
+				}
+				log.Printf("Health record reminders sent %d emails", sent)
+			}
+		}
+	}()
+	log.Printf("Health record reminders scheduled every %d days at %v after midnight", days, at)
+}
+
+// nextHealthReminder returns the first reminder time after now, on a day a whole number of intervals from the epoch.
+// at is read as a clock time so daylight saving changes don't move the reminder
+func nextHealthReminder(now time.Time, days int, at time.Duration) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dayNumber := int(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Sub(healthReminderEpoch) / (24 * time.Hour))
+	offset := (days - dayNumber%days) % days
+	next := models.ClockTime(midnight.AddDate(0, 0, offset), at)
+	if !next.After(now) {
+		next = models.ClockTime(midnight.AddDate(0, 0, offset+days), at)
+	}
+	return next
+}
+
+// checkAll runs the compliance check for every enrolled child, returning only children with issues
+func (s *HealthRecordService) checkAll() ([]models.ChildCompliance, error) {
+	children, err := s.childRepo.GetAllChildren(CHILDRENTABLE)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.GetRecords(HEALTHRECORDSTABLE, "")
+	if err != nil {
+		return nil, err
+	}
+	byChild := make(map[string][]models.HealthRecord)
+	for _, record := range records {
+		byChild[record.ChildID] = append(byChild[record.ChildID], record)
+	}
+
+	now := models.CenterNow()
+	report := []models.ChildCompliance{}
+	for _, child := range children {
+		compliance := models.CheckCompliance(child, byChild[child.ID], s.required, now, s.warning)
+		if len(compliance.Issues) > 0 {
+			report = append(report, compliance)
+		}
+	}
+	return report, nil
+}
+
+// authorizeViewer loads a child with its guardians and allows those guardians and staff covering its classroom
+func (s *HealthRecordService) authorizeViewer(actorID string, childID string) (models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", childID))
+	if err != nil {
+		return models.Child{}, err
+	}
+	child.Guardians = links
+	if err := authorizeChildAccess(actor, child); err != nil {
+		return models.Child{}, err
+	}
+	return child, nil
+}
+
+func (s *HealthRecordService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("HealthRecordService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
+
+// Helper - plain text and HTML bodies listing a child's outstanding records
+func renderHealthReminder(compliance models.ChildCompliance) (string, string) {
+	var lines []string
+	for _, issue := range compliance.Issues {
+		switch issue.Status {
+		case models.ComplianceMissing:
+			lines = append(lines, fmt.Sprintf("%s: no record on file", issue.Requirement))
+		case models.ComplianceExpired:
+			lines = append(lines, fmt.Sprintf("%s: expired on %s", issue.Requirement, issue.ExpiresOn))
+		case models.ComplianceExpiring:
+			lines = append(lines, fmt.Sprintf("%s: expires on %s", issue.Requirement, issue.ExpiresOn))
+		}
+	}
+
+	intro := fmt.Sprintf("Our records for %s need updating:", compliance.ChildName)
+	outro := "Please upload the latest documents in the Little Einstein app or bring them to the front desk."
+	plainTextContent := intro + "\n" + strings.Join(lines, "\n") + "\n" + outro
+
+	var items strings.Builder
+	for _, line := range lines {
+		items.WriteString("<li>" + html.EscapeString(line) + "</li>")
+	}
+	htmlContent := fmt.Sprintf("<p>%s</p><ul>%s</ul><p>%s</p>", html.EscapeString(intro), items.String(), outro)
+	return plainTextContent, htmlContent
+}
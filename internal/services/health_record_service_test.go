@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNextHealthReminder(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+	at := 9 * time.Hour
+
+	tests := []struct {
+		name string
+		now  time.Time
+		days int
+		want time.Time
+	}{
+		{
+			name: "daily before the send time",
+			now:  time.Date(2025, time.June, 4, 8, 0, 0, 0, chicago),
+			days: 1,
+			want: time.Date(2025, time.June, 4, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "daily at the send time moves to tomorrow",
+			now:  time.Date(2025, time.June, 4, 9, 0, 0, 0, chicago),
+			days: 1,
+			want: time.Date(2025, time.June, 5, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "weekly on the epoch Monday",
+			now:  time.Date(2024, time.January, 1, 7, 0, 0, 0, chicago),
+			days: 7,
+			want: time.Date(2024, time.January, 1, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "weekly on a later Monday before the send time",
+			now:  time.Date(2025, time.March, 3, 8, 59, 0, 0, chicago),
+			days: 7,
+			want: time.Date(2025, time.March, 3, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "weekly on a Monday after the send time",
+			now:  time.Date(2025, time.March, 3, 10, 0, 0, 0, chicago),
+			days: 7,
+			want: time.Date(2025, time.March, 10, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "weekly mid week",
+			now:  time.Date(2025, time.March, 5, 8, 0, 0, 0, chicago),
+			days: 7,
+			want: time.Date(2025, time.March, 10, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "every three days on the boundary",
+			now:  time.Date(2024, time.January, 4, 8, 0, 0, 0, chicago),
+			days: 3,
+			want: time.Date(2024, time.January, 4, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "every three days off the boundary",
+			now:  time.Date(2024, time.January, 5, 8, 0, 0, 0, chicago),
+			days: 3,
+			want: time.Date(2024, time.January, 7, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "spring forward keeps the clock time",
+			now:  time.Date(2025, time.March, 9, 1, 0, 0, 0, chicago),
+			days: 1,
+			want: time.Date(2025, time.March, 9, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "fall back keeps the clock time",
+			now:  time.Date(2025, time.November, 2, 0, 30, 0, 0, chicago),
+			days: 1,
+			want: time.Date(2025, time.November, 2, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "every three days across the fall back",
+			now:  time.Date(2025, time.October, 31, 10, 0, 0, 0, chicago),
+			days: 3,
+			want: time.Date(2025, time.November, 3, 9, 0, 0, 0, chicago),
+		},
+		{
+			name: "in the repeated hour on the fall back day",
+			now:  time.Date(2025, time.November, 2, 1, 30, 0, 0, chicago).Add(time.Hour),
+			days: 1,
+			want: time.Date(2025, time.November, 2, 9, 0, 0, 0, chicago),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextHealthReminder(tt.now, tt.days, at)
+			if !got.Equal(tt.want) {
+				t.Errorf("nextHealthReminder(%v, %d) = %v, want %v", tt.now, tt.days, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"time"
+)
+
+const INCIDENTSTABLE = "IncidentsTable"
+
+// ErrIncidentState is returned when an incident report is edited, submitted or acknowledged out of workflow order
+var ErrIncidentState = errors.New("incident report is not in the right state")
+
+// IncidentRepo interface methods implemented in repositories package
+type IncidentRepo interface {
+	GetIncident(tableName string, id string) (models.Incident, error)
+	GetIncidents(tableName string, filter string) ([]models.Incident, error)
+	UpsertIncident(tableName string, incident models.Incident) error
+}
+
+// IncidentService manages incident reports from a staff draft through guardian acknowledgment
+type IncidentService struct {
+	repo         IncidentRepo
+	childRepo    ChildRepo
+	userRepo     UserRepo
+	emailService EmailService
+}
+
+// NewIncidentService constructs and returns an IncidentService object
+func NewIncidentService(r IncidentRepo, c ChildRepo, u UserRepo, e EmailService) *IncidentService {
+	return &IncidentService{repo: r, childRepo: c, userRepo: u, emailService: e}
+}
+
+// CreateIncident validates and stores a draft report about a child, staff covering the child's classroom only
+func (s *IncidentService) CreateIncident(actorID string, draft models.Incident) (models.Incident, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, draft.ChildID)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, child.Classroom); err != nil {
+		return models.Incident{}, err
+	}
+	incident, err := models.NewIncident(draft.ID, child, draft.OccurredAt, draft.Location, draft.Description, draft.FirstAid, draft.Witnesses, actor.ID)
+	if err != nil {
+		return models.Incident{}, fmt.Errorf("IncidentService.CreateIncident: %w", err)
+	}
+	if err := s.repo.UpsertIncident(INCIDENTSTABLE, *incident); err != nil {
+		return models.Incident{}, err
+	}
+	return *incident, nil
+}
+
+// GetIncident returns a report to staff covering the child's classroom, and to guardians once it has been submitted
+func (s *IncidentService) GetIncident(actorID string, id string) (models.Incident, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	incident, err := s.repo.GetIncident(INCIDENTSTABLE, id)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if err := s.authorizeViewer(actor, incident); err != nil {
+		return models.Incident{}, err
+	}
+	return incident, nil
+}
+
+// GetIncidentsByChild returns the reports about a child visible to the acting user
+func (s *IncidentService) GetIncidentsByChild(actorID string, childID string) ([]models.Incident, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := s.repo.GetIncidents(INCIDENTSTABLE, fmt.Sprintf("ChildID eq '%s'", childID))
+	if err != nil {
+		return nil, err
+	}
+	visible := []models.Incident{}
+	for _, incident := range incidents {
+		if s.authorizeViewer(actor, incident) == nil {
+			visible = append(visible, incident)
+		}
+	}
+	return visible, nil
+}
+
+// UpdateIncident edits a draft report, staff covering the child's classroom only
+func (s *IncidentService) UpdateIncident(actorID string, newData models.Incident) (models.Incident, error) {
+	incident, err := s.getForStaff(actorID, newData.ID)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if err := incident.Update(newData); err != nil {
+		return models.Incident{}, fmt.Errorf("IncidentService.UpdateIncident: %v: %w", err, ErrIncidentState)
+	}
+	if err := s.repo.UpsertIncident(INCIDENTSTABLE, incident); err != nil {
+		return models.Incident{}, err
+	}
+	return incident, nil
+}
+
+// SubmitIncident finalises a draft and emails the child's guardians asking them to acknowledge it
+func (s *IncidentService) SubmitIncident(actorID string, id string) (models.Incident, error) {
+	incident, err := s.getForStaff(actorID, id)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if err := incident.Submit(time.Now()); err != nil {
+		return models.Incident{}, fmt.Errorf("IncidentService.SubmitIncident: %v: %w", err, ErrIncidentState)
+	}
+	if err := s.repo.UpsertIncident(INCIDENTSTABLE, incident); err != nil {
+		return models.Incident{}, err
+	}
+	s.notifyGuardians(incident)
+	return incident, nil
+}
+
+// AcknowledgeIncident records a guardian's sign off on a submitted report
+func (s *IncidentService) AcknowledgeIncident(actorID string, id string) (models.Incident, error) {
+	incident, err := s.repo.GetIncident(INCIDENTSTABLE, id)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if !s.isGuardian(actorID, incident.ChildID) {
+		return models.Incident{}, fmt.Errorf("%w: only guardians may acknowledge incident report %s", ErrForbidden, id)
+	}
+	if err := incident.Acknowledge(actorID, time.Now()); err != nil {
+		return models.Incident{}, fmt.Errorf("IncidentService.AcknowledgeIncident: %v: %w", err, ErrIncidentState)
+	}
+	if err := s.repo.UpsertIncident(INCIDENTSTABLE, incident); err != nil {
+		return models.Incident{}, err
+	}
+	return incident, nil
+}
+
+// ExportIncidents returns every report that occurred in [from, to) for licensing inspections, routes restrict it to admins
+func (s *IncidentService) ExportIncidents(from time.Time, to time.Time) ([]models.Incident, error) {
+	filter := fmt.Sprintf("OccurredAt ge '%s' and OccurredAt lt '%s'", from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	return s.repo.GetIncidents(INCIDENTSTABLE, filter)
+}
+
+// notifyGuardians emails each guardian of the child, failures are logged so submission still succeeds
+func (s *IncidentService) notifyGuardians(incident models.Incident) {
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, incident.ChildID)
+	if err != nil {
+		log.Printf("IncidentService.notifyGuardians: Failed to load child %s: %v", incident.ChildID, err)
+		return
+	}
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", incident.ChildID))
+	if err != nil {
+		log.Printf("IncidentService.notifyGuardians: Failed to load guardians of %s: %v", incident.ChildID, err)
+		return
+	}
+
+	subject := fmt.Sprintf("Incident report for %s", child.Name)
+	occurredAt := incident.OccurredAt.In(models.CenterLocation()).Format("Mon Jan 2 at 3:04 PM")
+	plainTextContent := fmt.Sprintf("An incident involving %s was recorded on %s. Please review and acknowledge the report in the Little Einstein app.", child.Name, occurredAt)
+	htmlContent := fmt.Sprintf(`<p>An incident involving %s was recorded on %s.</p><p><strong>What happened:</strong> %s</p><p><strong>First aid:</strong> %s</p><p>Please review and acknowledge the report in the <a href="https://littleeinsteinchildcare.org">Little Einstein app</a>.</p>`,
+		html.EscapeString(child.Name), occurredAt, html.EscapeString(incident.Description), html.EscapeString(incident.FirstAid))
+
+	for _, link := range links {
+		guardian, err := s.userRepo.GetUser(USERSTABLE, link.UserID)
+		if err != nil || guardian.Email == "" {
+			continue
+		}
+		if err := s.emailService.SendEmail(guardian.Email, subject, plainTextContent, htmlContent); err != nil {
+			log.Printf("IncidentService.notifyGuardians: Failed to email %s: %v", guardian.Email, err)
+		}
+	}
+}
+
+// authorizeViewer allows staff covering the classroom, and guardians once the report is no longer a draft
+func (s *IncidentService) authorizeViewer(actor models.User, incident models.Incident) error {
+	if incident.Status != models.IncidentDraft && s.isGuardian(actor.ID, incident.ChildID) {
+		return nil
+	}
+	return authorizeClassroom(actor, models.PermissionManageChildren, incident.Classroom)
+}
+
+// getForStaff loads a report for staff covering its classroom
+func (s *IncidentService) getForStaff(actorID string, id string) (models.Incident, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	incident, err := s.repo.GetIncident(INCIDENTSTABLE, id)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, incident.Classroom); err != nil {
+		return models.Incident{}, err
+	}
+	return incident, nil
+}
+
+func (s *IncidentService) isGuardian(userID string, childID string) bool {
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s' and RowKey eq '%s'", childID, userID))
+	return err == nil && len(links) > 0
+}
+
+func (s *IncidentService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("IncidentService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
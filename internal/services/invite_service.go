@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"strings"
+)
+
+const INVITESTABLE = "InvitedUsersTable"
+
+// ErrInviteNotFound is returned when no invite exists for an email address
+var ErrInviteNotFound = errors.New("invite not found")
+
+// ErrInviteUsed is returned when an invite has already been claimed by a sign up
+var ErrInviteUsed = errors.New("invite already used")
+
+// InviteRepo interface methods implemented in repositories package
+type InviteRepo interface {
+	GetInvite(tableName string, email string) (models.Invite, error)
+	GetAllInvites(tableName string) ([]models.Invite, error)
+	UpsertInvite(tableName string, invite models.Invite) error
+	UpdateInvite(tableName string, invite models.Invite) (string, error)
+	DeleteInvite(tableName string, email string) error
+}
+
+// InviteService is the single source of truth for invitations and the role granted at sign up
+type InviteService struct {
+	repo InviteRepo
+}
+
+// NewInviteService constructs and returns an InviteService object
+func NewInviteService(r InviteRepo) *InviteService {
+	return &InviteService{repo: r}
+}
+
+// CreateInvite records an invite for the email address. Re-inviting keeps the earlier invite's sign up state and
+// linked children, and its role unless a role is given
+func (s *InviteService) CreateInvite(email string, role models.Role) (models.Invite, error) {
+	invite := models.NewInvite(strings.ToLower(email), role)
+	if existing, err := s.GetInvite(email); err == nil {
+		invite.SignedUp = existing.SignedUp
+		invite.ChildIDs = existing.ChildIDs
+		if role == "" {
+			invite.Role = existing.Role
+		}
+	} else if !errors.Is(err, ErrInviteNotFound) {
+		return models.Invite{}, fmt.Errorf("InviteService.CreateInvite: %w", err)
+	}
+	if invite.Role == "" {
+		invite.Role = models.RoleParent
+	}
+	if err := s.repo.UpsertInvite(INVITESTABLE, *invite); err != nil {
+		return models.Invite{}, err
+	}
+	return *invite, nil
+}
+
+// CreateGuardianInvite invites a parent who will be linked to the child once they sign up,
+// keeping any children from an earlier invite that has not been used yet
+func (s *InviteService) CreateGuardianInvite(email string, childID string) (models.Invite, error) {
+	invite := models.NewInvite(strings.ToLower(email), models.RoleParent)
+	if existing, err := s.GetInvite(email); err == nil && !existing.SignedUp {
+		invite.Role = existing.Role
+		for _, id := range existing.ChildIDs {
+			if id != childID {
+				invite.ChildIDs = append(invite.ChildIDs, id)
+			}
+		}
+	}
+	invite.ChildIDs = append(invite.ChildIDs, childID)
+	if err := s.repo.UpsertInvite(INVITESTABLE, *invite); err != nil {
+		return models.Invite{}, err
+	}
+	return *invite, nil
+}
+
+// SaveInvite writes an invite as-is, used when importing existing records
+func (s *InviteService) SaveInvite(invite models.Invite) error {
+	invite.Email = strings.ToLower(invite.Email)
+	return s.repo.UpsertInvite(INVITESTABLE, invite)
+}
+
+// GetInvite returns the invite for an email address
+func (s *InviteService) GetInvite(email string) (models.Invite, error) {
+	return s.repo.GetInvite(INVITESTABLE, strings.ToLower(email))
+}
+
+func (s *InviteService) GetAllInvites() ([]models.Invite, error) {
+	invites, err := s.repo.GetAllInvites(INVITESTABLE)
+	if err != nil {
+		return []models.Invite{}, err
+	}
+	return invites, nil
+}
+
+// IsInvited reports whether an invite exists for the email address
+func (s *InviteService) IsInvited(email string) (bool, error) {
+	_, err := s.GetInvite(email)
+	if errors.Is(err, ErrInviteNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ClaimInvite flags the invite as used once the account has been created and returns it. The update is conditional
+// on the version read, so when two sign ups race for the same invite only one claims it and the other gets ErrInviteUsed.
+// The returned invite carries the version written by the claim for ReleaseInvite
+func (s *InviteService) ClaimInvite(email string) (models.Invite, error) {
+	invite, err := s.GetInvite(email)
+	if err != nil {
+		return models.Invite{}, fmt.Errorf("InviteService.ClaimInvite: %w", err)
+	}
+	if invite.SignedUp {
+		return models.Invite{}, fmt.Errorf("InviteService.ClaimInvite: %s: %w", invite.Email, ErrInviteUsed)
+	}
+	invite.SignedUp = true
+	etag, err := s.repo.UpdateInvite(INVITESTABLE, invite)
+	if err != nil {
+		return models.Invite{}, err
+	}
+	invite.ETag = etag
+	return invite, nil
+}
+
+// ReleaseInvite undoes a claim when the account it was claimed for could not be created, so the invite can be used again.
+// The update is conditional on the version the claim wrote, an invite changed since then is left alone
+func (s *InviteService) ReleaseInvite(claimed models.Invite) error {
+	claimed.SignedUp = false
+	if _, err := s.repo.UpdateInvite(INVITESTABLE, claimed); err != nil {
+		return fmt.Errorf("InviteService.ReleaseInvite: %w", err)
+	}
+	return nil
+}
+
+// DeleteInvite removes an invite
+func (s *InviteService) DeleteInvite(email string) error {
+	return s.repo.DeleteInvite(INVITESTABLE, strings.ToLower(email))
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"time"
+)
+
+const MEDICATIONAUTHORIZATIONSTABLE = "MedicationAuthorizationsTable"
+const MEDICATIONDOSESTABLE = "MedicationDosesTable"
+
+// MedicationRepo interface methods implemented in repositories package
+type MedicationRepo interface {
+	GetAuthorizations(tableName string, childID string) ([]models.MedicationAuthorization, error)
+	UpsertAuthorization(tableName string, authorization models.MedicationAuthorization) error
+	AddDose(tableName string, dose models.MedicationDose) error
+	GetDoses(tableName string, childID string) ([]models.MedicationDose, error)
+}
+
+// MedicationService manages guardians' medication authorizations and the log of doses staff give
+type MedicationService struct {
+	repo      MedicationRepo
+	childRepo ChildRepo
+	userRepo  UserRepo
+}
+
+// NewMedicationService constructs and returns a MedicationService object
+func NewMedicationService(r MedicationRepo, c ChildRepo, u UserRepo) *MedicationService {
+	return &MedicationService{repo: r, childRepo: c, userRepo: u}
+}
+
+// GetAuthorizations returns a child's medication authorizations to its guardians and staff covering its classroom
+func (s *MedicationService) GetAuthorizations(actorID string, childID string) ([]models.MedicationAuthorization, error) {
+	if _, err := s.authorizeViewer(actorID, childID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAuthorizations(MEDICATIONAUTHORIZATIONSTABLE, childID)
+}
+
+// AddAuthorization stores a signed medication authorization, guardians only
+func (s *MedicationService) AddAuthorization(actorID string, authorization models.MedicationAuthorization) (models.MedicationAuthorization, error) {
+	child, err := s.getChild(authorization.ChildID)
+	if err != nil {
+		return models.MedicationAuthorization{}, err
+	}
+	if !child.HasGuardian(actorID) {
+		return models.MedicationAuthorization{}, fmt.Errorf("%w: only guardians of child %s may authorize medication", ErrForbidden, child.ID)
+	}
+	if err := s.repo.UpsertAuthorization(MEDICATIONAUTHORIZATIONSTABLE, authorization); err != nil {
+		return models.MedicationAuthorization{}, err
+	}
+	return authorization, nil
+}
+
+// RevokeAuthorization withdraws a medication authorization, guardians only
+func (s *MedicationService) RevokeAuthorization(actorID string, childID string, authorizationID string) (models.MedicationAuthorization, error) {
+	child, err := s.getChild(childID)
+	if err != nil {
+		return models.MedicationAuthorization{}, err
+	}
+	if !child.HasGuardian(actorID) {
+		return models.MedicationAuthorization{}, fmt.Errorf("%w: only guardians of child %s may revoke medication authorizations", ErrForbidden, child.ID)
+	}
+	authorization, err := s.findAuthorization(childID, authorizationID)
+	if err != nil {
+		return models.MedicationAuthorization{}, err
+	}
+	if authorization.IsRevoked() {
+		return authorization, nil
+	}
+	authorization.RevokedAt = time.Now()
+	if err := s.repo.UpsertAuthorization(MEDICATIONAUTHORIZATIONSTABLE, authorization); err != nil {
+		return models.MedicationAuthorization{}, err
+	}
+	return authorization, nil
+}
+
+// LogDose records a dose given under an authorization, staff covering the child's classroom only.
+// Doses outside the authorized dates, schedule or minimum interval are still logged with warnings so the record stays complete
+func (s *MedicationService) LogDose(actorID string, dose models.MedicationDose) (models.MedicationDose, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.MedicationDose{}, err
+	}
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, dose.ChildID)
+	if err != nil {
+		return models.MedicationDose{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, child.Classroom); err != nil {
+		return models.MedicationDose{}, err
+	}
+	authorization, err := s.findAuthorization(dose.ChildID, dose.AuthorizationID)
+	if err != nil {
+		return models.MedicationDose{}, err
+	}
+	doses, err := s.repo.GetDoses(MEDICATIONDOSESTABLE, dose.ChildID)
+	if err != nil {
+		return models.MedicationDose{}, err
+	}
+	previous := []models.MedicationDose{}
+	for _, logged := range doses {
+		if logged.AuthorizationID == authorization.ID {
+			previous = append(previous, logged)
+		}
+	}
+
+	dose.StaffID = actor.ID
+	dose.Drug = authorization.Drug
+	dose.Dose = authorization.Dose
+	dose.Warnings = authorization.CheckDose(dose.AdministeredAt, previous)
+	if err := s.repo.AddDose(MEDICATIONDOSESTABLE, dose); err != nil {
+		return models.MedicationDose{}, err
+	}
+	return dose, nil
+}
+
+// GetDoses returns a child's dose log to its guardians and staff covering its classroom
+func (s *MedicationService) GetDoses(actorID string, childID string) ([]models.MedicationDose, error) {
+	if _, err := s.authorizeViewer(actorID, childID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDoses(MEDICATIONDOSESTABLE, childID)
+}
+
+func (s *MedicationService) findAuthorization(childID string, authorizationID string) (models.MedicationAuthorization, error) {
+	authorizations, err := s.repo.GetAuthorizations(MEDICATIONAUTHORIZATIONSTABLE, childID)
+	if err != nil {
+		return models.MedicationAuthorization{}, err
+	}
+	for _, authorization := range authorizations {
+		if authorization.ID == authorizationID {
+			return authorization, nil
+		}
+	}
+	return models.MedicationAuthorization{}, fmt.Errorf("MedicationService: Authorization %s not found for child %s", authorizationID, childID)
+}
+
+// authorizeViewer allows a child's guardians and staff covering its classroom
+func (s *MedicationService) authorizeViewer(actorID string, childID string) (models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	child, err := s.getChild(childID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	if err := authorizeChildAccess(actor, child); err != nil {
+		return models.Child{}, err
+	}
+	return child, nil
+}
+
+// getChild loads a child with its guardian links
+func (s *MedicationService) getChild(childID string) (models.Child, error) {
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", childID))
+	if err != nil {
+		return models.Child{}, err
+	}
+	child.Guardians = links
+	return child, nil
+}
+
+func (s *MedicationService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("MedicationService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+)
+
+const MENUSTABLE = "MenusTable"
+
+// MenuRepo interface methods implemented in repositories package
+type MenuRepo interface {
+	GetMenu(tableName string, week string) (models.Menu, error)
+	UpsertMenu(tableName string, menu models.Menu) error
+}
+
+// MenuService publishes weekly menus and flags dishes that clash with children's allergies
+type MenuService struct {
+	repo      MenuRepo
+	childRepo ChildRepo
+	userRepo  UserRepo
+}
+
+// NewMenuService constructs and returns a MenuService object
+func NewMenuService(r MenuRepo, c ChildRepo, u UserRepo) *MenuService {
+	return &MenuService{repo: r, childRepo: c, userRepo: u}
+}
+
+// PublishMenu stores a week's menu, replacing any earlier version, and returns the allergen conflicts across all enrolled children
+func (s *MenuService) PublishMenu(menu models.Menu) (models.Menu, []models.AllergenConflict, error) {
+	if err := s.repo.UpsertMenu(MENUSTABLE, menu); err != nil {
+		return models.Menu{}, nil, err
+	}
+	children, err := s.childRepo.GetAllChildren(CHILDRENTABLE)
+	if err != nil {
+		return models.Menu{}, nil, err
+	}
+	return menu, models.FindAllergenConflicts(menu, children), nil
+}
+
+// GetMenu returns a week's menu with the allergen conflicts for the children the acting user can see:
+// guardians their own children, teachers their classrooms and directors everyone
+func (s *MenuService) GetMenu(actorID string, week string) (models.Menu, []models.AllergenConflict, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.Menu{}, nil, fmt.Errorf("MenuService: Failed to load acting user %s: %w", actorID, err)
+	}
+	menu, err := s.repo.GetMenu(MENUSTABLE, week)
+	if err != nil {
+		return models.Menu{}, nil, err
+	}
+
+	children, err := s.childRepo.GetAllChildren(CHILDRENTABLE)
+	if err != nil {
+		return models.Menu{}, nil, err
+	}
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, "")
+	if err != nil {
+		return models.Menu{}, nil, err
+	}
+	linksByChild := make(map[string][]models.GuardianLink)
+	for _, link := range links {
+		linksByChild[link.ChildID] = append(linksByChild[link.ChildID], link)
+	}
+	visible := []models.Child{}
+	for _, child := range children {
+		child.Guardians = linksByChild[child.ID]
+		if authorizeChildAccess(actor, child) == nil {
+			visible = append(visible, child)
+		}
+	}
+	return menu, models.FindAllergenConflicts(menu, visible), nil
+}
@@ -0,0 +1,464 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"sort"
+	"time"
+)
+
+const MESSAGETHREADSTABLE = "MessageThreadsTable"
+const MESSAGESTABLE = "MessagesTable"
+const READRECEIPTSTABLE = "ReadReceiptsTable"
+
+// Longest message excerpt included in notification emails
+const messagePreviewLength = 200
+
+// MessageRepo interface methods implemented in repositories package
+type MessageRepo interface {
+	GetThread(tableName string, id string) (models.Thread, error)
+	GetThreads(tableName string, filter string) ([]models.Thread, error)
+	UpsertThread(tableName string, thread models.Thread) error
+	AddMessage(tableName string, message models.Message) error
+	GetMessages(tableName string, threadID string) ([]models.Message, error)
+	UpsertReceipt(tableName string, receipt models.ReadReceipt) error
+	IncrementUnread(tableName string, threadID string, userID string) error
+	GetReceipts(tableName string, filter string) ([]models.ReadReceipt, error)
+}
+
+// MessageService handles conversations between guardians and staff, one-to-one or broadcast to a classroom
+type MessageService struct {
+	repo         MessageRepo
+	childRepo    ChildRepo
+	userRepo     UserRepo
+	blobRepo     BlobRepo
+	emailService EmailService
+	quietHours   models.QuietHours
+}
+
+// NewMessageService constructs and returns a MessageService object
+func NewMessageService(r MessageRepo, c ChildRepo, u UserRepo, b BlobRepo, e EmailService, quietHours models.QuietHours) *MessageService {
+	return &MessageService{repo: r, childRepo: c, userRepo: u, blobRepo: b, emailService: e, quietHours: quietHours}
+}
+
+// StartDirectThread opens a conversation between a guardian and a staff member, returning the existing one if the pair already have a thread
+func (s *MessageService) StartDirectThread(actorID string, thread models.Thread) (models.Thread, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	otherID := thread.ParticipantIDs[len(thread.ParticipantIDs)-1]
+	other, err := s.userRepo.GetUser(USERSTABLE, otherID)
+	if err != nil {
+		return models.Thread{}, fmt.Errorf("MessageService.StartDirectThread: User %s not found: %w", otherID, err)
+	}
+	if !actor.Role.IsStaff() && !other.Role.IsStaff() {
+		return models.Thread{}, fmt.Errorf("%w: direct messages must include a staff member", ErrForbidden)
+	}
+
+	existing, err := s.repo.GetThreads(MESSAGETHREADSTABLE, fmt.Sprintf("Kind eq '%s'", models.ThreadDirect))
+	if err != nil {
+		return models.Thread{}, err
+	}
+	for _, candidate := range existing {
+		if candidate.HasParticipant(actorID) && candidate.HasParticipant(otherID) {
+			return candidate, nil
+		}
+	}
+	if err := s.repo.UpsertThread(MESSAGETHREADSTABLE, thread); err != nil {
+		return models.Thread{}, err
+	}
+	return thread, nil
+}
+
+// StartClassroomThread opens a broadcast to a classroom's guardians, staff covering the classroom only
+func (s *MessageService) StartClassroomThread(actorID string, thread models.Thread) (models.Thread, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageChildren, thread.Classroom); err != nil {
+		return models.Thread{}, err
+	}
+	if err := s.repo.UpsertThread(MESSAGETHREADSTABLE, thread); err != nil {
+		return models.Thread{}, err
+	}
+	return thread, nil
+}
+
+// GetThreads returns the threads a user can see, most recently active first, with their unread counts
+func (s *MessageService) GetThreads(actorID string) ([]models.Thread, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+	threads, err := s.visibleThreads(actor)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := s.repo.GetReceipts(READRECEIPTSTABLE, fmt.Sprintf("RowKey eq '%s'", actorID))
+	if err != nil {
+		return nil, err
+	}
+	unread := make(map[string]int, len(receipts))
+	for _, receipt := range receipts {
+		unread[receipt.ThreadID] = receipt.Unread
+	}
+	for i := range threads {
+		threads[i].UnreadCount = unread[threads[i].ID]
+	}
+	return threads, nil
+}
+
+// UnreadCount returns how many messages across all of a user's threads they have not read, from the counters on their read receipts
+func (s *MessageService) UnreadCount(userID string) (int, error) {
+	receipts, err := s.repo.GetReceipts(READRECEIPTSTABLE, fmt.Sprintf("RowKey eq '%s'", userID))
+	if err != nil {
+		return 0, err
+	}
+	unread := 0
+	for _, receipt := range receipts {
+		unread += receipt.Unread
+	}
+	return unread, nil
+}
+
+// GetMessages returns a thread's messages oldest first, each with the users who have read it
+func (s *MessageService) GetMessages(actorID string, threadID string) ([]models.Message, error) {
+	if _, _, err := s.authorizeThread(actorID, threadID); err != nil {
+		return nil, err
+	}
+	messages, err := s.repo.GetMessages(MESSAGESTABLE, threadID)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := s.repo.GetReceipts(READRECEIPTSTABLE, fmt.Sprintf("PartitionKey eq '%s'", threadID))
+	if err != nil {
+		return nil, err
+	}
+	models.FillReadBy(messages, receipts)
+	return messages, nil
+}
+
+// SendMessage posts to a thread and emails the other members, holding emails back during quiet hours.
+// Attachments must be images the sender has already uploaded, and only staff post to classroom broadcasts
+func (s *MessageService) SendMessage(ctx context.Context, actorID string, message models.Message) (models.Message, error) {
+	actor, thread, err := s.authorizeThread(actorID, message.ThreadID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	if thread.Kind == models.ThreadClassroom {
+		if err := authorizeClassroom(actor, models.PermissionManageChildren, thread.Classroom); err != nil {
+			return models.Message{}, err
+		}
+	}
+	for _, attachment := range message.Attachments {
+		if attachment.ImageOwner != actorID {
+			return models.Message{}, fmt.Errorf("%w: attachments must be your own uploads", ErrForbidden)
+		}
+		if _, err := s.blobRepo.GetImageMetadata(ctx, attachment.ImageOwner, attachment.ImageName); err != nil {
+			return models.Message{}, fmt.Errorf("MessageService.SendMessage: Attachment %s/%s not found: %w", attachment.ImageOwner, attachment.ImageName, err)
+		}
+	}
+
+	message.SenderID = actorID
+	if err := s.repo.AddMessage(MESSAGESTABLE, message); err != nil {
+		return models.Message{}, err
+	}
+	thread.LastMessageAt = message.SentAt
+	if err := s.repo.UpsertThread(MESSAGETHREADSTABLE, thread); err != nil {
+		return models.Message{}, err
+	}
+	// The sender has read everything up to their own message
+	if err := s.repo.UpsertReceipt(READRECEIPTSTABLE, models.ReadReceipt{ThreadID: thread.ID, UserID: actorID, LastReadAt: message.SentAt}); err != nil {
+		log.Printf("MessageService.SendMessage: Failed to update sender read receipt: %v", err)
+	}
+	message.ReadBy = []string{}
+
+	members, err := s.threadMembers(thread)
+	if err != nil {
+		log.Printf("MessageService: Failed to resolve members of thread %s: %v", thread.ID, err)
+		return message, nil
+	}
+	for _, memberID := range members {
+		if memberID == actorID {
+			continue
+		}
+		if err := s.repo.IncrementUnread(READRECEIPTSTABLE, thread.ID, memberID); err != nil {
+			log.Printf("MessageService.SendMessage: Failed to update unread count for %s: %v", memberID, err)
+		}
+	}
+	if !s.quietHours.Contains(message.SentAt.In(models.CenterLocation())) {
+		s.notifyMembers(actor, thread, message, members)
+	}
+	return message, nil
+}
+
+// MarkRead records that a user has read everything in a thread up to now
+func (s *MessageService) MarkRead(actorID string, threadID string) (models.ReadReceipt, error) {
+	if _, _, err := s.authorizeThread(actorID, threadID); err != nil {
+		return models.ReadReceipt{}, err
+	}
+	receipt := models.ReadReceipt{ThreadID: threadID, UserID: actorID, LastReadAt: time.Now(), Unread: 0}
+	if err := s.repo.UpsertReceipt(READRECEIPTSTABLE, receipt); err != nil {
+		return models.ReadReceipt{}, err
+	}
+	return receipt, nil
+}
+
+// GetAttachment opens an image attached to a message to anyone who can read the thread
+func (s *MessageService) GetAttachment(ctx context.Context, actorID string, threadID string, messageID string, index int) (*models.ImageStream, error) {
+	messages, err := s.GetMessages(actorID, threadID)
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		if message.ID != messageID {
+			continue
+		}
+		if index < 0 || index >= len(message.Attachments) {
+			return nil, fmt.Errorf("MessageService.GetAttachment: Message %s has no attachment %d", messageID, index)
+		}
+		attachment := message.Attachments[index]
+		return s.blobRepo.OpenBlob(ctx, fmt.Sprintf("%s/%s", attachment.ImageOwner, attachment.ImageName))
+	}
+	return nil, fmt.Errorf("MessageService.GetAttachment: Message %s not found in thread %s", messageID, threadID)
+}
+
+// SendQuietHoursSummaries emails each member who received unread messages during the quiet window ending at end,
+// returning how many emails were sent
+func (s *MessageService) SendQuietHoursSummaries(end time.Time) (int, error) {
+	start := s.quietHours.StartBefore(end.In(models.CenterLocation()))
+	threads, err := s.repo.GetThreads(MESSAGETHREADSTABLE, "")
+	if err != nil {
+		return 0, err
+	}
+
+	unreadByUser := make(map[string]int)
+	for _, thread := range threads {
+		if thread.LastMessageAt.Before(start) {
+			continue
+		}
+		messages, err := s.repo.GetMessages(MESSAGESTABLE, thread.ID)
+		if err != nil {
+			return 0, err
+		}
+		quietMessages := []models.Message{}
+		for _, message := range messages {
+			if !message.SentAt.Before(start) && message.SentAt.Before(end) {
+				quietMessages = append(quietMessages, message)
+			}
+		}
+		if len(quietMessages) == 0 {
+			continue
+		}
+		receipts, err := s.repo.GetReceipts(READRECEIPTSTABLE, fmt.Sprintf("PartitionKey eq '%s'", thread.ID))
+		if err != nil {
+			return 0, err
+		}
+		lastRead := make(map[string]time.Time)
+		for _, receipt := range receipts {
+			lastRead[receipt.UserID] = receipt.LastReadAt
+		}
+		members, err := s.threadMembers(thread)
+		if err != nil {
+			return 0, err
+		}
+		for _, memberID := range members {
+			unreadByUser[memberID] += models.CountUnread(quietMessages, memberID, lastRead[memberID])
+		}
+	}
+
+	sent := 0
+	for userID, unread := range unreadByUser {
+		if unread == 0 {
+			continue
+		}
+		user, err := s.userRepo.GetUser(USERSTABLE, userID)
+		if err != nil || user.Email == "" {
+			continue
+		}
+		subject := fmt.Sprintf("You have %d new message(s) at Little Einstein", unread)
+		plainTextContent := fmt.Sprintf("%d message(s) arrived overnight. Open the Little Einstein app to read them.", unread)
+		htmlContent := fmt.Sprintf("<p>%d message(s) arrived overnight. Open the Little Einstein app to read them.</p>", unread)
+		if err := s.emailService.SendEmail(user.Email, subject, plainTextContent, htmlContent); err != nil {
+			log.Printf("MessageService.SendQuietHoursSummaries: Failed to email %s: %v", user.Email, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// StartQuietHoursSummaries sends the held back notifications each day when quiet hours end, until ctx is cancelled
+func (s *MessageService) StartQuietHoursSummaries(ctx context.Context) {
+	if s.quietHours.Start == s.quietHours.End {
+		log.Println("Message quiet hours disabled")
+		return
+	}
+	go func() {
+		for {
+			now := models.CenterNow()
+			next := models.ClockTime(now, s.quietHours.End)
+			if !next.After(now) {
+				next = models.ClockTime(now.AddDate(0, 0, 1), s.quietHours.End)
+			}
+
+			select {
+			case <-ctx.Done():
+				log.Println("Message quiet hours summaries stopped")
+				return
+			case <-time.After(time.Until(next)):
+				sent, err := s.SendQuietHoursSummaries(next)
+				if err != nil {
+					log.Printf("Message quiet hours summaries failed after %d emails: %v", sent, err)
+					continue
+				}
+				log.Printf("Message quiet hours summaries sent %d emails", sent)
+			}
+		}
+	}()
+	log.Printf("Message emails held from %v to %v after midnight", s.quietHours.Start, s.quietHours.End)
+}
+
+// notifyMembers emails everyone in the thread except the sender, logging failures
+func (s *MessageService) notifyMembers(sender models.User, thread models.Thread, message models.Message, members []string) {
+	preview := []rune(message.Body)
+	if len(preview) > messagePreviewLength {
+		preview = append(preview[:messagePreviewLength], '…')
+	}
+	subject := fmt.Sprintf("New message from %s", sender.Name)
+	if thread.Subject != "" {
+		subject = fmt.Sprintf("%s: %s", subject, thread.Subject)
+	}
+	plainTextContent := fmt.Sprintf("%s wrote:\n\n%s\n\nReply in the Little Einstein app.", sender.Name, string(preview))
+	htmlContent := fmt.Sprintf("<p>%s wrote:</p><blockquote>%s</blockquote><p>Reply in the Little Einstein app.</p>", html.EscapeString(sender.Name), html.EscapeString(string(preview)))
+
+	for _, memberID := range members {
+		if memberID == sender.ID {
+			continue
+		}
+		member, err := s.userRepo.GetUser(USERSTABLE, memberID)
+		if err != nil || member.Email == "" {
+			continue
+		}
+		if err := s.emailService.SendEmail(member.Email, subject, plainTextContent, htmlContent); err != nil {
+			log.Printf("MessageService: Failed to email %s: %v", member.Email, err)
+		}
+	}
+}
+
+// threadMembers returns who receives a thread's messages: a direct thread's participants, or a classroom's guardians and assigned teachers
+func (s *MessageService) threadMembers(thread models.Thread) ([]string, error) {
+	if thread.Kind == models.ThreadDirect {
+		return thread.ParticipantIDs, nil
+	}
+	seen := make(map[string]bool)
+	members := []string{}
+	children, err := s.childRepo.GetChildrenByClassroom(CHILDRENTABLE, thread.Classroom)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", child.ID))
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			if !seen[link.UserID] {
+				seen[link.UserID] = true
+				members = append(members, link.UserID)
+			}
+		}
+	}
+	users, err := s.userRepo.GetAllUsers(USERSTABLE)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user.Role.IsStaff() && user.HasClassroom(thread.Classroom) && !seen[user.ID] {
+			seen[user.ID] = true
+			members = append(members, user.ID)
+		}
+	}
+	return members, nil
+}
+
+// visibleThreads returns the direct threads a user takes part in and the broadcasts for classrooms they cover or have a child in
+func (s *MessageService) visibleThreads(actor models.User) ([]models.Thread, error) {
+	threads, err := s.repo.GetThreads(MESSAGETHREADSTABLE, "")
+	if err != nil {
+		return nil, err
+	}
+	guardianClassrooms, err := s.guardianClassrooms(actor.ID)
+	if err != nil {
+		return nil, err
+	}
+	visible := []models.Thread{}
+	for _, thread := range threads {
+		if canViewThread(actor, thread, guardianClassrooms) {
+			visible = append(visible, thread)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].LastMessageAt.After(visible[j].LastMessageAt)
+	})
+	return visible, nil
+}
+
+// authorizeThread loads a thread and checks the acting user can read it
+func (s *MessageService) authorizeThread(actorID string, threadID string) (models.User, models.Thread, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.User{}, models.Thread{}, err
+	}
+	thread, err := s.repo.GetThread(MESSAGETHREADSTABLE, threadID)
+	if err != nil {
+		return models.User{}, models.Thread{}, err
+	}
+	guardianClassrooms, err := s.guardianClassrooms(actorID)
+	if err != nil {
+		return models.User{}, models.Thread{}, err
+	}
+	if !canViewThread(actor, thread, guardianClassrooms) {
+		return models.User{}, models.Thread{}, fmt.Errorf("%w: %s is not a member of thread %s", ErrForbidden, actorID, threadID)
+	}
+	return actor, thread, nil
+}
+
+// guardianClassrooms returns the classrooms of the children a user is a guardian of
+func (s *MessageService) guardianClassrooms(userID string) (map[string]bool, error) {
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("RowKey eq '%s'", userID))
+	if err != nil {
+		return nil, err
+	}
+	classrooms := make(map[string]bool)
+	for _, link := range links {
+		child, err := s.childRepo.GetChild(CHILDRENTABLE, link.ChildID)
+		if err != nil {
+			// Link left behind by a deleted child
+			continue
+		}
+		classrooms[child.Classroom] = true
+	}
+	return classrooms, nil
+}
+
+func (s *MessageService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("MessageService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
+
+// Helper - direct threads are open to their participants, classroom threads to guardians with a child in the classroom and staff covering it
+func canViewThread(actor models.User, thread models.Thread, guardianClassrooms map[string]bool) bool {
+	if thread.Kind == models.ThreadDirect {
+		return thread.HasParticipant(actor.ID)
+	}
+	return guardianClassrooms[thread.Classroom] || authorizeClassroom(actor, models.PermissionManageChildren, thread.Classroom) == nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrPaymentDeclined is returned when the payment provider refuses a charge
+var ErrPaymentDeclined = errors.New("payment was declined")
+
+// PaymentProvider charges a family for an invoice and returns the provider's payment reference, and refunds a charge
+// that could not be recorded. Charges sharing an idempotency key are made at most once, a repeat returns the original reference
+type PaymentProvider interface {
+	Charge(ctx context.Context, invoice models.Invoice, paymentToken string, idempotencyKey string) (string, error)
+	Refund(ctx context.Context, reference string) error
+}
+
+// LocalPaymentDeclineToken is the payment token the local provider declines, so failures can be exercised without a real provider
+const LocalPaymentDeclineToken = "decline"
+
+// LocalPaymentProvider approves charges without moving money, for development and the storage emulator
+type LocalPaymentProvider struct {
+	mutex   sync.Mutex
+	charges map[string]string // Idempotency key to reference
+}
+
+// NewLocalPaymentProvider creates a payment provider that never contacts a real payment network
+func NewLocalPaymentProvider() *LocalPaymentProvider {
+	return &LocalPaymentProvider{charges: make(map[string]string)}
+}
+
+// Charge approves any token except LocalPaymentDeclineToken and returns a made up reference, the same one for a repeated key
+func (p *LocalPaymentProvider) Charge(ctx context.Context, invoice models.Invoice, paymentToken string, idempotencyKey string) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if reference, ok := p.charges[idempotencyKey]; ok {
+		log.Printf("LocalPaymentProvider: Repeated charge for invoice %s returned %s", invoice.ID, reference)
+		return reference, nil
+	}
+	if paymentToken == LocalPaymentDeclineToken {
+		return "", fmt.Errorf("LocalPaymentProvider.Charge: Token declined for invoice %s: %w", invoice.ID, ErrPaymentDeclined)
+	}
+	reference := fmt.Sprintf("local_%s_%d", invoice.ID, time.Now().UnixNano())
+	p.charges[idempotencyKey] = reference
+	log.Printf("LocalPaymentProvider: Approved %s for invoice %s as %s", models.FormatCents(invoice.TotalCents), invoice.ID, reference)
+	return reference, nil
+}
+
+// Refund forgets a charge so its idempotency key can be charged again
+func (p *LocalPaymentProvider) Refund(ctx context.Context, reference string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for key, charged := range p.charges {
+		if charged == reference {
+			delete(p.charges, key)
+			log.Printf("LocalPaymentProvider: Refunded %s", reference)
+			return nil
+		}
+	}
+	return fmt.Errorf("LocalPaymentProvider.Refund: No charge with reference %s", reference)
+}
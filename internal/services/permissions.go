@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+)
+
+// ErrForbidden is returned when the acting user's role does not allow an action
+var ErrForbidden = errors.New("forbidden")
+
+// authorizeUserManagement allows users to manage their own account and staff with the manage users permission to
+// manage anyone else, admin accounts excepted, which only admins may change
+func authorizeUserManagement(actor models.User, target models.User) error {
+	if actor.ID == target.ID {
+		return nil
+	}
+	if !actor.Role.Can(models.PermissionManageUsers) {
+		return fmt.Errorf("%w: %s may not manage user accounts", ErrForbidden, actor.Role)
+	}
+	if target.Role == models.RoleAdmin && actor.Role != models.RoleAdmin {
+		return fmt.Errorf("%w: only admins may manage admin account %s", ErrForbidden, target.ID)
+	}
+	return nil
+}
+
+// authorizeClassroom checks a classroom scoped permission, limiting teachers to their assigned classrooms
+func authorizeClassroom(actor models.User, permission models.Permission, classroomID string) error {
+	if !actor.Role.Can(permission) {
+		return fmt.Errorf("%w: %s may not %s", ErrForbidden, actor.Role, permission)
+	}
+	if actor.Role.IsClassroomScoped() && !actor.HasClassroom(classroomID) {
+		return fmt.Errorf("%w: %s is not assigned to classroom %q", ErrForbidden, actor.ID, classroomID)
+	}
+	return nil
+}
+
+// authorizeEventManagement allows creators to manage their own events and staff to manage events for classrooms they cover
+func authorizeEventManagement(actor models.User, event models.Event) error {
+	if event.Classroom == "" {
+		if event.Creator.ID == actor.ID || (actor.Role.Can(models.PermissionManageEvents) && !actor.Role.IsClassroomScoped()) {
+			return nil
+		}
+		return fmt.Errorf("%w: only the creator or a director may manage event %s", ErrForbidden, event.ID)
+	}
+	return authorizeClassroom(actor, models.PermissionManageEvents, event.Classroom)
+}
+
+// authorizeChildAccess allows a child's guardians and staff covering the child's classroom
+func authorizeChildAccess(actor models.User, child models.Child) error {
+	if child.HasGuardian(actor.ID) {
+		return nil
+	}
+	return authorizeClassroom(actor, models.PermissionManageChildren, child.Classroom)
+}
+
+// authorizeMediaConsent allows a child's guardians and admins to choose how widely photos of the child are shared
+func authorizeMediaConsent(actor models.User, child models.Child) error {
+	if child.HasGuardian(actor.ID) || actor.Role == models.RoleAdmin {
+		return nil
+	}
+	return fmt.Errorf("%w: only a guardian or an admin may change media consent for child %s", ErrForbidden, child.ID)
+}
+
+// authorizeClassroomInvites allows staff to invite whole classrooms they cover
+func authorizeClassroomInvites(actor models.User, classroomIDs []string) error {
+	for _, classroomID := range classroomIDs {
+		if err := authorizeClassroom(actor, models.PermissionManageEvents, classroomID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/imaging"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"strings"
+	"time"
+)
+
+const AUTHORIZEDPICKUPSTABLE = "AuthorizedPickupsTable"
+const PICKUPAUDITTABLE = "PickupAuditTable"
+
+// PICKUPPHOTOPREFIX keeps pickup photos apart from user images in the blob container
+const PICKUPPHOTOPREFIX = "pickups/"
+
+// ErrUnauthorizedPickup is returned when the adult collecting a child is not a guardian or on the authorized pickup list
+var ErrUnauthorizedPickup = errors.New("adult is not authorized to pick up this child")
+
+// PickupRepo interface methods implemented in repositories package
+type PickupRepo interface {
+	GetPickups(tableName string, childID string) ([]models.AuthorizedPickup, error)
+	UpsertPickup(tableName string, pickup models.AuthorizedPickup) error
+	DeletePickup(tableName string, childID string, pickupID string) error
+	AddAudit(tableName string, audit models.PickupAudit) error
+	GetAudits(tableName string, childID string) ([]models.PickupAudit, error)
+}
+
+// PickupService manages each child's authorized pickup list and verifies the adult at checkout
+type PickupService struct {
+	repo      PickupRepo
+	blobRepo  BlobRepo
+	childRepo ChildRepo
+	userRepo  UserRepo
+}
+
+// NewPickupService constructs and returns a PickupService object
+func NewPickupService(r PickupRepo, b BlobRepo, c ChildRepo, u UserRepo) *PickupService {
+	return &PickupService{repo: r, blobRepo: b, childRepo: c, userRepo: u}
+}
+
+// GetPickups returns a child's authorized pickup list to its guardians and staff covering its classroom
+func (s *PickupService) GetPickups(actorID string, childID string) ([]models.AuthorizedPickup, error) {
+	if _, err := s.authorizeViewer(actorID, childID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPickups(AUTHORIZEDPICKUPSTABLE, childID)
+}
+
+// AddPickup adds an adult to a child's authorized pickup list with an optional photo, guardians only
+func (s *PickupService) AddPickup(ctx context.Context, actorID string, pickup models.AuthorizedPickup, photo []byte, photoType string) (models.AuthorizedPickup, error) {
+	child, err := s.getChild(pickup.ChildID)
+	if err != nil {
+		return models.AuthorizedPickup{}, err
+	}
+	if !child.HasGuardian(actorID) {
+		return models.AuthorizedPickup{}, fmt.Errorf("%w: only guardians of child %s may change its pickup list", ErrForbidden, child.ID)
+	}
+
+	if len(photo) > 0 {
+		var scrubbed bytes.Buffer
+		if _, err := imaging.Sanitize(&scrubbed, bytes.NewReader(photo), nil); err != nil {
+			return models.AuthorizedPickup{}, fmt.Errorf("PickupService.AddPickup: Failed to strip photo metadata: %w", err)
+		}
+		photo = scrubbed.Bytes()
+		pickup.PhotoBlob = fmt.Sprintf("%s%s/%s", PICKUPPHOTOPREFIX, pickup.ChildID, pickup.ID)
+		metadata := map[string]string{"id": actorID, "child": pickup.ChildID}
+		if _, err := s.blobRepo.UploadBlob(ctx, pickup.PhotoBlob, photoType, photo, metadata); err != nil {
+			return models.AuthorizedPickup{}, err
+		}
+	}
+	if err := s.repo.UpsertPickup(AUTHORIZEDPICKUPSTABLE, pickup); err != nil {
+		return models.AuthorizedPickup{}, err
+	}
+	return pickup, nil
+}
+
+// RemovePickup removes an adult and their photo from a child's authorized pickup list, guardians only
+func (s *PickupService) RemovePickup(ctx context.Context, actorID string, childID string, pickupID string) error {
+	child, err := s.getChild(childID)
+	if err != nil {
+		return err
+	}
+	if !child.HasGuardian(actorID) {
+		return fmt.Errorf("%w: only guardians of child %s may change its pickup list", ErrForbidden, child.ID)
+	}
+	pickup, err := s.findPickup(childID, pickupID)
+	if err != nil {
+		return err
+	}
+	if pickup.PhotoBlob != "" {
+		if err := s.blobRepo.DeleteBlob(ctx, pickup.PhotoBlob); err != nil {
+			log.Printf("PickupService.RemovePickup: Failed to delete photo for pickup %s: %v", pickupID, err)
+		}
+	}
+	return s.repo.DeletePickup(AUTHORIZEDPICKUPSTABLE, childID, pickupID)
+}
+
+// GetPickupPhoto returns the photo of an authorized pickup so staff can confirm who is at the door
+func (s *PickupService) GetPickupPhoto(ctx context.Context, actorID string, childID string, pickupID string) ([]byte, string, error) {
+	if _, err := s.authorizeViewer(actorID, childID); err != nil {
+		return nil, "", err
+	}
+	pickup, err := s.findPickup(childID, pickupID)
+	if err != nil {
+		return nil, "", err
+	}
+	if pickup.PhotoBlob == "" {
+		return nil, "", fmt.Errorf("PickupService.GetPickupPhoto: Pickup %s has no photo", pickupID)
+	}
+	return s.blobRepo.GetBlob(ctx, pickup.PhotoBlob)
+}
+
+// GetAudits returns the checkout audit trail for a child, staff covering its classroom only
+func (s *PickupService) GetAudits(actorID string, childID string) ([]models.PickupAudit, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageAttendance, child.Classroom); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAudits(PICKUPAUDITTABLE, childID)
+}
+
+// AuthorizeCheckout verifies the adult collecting a child against its guardians and authorized pickup list.
+// pickupID may name an authorized pickup or a guardian's user ID, otherwise pickedUpBy is matched by name.
+// Unlisted adults are only allowed when a staff member with the override permission records a reason.
+// Every attempt is written to the audit trail and the verified adult's name is returned
+func (s *PickupService) AuthorizeCheckout(actorID string, attendanceID string, childID string, pickedUpBy string, pickupID string, overrideReason string) (string, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return "", err
+	}
+	child, err := s.getChild(childID)
+	if err != nil {
+		return "", err
+	}
+
+	audit := models.PickupAudit{
+		ChildID:      childID,
+		AttendanceID: attendanceID,
+		PickedUpBy:   pickedUpBy,
+		StaffID:      actorID,
+		At:           time.Now(),
+	}
+	name, matchedID, err := s.matchAuthorizedAdult(child, pickedUpBy, pickupID)
+	if err != nil {
+		return "", err
+	}
+	if matchedID != "" {
+		audit.PickedUpBy = name
+		audit.PickupID = matchedID
+		audit.Authorized = true
+		audit.Allowed = true
+	} else if strings.TrimSpace(overrideReason) != "" && actor.Role.Can(models.PermissionOverridePickup) {
+		audit.OverrideReason = strings.TrimSpace(overrideReason)
+		audit.Allowed = true
+	}
+
+	if err := s.repo.AddAudit(PICKUPAUDITTABLE, audit); err != nil {
+		return "", err
+	}
+	if !audit.Allowed {
+		if overrideReason != "" {
+			return "", fmt.Errorf("PickupService.AuthorizeCheckout: %s may not override the pickup list: %w", actor.Role, ErrUnauthorizedPickup)
+		}
+		return "", fmt.Errorf("PickupService.AuthorizeCheckout: %q for child %s: %w", pickedUpBy, childID, ErrUnauthorizedPickup)
+	}
+	return audit.PickedUpBy, nil
+}
+
+// matchAuthorizedAdult finds the guardian or authorized pickup referred to at checkout, returning an empty ID when unlisted
+func (s *PickupService) matchAuthorizedAdult(child models.Child, pickedUpBy string, pickupID string) (string, string, error) {
+	for _, link := range child.Guardians {
+		guardian, err := s.userRepo.GetUser(USERSTABLE, link.UserID)
+		if err != nil {
+			continue
+		}
+		if (pickupID != "" && pickupID == guardian.ID) || (pickupID == "" && models.NamesMatch(guardian.Name, pickedUpBy)) {
+			return guardian.Name, guardian.ID, nil
+		}
+	}
+
+	pickups, err := s.repo.GetPickups(AUTHORIZEDPICKUPSTABLE, child.ID)
+	if err != nil {
+		return "", "", err
+	}
+	for _, pickup := range pickups {
+		if (pickupID != "" && pickupID == pickup.ID) || (pickupID == "" && pickup.MatchesName(pickedUpBy)) {
+			return pickup.Name, pickup.ID, nil
+		}
+	}
+	return "", "", nil
+}
+
+// authorizeViewer allows a child's guardians and staff covering its classroom
+func (s *PickupService) authorizeViewer(actorID string, childID string) (models.Child, error) {
+	actor, err := s.getActor(actorID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	child, err := s.getChild(childID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	if child.HasGuardian(actor.ID) {
+		return child, nil
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageAttendance, child.Classroom); err != nil {
+		return models.Child{}, err
+	}
+	return child, nil
+}
+
+func (s *PickupService) findPickup(childID string, pickupID string) (models.AuthorizedPickup, error) {
+	pickups, err := s.repo.GetPickups(AUTHORIZEDPICKUPSTABLE, childID)
+	if err != nil {
+		return models.AuthorizedPickup{}, err
+	}
+	for _, pickup := range pickups {
+		if pickup.ID == pickupID {
+			return pickup, nil
+		}
+	}
+	return models.AuthorizedPickup{}, fmt.Errorf("PickupService: Pickup %s not found for child %s", pickupID, childID)
+}
+
+// getChild loads a child with its guardian links
+func (s *PickupService) getChild(childID string) (models.Child, error) {
+	child, err := s.childRepo.GetChild(CHILDRENTABLE, childID)
+	if err != nil {
+		return models.Child{}, err
+	}
+	links, err := s.childRepo.GetGuardianLinks(GUARDIANSTABLE, fmt.Sprintf("PartitionKey eq '%s'", childID))
+	if err != nil {
+		return models.Child{}, err
+	}
+	child.Guardians = links
+	return child, nil
+}
+
+func (s *PickupService) getActor(actorID string) (models.User, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.User{}, fmt.Errorf("PickupService: Failed to load acting user %s: %w", actorID, err)
+	}
+	return actor, nil
+}
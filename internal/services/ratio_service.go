@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long a ratio alert banner stays up if the monitor doesn't clear it first
+const ratioBannerDuration = 8 * time.Hour
+
+// BannerPublisher is the part of the banner service used to post automatic alerts
+type BannerPublisher interface {
+	GetCurrentBanner() (*models.Banner, error)
+	CreateOrUpdateBanner(banner *models.Banner) error
+	DeleteBanner() error
+}
+
+// RatioService compares the children checked in to each classroom with the staff clocked in to it
+type RatioService struct {
+	classroomRepo  ClassroomRepo
+	childRepo      ChildRepo
+	attendanceRepo AttendanceRepo
+	shiftRepo      ShiftRepo
+	userRepo       UserRepo
+}
+
+// NewRatioService constructs and returns a RatioService object
+func NewRatioService(cr ClassroomRepo, c ChildRepo, a AttendanceRepo, sr ShiftRepo, u UserRepo) *RatioService {
+	return &RatioService{classroomRepo: cr, childRepo: c, attendanceRepo: a, shiftRepo: sr, userRepo: u}
+}
+
+// GetRatios returns the live ratio of every classroom the acting staff member covers
+func (s *RatioService) GetRatios(actorID string) ([]models.ClassroomRatio, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("RatioService.GetRatios: Failed to load acting user %s: %w", actorID, err)
+	}
+	if !actor.Role.Can(models.PermissionManageAttendance) {
+		return nil, fmt.Errorf("%w: %s may not view staffing ratios", ErrForbidden, actor.Role)
+	}
+	ratios, err := s.CurrentRatios()
+	if err != nil {
+		return nil, err
+	}
+	visible := []models.ClassroomRatio{}
+	for _, ratio := range ratios {
+		if !actor.Role.IsClassroomScoped() || actor.HasClassroom(ratio.Classroom) {
+			visible = append(visible, ratio)
+		}
+	}
+	return visible, nil
+}
+
+// CurrentRatios computes the ratio of every classroom from today's open visits and open shifts
+func (s *RatioService) CurrentRatios() ([]models.ClassroomRatio, error) {
+	now := models.CenterNow()
+	date := now.Format(models.AttendanceDateFormat)
+
+	classrooms, err := s.classroomRepo.GetAllClassrooms(CLASSROOMSTABLE)
+	if err != nil {
+		return nil, err
+	}
+	children, err := s.childRepo.GetAllChildren(CHILDRENTABLE)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.attendanceRepo.GetRecords(ATTENDANCETABLE, date, "")
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := s.shiftRepo.GetShifts(SHIFTSTABLE, date, "")
+	if err != nil {
+		return nil, err
+	}
+
+	childrenByID := make(map[string]models.Child, len(children))
+	for _, child := range children {
+		childrenByID[child.ID] = child
+	}
+	// Only the latest visit per child matters, records are ordered by check-in time
+	latestVisit := make(map[string]models.AttendanceRecord)
+	for _, record := range records {
+		latestVisit[record.ChildID] = record
+	}
+	agesByClassroom := make(map[string][]int)
+	for childID, record := range latestVisit {
+		if record.IsCheckedOut() {
+			continue
+		}
+		age := -1
+		if child, ok := childrenByID[childID]; ok {
+			age = child.AgeInMonths(now)
+		}
+		agesByClassroom[record.Classroom] = append(agesByClassroom[record.Classroom], age)
+	}
+	staffByClassroom := make(map[string]map[string]bool)
+	for _, shift := range shifts {
+		if !shift.IsOpen() {
+			continue
+		}
+		if staffByClassroom[shift.Classroom] == nil {
+			staffByClassroom[shift.Classroom] = make(map[string]bool)
+		}
+		staffByClassroom[shift.Classroom][shift.StaffID] = true
+	}
+
+	ratios := make([]models.ClassroomRatio, 0, len(classrooms))
+	for _, classroom := range classrooms {
+		ages := agesByClassroom[classroom.ID]
+		ratio := models.ClassroomRatio{
+			Classroom:       classroom.ID,
+			ClassroomName:   classroom.Name,
+			ChildrenPresent: len(ages),
+			StaffPresent:    len(staffByClassroom[classroom.ID]),
+			StaffRequired:   models.RequiredStaff(classroom.RatioRules, ages),
+			CheckedAt:       now,
+		}
+		ratio.InRatio = ratio.StaffPresent >= ratio.StaffRequired
+		ratios = append(ratios, ratio)
+	}
+	sort.Slice(ratios, func(i, j int) bool {
+		return ratios[i].ClassroomName < ratios[j].ClassroomName
+	})
+	return ratios, nil
+}
+
+// RatioMonitor periodically checks classroom ratios and alerts admins by email and banner when a classroom falls out of ratio
+type RatioMonitor struct {
+	ratios       *RatioService
+	emailService EmailService
+	banners      BannerPublisher
+	userRepo     UserRepo
+	interval     time.Duration
+
+	mutex      sync.Mutex
+	outOfRatio map[string]bool
+	banner     *models.Banner
+	bannerKey  string
+}
+
+// NewRatioMonitor creates a monitor that checks every interval once started
+func NewRatioMonitor(r *RatioService, e EmailService, b BannerPublisher, u UserRepo, interval time.Duration) *RatioMonitor {
+	return &RatioMonitor{
+		ratios:       r,
+		emailService: e,
+		banners:      b,
+		userRepo:     u,
+		interval:     interval,
+		outOfRatio:   make(map[string]bool),
+	}
+}
+
+// Start runs a first check in the background and then one check per interval until ctx is cancelled
+func (m *RatioMonitor) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		m.RunOnce()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("Ratio monitor stopped")
+				return
+			case <-ticker.C:
+				m.RunOnce()
+			}
+		}
+	}()
+	log.Printf("Ratio monitor started, interval: %v", m.interval)
+}
+
+// RunOnce checks every classroom, emailing only when a classroom newly falls out of ratio
+// and keeping the banner in step with the classrooms currently out of ratio
+func (m *RatioMonitor) RunOnce() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	ratios, err := m.ratios.CurrentRatios()
+	if err != nil {
+		log.Printf("Ratio monitor: check failed: %v", err)
+		return
+	}
+
+	var breaches, newBreaches []models.ClassroomRatio
+	current := make(map[string]bool)
+	for _, ratio := range ratios {
+		if ratio.InRatio {
+			continue
+		}
+		current[ratio.Classroom] = true
+		breaches = append(breaches, ratio)
+		if !m.outOfRatio[ratio.Classroom] {
+			newBreaches = append(newBreaches, ratio)
+		}
+	}
+	m.outOfRatio = current
+
+	if len(newBreaches) > 0 {
+		m.emailAdmins(newBreaches)
+	}
+	m.updateBanner(breaches)
+}
+
+func (m *RatioMonitor) emailAdmins(breaches []models.ClassroomRatio) {
+	users, err := m.userRepo.GetAllUsers(USERSTABLE)
+	if err != nil {
+		log.Printf("Ratio monitor: Failed to load admins for alert: %v", err)
+		return
+	}
+
+	var lines []string
+	for _, ratio := range breaches {
+		lines = append(lines, describeRatio(ratio))
+	}
+	subject := "Staff ratio alert"
+	plainTextContent := "The following classrooms are out of ratio:\n" + strings.Join(lines, "\n")
+	htmlContent := "<p>The following classrooms are out of ratio:</p><ul><li>" + strings.Join(lines, "</li><li>") + "</li></ul>"
+
+	for _, user := range users {
+		if user.Role != models.RoleAdmin || user.Email == "" {
+			continue
+		}
+		if err := m.emailService.SendEmail(user.Email, subject, plainTextContent, htmlContent); err != nil {
+			log.Printf("Ratio monitor: Failed to email %s: %v", user.Email, err)
+		}
+	}
+}
+
+// updateBanner reposts the monitor's banner whenever the set of classrooms out of ratio changes or the banner has
+// expired, and clears it once every classroom is back in ratio. A banner posted by staff is never replaced or cleared
+func (m *RatioMonitor) updateBanner(breaches []models.ClassroomRatio) {
+	posted, err := m.banners.GetCurrentBanner()
+	if err != nil {
+		// No banner is up, or the last one has expired
+		posted = nil
+	}
+	if posted != nil && posted != m.banner {
+		// Staff have their own banner up, the monitor posts again once it is gone
+		m.banner, m.bannerKey = nil, ""
+		return
+	}
+
+	if len(breaches) == 0 {
+		if posted != nil {
+			m.banners.DeleteBanner()
+		}
+		m.banner, m.bannerKey = nil, ""
+		return
+	}
+
+	var classrooms, lines []string
+	for _, ratio := range breaches {
+		classrooms = append(classrooms, ratio.Classroom)
+		lines = append(lines, describeRatio(ratio))
+	}
+	key := strings.Join(classrooms, ",")
+	if posted != nil && key == m.bannerKey {
+		return
+	}
+
+	banner, err := models.NewBanner(models.BannerTypeCustom, "Out of ratio: "+strings.Join(lines, "; "), time.Now().Add(ratioBannerDuration))
+	if err != nil {
+		log.Printf("Ratio monitor: Failed to build banner: %v", err)
+		return
+	}
+	if err := m.banners.CreateOrUpdateBanner(banner); err != nil {
+		log.Printf("Ratio monitor: Failed to post banner: %v", err)
+		return
+	}
+	m.banner, m.bannerKey = banner, key
+}
+
+// Helper - one line summary of a classroom's staffing
+func describeRatio(ratio models.ClassroomRatio) string {
+	return fmt.Sprintf("%s has %d children with %d staff, %d required", ratio.ClassroomName, ratio.ChildrenPresent, ratio.StaffPresent, ratio.StaffRequired)
+}
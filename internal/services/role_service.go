@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+	"time"
+)
+
+const ROLECHANGESTABLE = "RoleChangesTable"
+
+// ErrSelfDemotion is returned when an admin tries to remove their own admin role
+var ErrSelfDemotion = errors.New("admins cannot remove their own admin role")
+
+// RoleChangeRepo interface methods implemented in repositories package
+type RoleChangeRepo interface {
+	AddRoleChange(tableName string, change models.RoleChange) error
+	GetRoleChanges(tableName string, userID string) ([]models.RoleChange, error)
+}
+
+// ClaimsUpdater pushes a role change to the identity provider, implemented in the firebase package
+type ClaimsUpdater interface {
+	ApplyRole(ctx context.Context, uid string, role models.Role) error
+}
+
+// RoleService changes user roles and keeps the stored role, auth claims and audit trail in step
+type RoleService struct {
+	userRepo   UserRepo
+	changeRepo RoleChangeRepo
+	claims     ClaimsUpdater
+}
+
+// NewRoleService constructs and returns a RoleService object
+func NewRoleService(u UserRepo, c RoleChangeRepo, claims ClaimsUpdater) *RoleService {
+	return &RoleService{userRepo: u, changeRepo: c, claims: claims}
+}
+
+// ChangeRole updates a user's stored role and classroom assignment, applies the matching custom claims and records who made the change
+func (s *RoleService) ChangeRole(ctx context.Context, userID string, role models.Role, classrooms []string, changedBy string) (models.User, error) {
+	if userID == changedBy && role != models.RoleAdmin {
+		return models.User{}, ErrSelfDemotion
+	}
+	// Only teachers are scoped to classrooms
+	if !role.IsClassroomScoped() {
+		classrooms = []string{}
+	}
+
+	user, err := s.userRepo.GetUser(USERSTABLE, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+	if err := s.userRepo.UpdateRole(USERSTABLE, userID, role, classrooms); err != nil {
+		return models.User{}, err
+	}
+
+	// The audit row is only written for a change that was stored, and a change without its record is an error
+	change := models.RoleChange{
+		UserID:    userID,
+		OldRole:   user.Role,
+		NewRole:   role,
+		ChangedBy: changedBy,
+		ChangedAt: time.Now().UTC(),
+	}
+	if err := s.changeRepo.AddRoleChange(ROLECHANGESTABLE, change); err != nil {
+		return models.User{}, fmt.Errorf("RoleService.ChangeRole: role stored but change not recorded for %s: %w", userID, err)
+	}
+	user.Role = role
+	user.Classrooms = classrooms
+
+	if err := s.claims.ApplyRole(ctx, userID, role); err != nil {
+		return user, fmt.Errorf("RoleService.ChangeRole: role stored but claims not applied: %w", err)
+	}
+
+	return user, nil
+}
+
+// ApplyClaims pushes a newly created user's stored role to their auth claims, the role was granted by an invite so there is no change to record
+func (s *RoleService) ApplyClaims(ctx context.Context, userID string, role models.Role) error {
+	if err := s.claims.ApplyRole(ctx, userID, role); err != nil {
+		return fmt.Errorf("RoleService.ApplyClaims: %w", err)
+	}
+	return nil
+}
+
+// GetRoleHistory returns the audit trail of role changes for a user
+func (s *RoleService) GetRoleHistory(userID string) ([]models.RoleChange, error) {
+	return s.changeRepo.GetRoleChanges(ROLECHANGESTABLE, userID)
+}
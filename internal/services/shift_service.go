@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"littleeinsteinchildcare/backend/internal/models"
+)
+
+const SHIFTSTABLE = "ShiftsTable"
+
+// ErrAlreadyClockedIn is returned when a staff member with an open shift clocks in again
+var ErrAlreadyClockedIn = errors.New("staff member is already clocked in")
+
+// ErrNotClockedIn is returned when a staff member without an open shift clocks out
+var ErrNotClockedIn = errors.New("staff member is not clocked in")
+
+// ShiftRepo interface methods implemented in repositories package
+type ShiftRepo interface {
+	CreateShift(tableName string, shift models.Shift) error
+	UpdateShift(tableName string, shift models.Shift) error
+	GetShifts(tableName string, date string, filter string) ([]models.Shift, error)
+}
+
+// ShiftService records staff clocking in and out of classrooms
+type ShiftService struct {
+	repo     ShiftRepo
+	userRepo UserRepo
+}
+
+// NewShiftService constructs and returns a ShiftService object
+func NewShiftService(r ShiftRepo, u UserRepo) *ShiftService {
+	return &ShiftService{repo: r, userRepo: u}
+}
+
+// ClockIn starts a shift in a classroom the acting staff member covers
+func (s *ShiftService) ClockIn(actorID string, classroomID string) (models.Shift, error) {
+	actor, err := s.userRepo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return models.Shift{}, fmt.Errorf("ShiftService.ClockIn: Failed to load acting user %s: %w", actorID, err)
+	}
+	if classroomID == "" {
+		return models.Shift{}, errors.New("classroom is required to clock in")
+	}
+	if err := authorizeClassroom(actor, models.PermissionManageAttendance, classroomID); err != nil {
+		return models.Shift{}, err
+	}
+
+	now := models.CenterNow()
+	date := now.Format(models.AttendanceDateFormat)
+	shifts, err := s.getShifts(date, actorID)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	if len(shifts) > 0 && shifts[len(shifts)-1].IsOpen() {
+		return models.Shift{}, fmt.Errorf("ShiftService.ClockIn: %s in classroom %s: %w", actorID, shifts[len(shifts)-1].Classroom, ErrAlreadyClockedIn)
+	}
+
+	// Shift numbers make the row key unique per staff member and day, so a concurrent duplicate clock-in conflicts in storage
+	shift := models.Shift{
+		ID:        fmt.Sprintf("%s-%d", actorID, len(shifts)+1),
+		StaffID:   actorID,
+		Classroom: classroomID,
+		Date:      date,
+		ClockInAt: now,
+	}
+	if err := s.repo.CreateShift(SHIFTSTABLE, shift); err != nil {
+		return models.Shift{}, err
+	}
+	return shift, nil
+}
+
+// ClockOut closes the acting staff member's open shift for today
+func (s *ShiftService) ClockOut(actorID string) (models.Shift, error) {
+	now := models.CenterNow()
+	shifts, err := s.getShifts(now.Format(models.AttendanceDateFormat), actorID)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	if len(shifts) == 0 || !shifts[len(shifts)-1].IsOpen() {
+		return models.Shift{}, fmt.Errorf("ShiftService.ClockOut: %s: %w", actorID, ErrNotClockedIn)
+	}
+
+	shift := shifts[len(shifts)-1]
+	shift.ClockOutAt = now
+	if err := s.repo.UpdateShift(SHIFTSTABLE, shift); err != nil {
+		return models.Shift{}, err
+	}
+	return shift, nil
+}
+
+// getShifts returns a staff member's shifts on a date, oldest first
+func (s *ShiftService) getShifts(date string, staffID string) ([]models.Shift, error) {
+	return s.repo.GetShifts(SHIFTSTABLE, date, fmt.Sprintf("StaffID eq '%s'", staffID))
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"fmt"
 	"log"
 	"littleeinsteinchildcare/backend/internal/models"
 )
@@ -15,7 +16,7 @@ type UserRepo interface {
 	DeleteUser(tableName string, id string) error
 	UpdateUser(tableName string, user models.User) (models.User, error)
 	UpsertUser(tableName string, user models.User) error
-
+	UpdateRole(tableName string, id string, role models.Role, classrooms []string) error
 }
 
 // UserService contains and handles a specific UserRepository object
@@ -59,8 +60,14 @@ func (s *UserService) CreateUser(user models.User) error {
 	return nil
 }
 
-// Update User and Process Errors from User Repo
-func (s *UserService) UpdateUser(user models.User) (models.User, error) {
+// Update User and Process Errors from User Repo, roles are only changed through the RoleService
+func (s *UserService) UpdateUser(actorID string, user models.User) (models.User, error) {
+	if err := s.authorize(actorID, user.ID); err != nil {
+		return models.User{}, err
+	}
+	user.Role = ""
+	user.Classrooms = nil
+
 	user, err := s.repo.UpdateUser(USERSTABLE, user)
 	if err != nil {
 		return user, err
@@ -68,8 +75,16 @@ func (s *UserService) UpdateUser(user models.User) (models.User, error) {
 	return user, nil
 }
 
+// SetUserRole changes only the stored role and classroom assignment of a user
+func (s *UserService) SetUserRole(id string, role models.Role, classrooms []string) error {
+	return s.repo.UpdateRole(USERSTABLE, id, role, classrooms)
+}
+
 // Delete User from Users Table and all relevant Events and Invitations
-func (s *UserService) DeleteUserByID(id string) error {
+func (s *UserService) DeleteUserByID(actorID string, id string) error {
+	if err := s.authorize(actorID, id); err != nil {
+		return err
+	}
 	err1 := s.repo.DeleteUser(USERSTABLE, id)
 	if err1 != nil {
 		return err1
@@ -93,4 +108,16 @@ func (s *UserService) DeleteUserByID(id string) error {
 	// Delete the entire blob as a user
 
 	return nil
-}
\ No newline at end of file
+}
+// authorize loads the acting user and checks they may manage the target account
+func (s *UserService) authorize(actorID string, targetID string) error {
+	actor, err := s.repo.GetUser(USERSTABLE, actorID)
+	if err != nil {
+		return fmt.Errorf("UserService: Failed to load acting user %s: %w", actorID, err)
+	}
+	target, err := s.repo.GetUser(USERSTABLE, targetID)
+	if err != nil {
+		return fmt.Errorf("UserService: Failed to load user %s: %w", targetID, err)
+	}
+	return authorizeUserManagement(actor, target)
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"littleeinsteinchildcare/backend/internal/models"
+	"log"
+	"strings"
+	"time"
+)
+
+const APPLICATIONSTABLE = "ApplicationsTable"
+
+// ErrApplicationState is returned when an application is moved out of workflow order
+var ErrApplicationState = errors.New("application is not in the right state")
+
+// ErrRankTaken is returned when another application has already reserved a waitlist rank in the age group
+var ErrRankTaken = errors.New("waitlist rank already taken")
+
+// How many ranks a submission tries before giving up when concurrent submissions keep taking them
+const rankReserveAttempts = 10
+
+// WaitlistRepo interface methods implemented in repositories package
+type WaitlistRepo interface {
+	GetApplication(tableName string, id string) (models.Application, error)
+	GetApplications(tableName string, filter string) ([]models.Application, error)
+	UpsertApplication(tableName string, application models.Application) error
+	ReserveRank(tableName string, ageGroup string, rank int, applicationID string) error
+}
+
+// ChildEnroller creates the child record for an accepted application
+type ChildEnroller interface {
+	CreateChild(actorID string, child models.Child) (models.Child, error)
+}
+
+// GuardianInviter invites a parent who is linked to their child once they sign up
+type GuardianInviter interface {
+	CreateGuardianInvite(email string, childID string) (models.Invite, error)
+}
+
+// WaitlistService takes in enrollment applications from the public form and moves them through intake to enrollment
+type WaitlistService struct {
+	repo         WaitlistRepo
+	children     ChildEnroller
+	invites      GuardianInviter
+	userRepo     UserRepo
+	emailService EmailService
+}
+
+// NewWaitlistService constructs and returns a WaitlistService object
+func NewWaitlistService(r WaitlistRepo, c ChildEnroller, i GuardianInviter, u UserRepo, e EmailService) *WaitlistService {
+	return &WaitlistService{repo: r, children: c, invites: i, userRepo: u, emailService: e}
+}
+
+// SubmitApplication puts a new application at the back of its age group's waitlist and emails the family a confirmation
+func (s *WaitlistService) SubmitApplication(application models.Application) (models.Application, error) {
+	waiting, err := s.repo.GetApplications(APPLICATIONSTABLE, fmt.Sprintf("AgeGroup eq '%s'", application.AgeGroup))
+	if err != nil {
+		return models.Application{}, err
+	}
+	rank := 1
+	for _, other := range waiting {
+		if other.IsWaiting() && other.Rank >= rank {
+			rank = other.Rank + 1
+		}
+	}
+	// Each rank is reserved with a conditional insert, so concurrent submissions in an age group never share one
+	for attempt := 0; ; attempt++ {
+		err := s.repo.ReserveRank(APPLICATIONSTABLE, application.AgeGroup, rank, application.ID)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrRankTaken) || attempt+1 >= rankReserveAttempts {
+			return models.Application{}, fmt.Errorf("WaitlistService.SubmitApplication: %w", err)
+		}
+		rank++
+	}
+	application.Rank = rank
+	if err := s.repo.UpsertApplication(APPLICATIONSTABLE, application); err != nil {
+		return models.Application{}, err
+	}
+
+	subject := "We received your application to Little Einstein"
+	plainTextContent := fmt.Sprintf("Thank you %s, we received your application for %s and will be in touch about a tour.", application.ParentName, application.ChildName)
+	htmlContent := fmt.Sprintf("<p>Thank you %s, we received your application for %s and will be in touch about a tour.</p>", html.EscapeString(application.ParentName), html.EscapeString(application.ChildName))
+	if err := s.emailService.SendEmail(application.Email, subject, plainTextContent, htmlContent); err != nil {
+		log.Printf("WaitlistService.SubmitApplication: Failed to email confirmation to %s: %v", application.Email, err)
+	}
+	return application, nil
+}
+
+// GetWaitlist returns applications in waitlist order, optionally limited to one age group and status.
+// Without a status only applications still waiting are returned
+func (s *WaitlistService) GetWaitlist(ageGroup string, status models.ApplicationStatus) ([]models.Application, error) {
+	filters := []string{}
+	if ageGroup != "" {
+		filters = append(filters, fmt.Sprintf("AgeGroup eq '%s'", ageGroup))
+	}
+	if status != "" {
+		filters = append(filters, fmt.Sprintf("Status eq '%s'", status))
+	}
+	applications, err := s.repo.GetApplications(APPLICATIONSTABLE, strings.Join(filters, " and "))
+	if err != nil {
+		return nil, err
+	}
+	if status != "" {
+		return applications, nil
+	}
+	waiting := []models.Application{}
+	for _, application := range applications {
+		if application.IsWaiting() {
+			waiting = append(waiting, application)
+		}
+	}
+	return waiting, nil
+}
+
+// GetApplication returns a single application
+func (s *WaitlistService) GetApplication(id string) (models.Application, error) {
+	return s.repo.GetApplication(APPLICATIONSTABLE, id)
+}
+
+// UpdateApplication applies an admin's changes to rank, desired start date, phone or notes
+func (s *WaitlistService) UpdateApplication(newData models.Application) (models.Application, error) {
+	application, err := s.repo.GetApplication(APPLICATIONSTABLE, newData.ID)
+	if err != nil {
+		return models.Application{}, err
+	}
+	if err := application.Update(newData); err != nil {
+		return models.Application{}, err
+	}
+	if err := s.repo.UpsertApplication(APPLICATIONSTABLE, application); err != nil {
+		return models.Application{}, err
+	}
+	return application, nil
+}
+
+// TransitionApplication records a tour, offer or decline. Enrollment goes through EnrollApplication
+func (s *WaitlistService) TransitionApplication(id string, status models.ApplicationStatus) (models.Application, error) {
+	if status == models.ApplicationEnrolled {
+		return models.Application{}, fmt.Errorf("WaitlistService.TransitionApplication: use enrollment to enroll application %s: %w", id, ErrApplicationState)
+	}
+	application, err := s.repo.GetApplication(APPLICATIONSTABLE, id)
+	if err != nil {
+		return models.Application{}, err
+	}
+	if err := application.Transition(status, time.Now()); err != nil {
+		return models.Application{}, fmt.Errorf("WaitlistService.TransitionApplication: %v: %w", err, ErrApplicationState)
+	}
+	if err := s.repo.UpsertApplication(APPLICATIONSTABLE, application); err != nil {
+		return models.Application{}, err
+	}
+	return application, nil
+}
+
+// EnrollApplication turns an offered application into a child record in the given classroom.
+// A parent who already has an account is linked straight away, otherwise they are invited and linked when they sign up
+func (s *WaitlistService) EnrollApplication(actorID string, id string, childID string, classroom string) (models.Application, models.Child, error) {
+	application, err := s.repo.GetApplication(APPLICATIONSTABLE, id)
+	if err != nil {
+		return models.Application{}, models.Child{}, err
+	}
+	if err := application.Transition(models.ApplicationEnrolled, time.Now()); err != nil {
+		return models.Application{}, models.Child{}, fmt.Errorf("WaitlistService.EnrollApplication: %v: %w", err, ErrApplicationState)
+	}
+
+	child, err := models.NewChild(childID, application.ChildName, application.ChildBirthdate, classroom, nil, application.Notes)
+	if err != nil {
+		return models.Application{}, models.Child{}, fmt.Errorf("WaitlistService.EnrollApplication: %w", err)
+	}
+	parent, hasAccount := s.findUserByEmail(application.Email)
+	if hasAccount {
+		child.Guardians = []models.GuardianLink{{UserID: parent.ID, Relationship: models.RelationshipParent}}
+	}
+	created, err := s.children.CreateChild(actorID, *child)
+	if err != nil {
+		return models.Application{}, models.Child{}, err
+	}
+
+	application.ChildID = created.ID
+	if err := s.repo.UpsertApplication(APPLICATIONSTABLE, application); err != nil {
+		return models.Application{}, models.Child{}, err
+	}
+
+	if !hasAccount {
+		if _, err := s.invites.CreateGuardianInvite(application.Email, created.ID); err != nil {
+			return application, created, fmt.Errorf("WaitlistService.EnrollApplication: Child %s created but invite failed: %w", created.ID, err)
+		}
+		if err := s.emailService.SendInviteEmail(application.Email); err != nil {
+			log.Printf("WaitlistService.EnrollApplication: Failed to email invite to %s: %v", application.Email, err)
+		}
+	}
+	return application, created, nil
+}
+
+// findUserByEmail looks for an existing account with the applicant's email address
+func (s *WaitlistService) findUserByEmail(email string) (models.User, bool) {
+	users, err := s.userRepo.GetAllUsers(USERSTABLE)
+	if err != nil {
+		return models.User{}, false
+	}
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) {
+			return user, true
+		}
+	}
+	return models.User{}, false
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"log"
@@ -45,6 +47,12 @@ func GetUserIDFromAuth(r *http.Request) (string, error) {
 	return uid, nil
 }
 
+// IsAdmin reports whether the authenticated user's token carries the admin claim
+func IsAdmin(r *http.Request) bool {
+	isAdmin, ok := r.Context().Value(common.ContextAdmin).(bool)
+	return ok && isAdmin
+}
+
 func RespondUnauthorized(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
@@ -57,3 +65,12 @@ func GetContextString(ctx context.Context, key ContextKey) (string, bool) {
 	val, ok := ctx.Value(key).(string)
 	return val, ok
 }
+
+// NewID returns a random 32 character hex identifier for new table rows
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("utils.NewID: Failed to read random bytes: %v", err)
+	}
+	return hex.EncodeToString(b)
+}